@@ -0,0 +1,39 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// ScopedName returns the name of a resource scoped to the Workshop instance, so that several Workshops can run
+// on the same cluster: <workshop>-<hash>-<name>, the hash telling apart the Workshops of the same name in other namespaces
+func (r *Workshop) ScopedName(name string) string {
+	return r.Name + "-" + namespaceHash(r.Namespace) + "-" + name
+}
+
+// ProjectName returns the name of the staging project of the user
+func (r *Workshop) ProjectName(username string) string {
+	return r.ScopedName(username + "-" + r.Spec.Infrastructure.Project.StagingName)
+}
+
+// namespaceHash returns the first 5 hexadecimal digits of the SHA-256 of the namespace
+func namespaceHash(namespace string) string {
+	sum := sha256.Sum256([]byte(namespace))
+	return hex.EncodeToString(sum[:])[:5]
+}
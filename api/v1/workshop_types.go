@@ -17,6 +17,7 @@ limitations under the License.
 package v1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,13 +31,144 @@ type WorkshopSpec struct {
 	Source         SourceSpec         `json:"source"`
 	Infrastructure InfrastructureSpec `json:"infrastructure"`
 	UserDetails    UserDetailsSpec    `json:"userDetails"`
+	// Cluster overrides the URLs read from the cluster configuration
+	// +optional
+	Cluster ClusterSpec `json:"cluster,omitempty"`
+	// Locked prevents removing users while the Workshop is running. Unlocking is an update of its own,
+	// the users are removed by the next one.
+	// +optional
+	Locked bool `json:"locked,omitempty"`
+	// Provisioning tunes the provisioning of the users in the tools of the Workshop
+	// +optional
+	Provisioning ProvisioningSpec `json:"provisioning,omitempty"`
+	// ExtraManifests are applied with the user projects, for each user or once for the Workshop
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ExtraManifests []ExtraManifestSpec `json:"extraManifests,omitempty"`
+	// ServiceAccountName is a Service Account of the namespace of the Workshop, which the operator impersonates
+	// to apply and delete the manifests given by the spec (extraManifests and infrastructure.operators[].manifests):
+	// they can only do what its roles allow. Required with manifests.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+}
+
+// ExtraManifestSpec is a Go template of a YAML stream of resources, given inline or by a ConfigMap.
+// The templates see .Workshop, .Namespace, .Users, .AppsHostnameSuffix and .GiteaURL,
+// plus .Username and .ProjectName in the User scope.
+type ExtraManifestSpec struct {
+	// Name identifies the manifests in the status and labels their resources
+	Name string `json:"name"`
+	// Scope is User to render the template for each user, Workshop to render it once
+	// +optional
+	Scope ManifestScope `json:"scope,omitempty"`
+	// Template is the template of the manifests
+	// +optional
+	Template string `json:"template,omitempty"`
+	// ConfigMapRef gives the templates in a ConfigMap of the namespace of the Workshop
+	// +optional
+	ConfigMapRef *ManifestConfigMapReference `json:"configMapRef,omitempty"`
+}
+
+// ManifestScope ...
+// +kubebuilder:validation:Enum=User;Workshop
+type ManifestScope string
+
+const (
+	// ManifestScopeUser renders the manifests for each user
+	ManifestScopeUser ManifestScope = "User"
+	// ManifestScopeWorkshop renders the manifests once for the Workshop
+	ManifestScopeWorkshop ManifestScope = "Workshop"
+)
+
+// ManifestConfigMapReference ...
+type ManifestConfigMapReference struct {
+	Name string `json:"name"`
+	// Key holds the template, all the keys in their order by default
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// ProvisioningSpec ...
+type ProvisioningSpec struct {
+	// Concurrency is the number of users provisioned at the same time in a tool, 5 by default
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Concurrency int `json:"concurrency,omitempty"`
 }
 
 // UserDetailsSpec ...
 type UserDetailsSpec struct {
-	UserNamePrefix  string `json:"userNamePrefix"`
-	NumberOfUsers   int    `json:"numberOfUsers"`
-	DefaultPassword string `json:"defaultPassword"`
+	// UserNamePrefix and NumberOfUsers generate the users <prefix>1 to <prefix><numberOfUsers>
+	// +optional
+	UserNamePrefix string `json:"userNamePrefix,omitempty"`
+	// +optional
+	NumberOfUsers int `json:"numberOfUsers,omitempty"`
+	// Users lists the attendees explicitly, in addition to the generated users
+	// +optional
+	Users []UserSpec `json:"users,omitempty"`
+	// DefaultPassword is the password of all the users with the Shared password policy.
+	// With the Generated password policy, it is only the access token of the portal.
+	DefaultPassword string `json:"defaultPassword,omitempty"`
+	// PasswordPolicy selects how the passwords of the users are set. With Generated, the passwords are in the
+	// credentials Secrets, and the portal hands out each attendee their user with its password.
+	// +optional
+	PasswordPolicy PasswordPolicy `json:"passwordPolicy,omitempty"`
+	// KubernetesIdentity selects what stands in for the OpenShift users on Kubernetes, ignored on OpenShift
+	// +optional
+	KubernetesIdentity KubernetesIdentity `json:"kubernetesIdentity,omitempty"`
+	// Dex replaces the chart of Dex, installed on Kubernetes for the Dex identity
+	// +optional
+	Dex HelmChartSpec `json:"dex,omitempty"`
+}
+
+// UserSpec ...
+type UserSpec struct {
+	Username string `json:"username"`
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+	// +optional
+	Email string `json:"email,omitempty"`
+	// Group adds the user to the <workshop>-<hash>-<group> OpenShift group
+	// +optional
+	Group string `json:"group,omitempty"`
+	// ProjectSize names the size of the project of the user in infrastructure.project.sizes
+	// +optional
+	ProjectSize string `json:"projectSize,omitempty"`
+}
+
+// KubernetesIdentity ...
+// +kubebuilder:validation:Enum=ServiceAccount;Dex
+type KubernetesIdentity string
+
+const (
+	// KubernetesIdentityServiceAccount gives each user a Service Account and its token
+	KubernetesIdentityServiceAccount KubernetesIdentity = "ServiceAccount"
+	// KubernetesIdentityDex gives each user a password in Dex, the OIDC issuer of the cluster
+	KubernetesIdentityDex KubernetesIdentity = "Dex"
+)
+
+// PasswordPolicy ...
+// +kubebuilder:validation:Enum=Shared;Generated
+type PasswordPolicy string
+
+const (
+	// PasswordPolicyShared gives the DefaultPassword to all the users
+	PasswordPolicyShared PasswordPolicy = "Shared"
+	// PasswordPolicyGenerated generates a random password for each user, stored in the
+	// <workshop>-<hash>-<user>-credentials Secret of the Workshop namespace. The portal serves
+	// each attendee the username and the password of their user, read from the Secret.
+	PasswordPolicyGenerated PasswordPolicy = "Generated"
+)
+
+// ClusterSpec ...
+type ClusterSpec struct {
+	// AppsDomain is the domain of the routes, read from the cluster Ingress config when empty
+	// +optional
+	AppsDomain string `json:"appsDomain,omitempty"`
+	// ConsoleURL is the URL of the OpenShift console, read from the cluster Console config when empty
+	// +optional
+	ConsoleURL string `json:"consoleURL,omitempty"`
 }
 
 // SourceSpec ...
@@ -58,6 +190,12 @@ type InfrastructureSpec struct {
 	ServiceMesh        ServiceMeshSpec        `json:"serviceMesh,omitempty"`
 	Serverless         ServerlessSpec         `json:"serverless,omitempty"`
 	Vault              VaultSpec              `json:"vault,omitempty"`
+
+	// Operators are installed from OperatorHub for the labs, without a component of their own
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Operators []OperatorSpec `json:"operators,omitempty"`
 }
 
 // BookbagSpec ...
@@ -70,18 +208,24 @@ type BookbagSpec struct {
 type CertManagerSpec struct {
 	Enabled     bool            `json:"enabled"`
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // GiteaSpec ...
 type GiteaSpec struct {
 	Enabled bool      `json:"enabled"`
 	Image   ImageSpec `json:"image"`
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // GitOpsSpec ...
 type GitOpsSpec struct {
 	Enabled     bool            `json:"enabled"`
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // GuideSpec ...
@@ -94,18 +238,42 @@ type GuideSpec struct {
 type NexusSpec struct {
 	Enabled bool      `json:"enabled"`
 	Image   ImageSpec `json:"image"`
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // PipelineSpec ...
 type PipelineSpec struct {
 	Enabled     bool            `json:"enabled"`
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // ProjectSpec ...
 type ProjectSpec struct {
 	Enabled     bool   `json:"enabled"`
 	StagingName string `json:"stagingName"`
+	// Quota is the ResourceQuota of the project of each user
+	// +optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+	// LimitRange gives the default and maximum resources of the containers in the project of each user
+	// +optional
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
+	// Sizes are tiers of projects, picked by the projectSize of the users
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Sizes []ProjectSizeSpec `json:"sizes,omitempty"`
+}
+
+// ProjectSizeSpec replaces the quota and the limit range of the projects of the users of this size, when it sets them
+type ProjectSizeSpec struct {
+	Name string `json:"name"`
+	// +optional
+	Quota *corev1.ResourceQuotaSpec `json:"quota,omitempty"`
+	// +optional
+	LimitRange *corev1.LimitRangeSpec `json:"limitRange,omitempty"`
 }
 
 // ScholarsSpec ...
@@ -121,12 +289,22 @@ type ServiceMeshSpec struct {
 	ElasticSearchOperatorHub OperatorHubSpec `json:"elasticSearchOperatorHub"`
 	JaegerOperatorHub        OperatorHubSpec `json:"jaegerOperatorHub"`
 	KialiOperatorHub         OperatorHubSpec `json:"kialiOperatorHub"`
+	// +optional
+	IstioBaseHelm HelmChartSpec `json:"istioBaseHelm,omitempty"`
+	// +optional
+	IstiodHelm HelmChartSpec `json:"istiodHelm,omitempty"`
+	// +optional
+	JaegerHelm HelmChartSpec `json:"jaegerHelm,omitempty"`
+	// +optional
+	KialiHelm HelmChartSpec `json:"kialiHelm,omitempty"`
 }
 
 // ServerlessSpec ...
 type ServerlessSpec struct {
 	Enabled     bool            `json:"enabled"`
 	OperatorHub OperatorHubSpec `json:"operatorHub"`
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // CodeReadyWorkspaceSpec ...
@@ -135,12 +313,102 @@ type CodeReadyWorkspaceSpec struct {
 	OperatorHub         OperatorHubSpec `json:"operatorHub"`
 	OpenshiftOAuth      bool            `json:"openshiftOAuth"`
 	PluginRegistryImage ImageSpec       `json:"pluginRegistryImage,omitempty"`
+	// Helm is the chart of the Eclipse Che operator on Kubernetes, which has no default
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
 }
 
 // OperatorHubSpec ...
 type OperatorHubSpec struct {
 	Channel               string `json:"channel"`
 	ClusterServiceVersion string `json:"clusterServiceVersion,omitempty"`
+	// CatalogSource replaces the catalog of the operator, for instance with a mirror on a disconnected cluster
+	// +optional
+	CatalogSource string `json:"catalogSource,omitempty"`
+	// CatalogSourceNamespace is the namespace of the CatalogSource, openshift-marketplace by default
+	// +optional
+	CatalogSourceNamespace string `json:"catalogSourceNamespace,omitempty"`
+	// Package replaces the name of the package of the operator in the catalog
+	// +optional
+	Package string `json:"package,omitempty"`
+	// TargetNamespace replaces the namespace of the Subscription, which needs an OperatorGroup
+	// +optional
+	TargetNamespace string `json:"targetNamespace,omitempty"`
+	// InstallPlanApproval is Manual by default, the operator approves the InstallPlan of the ClusterServiceVersion
+	// +optional
+	InstallPlanApproval InstallPlanApproval `json:"installPlanApproval,omitempty"`
+	// Config customizes the deployment of the operator
+	// +optional
+	Config *SubscriptionConfigSpec `json:"config,omitempty"`
+}
+
+// OperatorSpec describes an operator installed from OperatorHub, for instance AMQ Streams or Camel K
+type OperatorSpec struct {
+	// Name names the Subscription and the OperatorGroup, and the <name>Operator condition reports the installation
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+	// The package defaults to the name and the catalog to redhat-operators.
+	// The targetNamespace is the namespace of the operator, openshift-operators by default, created if missing.
+	OperatorHubSpec `json:",inline"`
+	// OperatorGroupMode gives the namespaces watched by the operator when it is not installed in openshift-operators
+	// +optional
+	OperatorGroupMode OperatorGroupMode `json:"operatorGroupMode,omitempty"`
+	// Helm is the chart of the operator on Kubernetes, installed in the targetNamespace, the namespace of the name by default
+	// +optional
+	Helm HelmChartSpec `json:"helm,omitempty"`
+	// Manifests are applied once the operator is installed, usually the custom resources of the operator.
+	// It is a YAML stream, the namespaced resources must set their namespace. They are applied as spec.serviceAccountName.
+	// +optional
+	Manifests string `json:"manifests,omitempty"`
+}
+
+// HelmChartSpec describes the Helm chart standing in for an operator of OperatorHub on Kubernetes, which has no OLM.
+// The repository and the chart default to the ones of the component, the version to the one of the catalogue.
+type HelmChartSpec struct {
+	// Repository replaces the repository of the chart, for instance with a mirror. It is empty for an OCI chart.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+	// Chart replaces the name of the chart in the repository, or gives the oci:// reference of the chart
+	// +optional
+	Chart string `json:"chart,omitempty"`
+	// Version of the chart, the latest when empty
+	// +optional
+	Version string `json:"version,omitempty"`
+	// Values are the YAML values of the chart, applied on top of the ones set by the operator
+	// +optional
+	Values string `json:"values,omitempty"`
+}
+
+// OperatorGroupMode ...
+// +kubebuilder:validation:Enum=AllNamespaces;OwnNamespace
+type OperatorGroupMode string
+
+const (
+	// OperatorGroupAllNamespaces lets the operator watch every namespace
+	OperatorGroupAllNamespaces OperatorGroupMode = "AllNamespaces"
+	// OperatorGroupOwnNamespace restricts the operator to its namespace
+	OperatorGroupOwnNamespace OperatorGroupMode = "OwnNamespace"
+)
+
+// InstallPlanApproval ...
+// +kubebuilder:validation:Enum=Manual;Automatic
+type InstallPlanApproval string
+
+const (
+	// InstallPlanApprovalManual waits for the operator to approve the InstallPlans
+	InstallPlanApprovalManual InstallPlanApproval = "Manual"
+	// InstallPlanApprovalAutomatic lets OLM install the InstallPlans, including the upgrades
+	InstallPlanApprovalAutomatic InstallPlanApproval = "Automatic"
+)
+
+// SubscriptionConfigSpec ...
+type SubscriptionConfigSpec struct {
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
 }
 
 // ImageSpec ...
@@ -167,16 +435,166 @@ type WorkshopStatus struct {
 	Gitea                string `json:"gitea"`
 	GitOps               string `json:"gitops"`
 	Nexus                string `json:"nexus"`
+	Operators            string `json:"operators,omitempty"`
 	Pipeline             string `json:"pipeline"`
 	Project              string `json:"project"`
 	ServiceMesh          string `json:"serviceMesh"`
 	Serverless           string `json:"serverless"`
 	UsernameDistribution string `json:"usernameDistribution"`
+	Users                string `json:"users,omitempty"`
 	Vault                string `json:"vault"`
+	// AddOns holds the phase of the components without a field of their own, by name
+	// +optional
+	AddOns map[string]string `json:"addOns,omitempty"`
+
+	// InstalledOperators reports the operators of infrastructure.operators installed by the Workshop,
+	// and the resources to remove once they leave the spec
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	InstalledOperators []InstalledOperatorStatus `json:"installedOperators,omitempty"`
+
+	// ExtraManifests reports the resources applied from the extraManifests of the spec
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	ExtraManifests []ExtraManifestStatus `json:"extraManifests,omitempty"`
+
+	// Attendees reports the provisioning of each user by the components
+	// +optional
+	// +listType=map
+	// +listMapKey=username
+	Attendees []AttendeeStatus `json:"attendees,omitempty"`
+
+	// ObservedGeneration is the most recent generation observed by the controller
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions represent the latest available observations of the Workshop state
+	// +optional
+	// +patchMergeKey=type
+	// +patchStrategy=merge
+	// +listType=map
+	// +listMapKey=type
+	Conditions []Condition `json:"conditions,omitempty" patchStrategy:"merge" patchMergeKey:"type"`
+}
+
+// AttendeeStatus reports the provisioning of a user
+type AttendeeStatus struct {
+	Username string `json:"username"`
+	// UserReady is true once the OpenShift user, or the Service Account standing in for it on Kubernetes, exists
+	// +optional
+	UserReady bool `json:"userReady,omitempty"`
+	// Project is the namespace of the project of the user
+	// +optional
+	Project string `json:"project,omitempty"`
+	// ProjectSize is the size of the project of the user, empty for the default one
+	// +optional
+	ProjectSize string `json:"projectSize,omitempty"`
+	// QuotaHard is the quota of the project of the user
+	// +optional
+	QuotaHard corev1.ResourceList `json:"quotaHard,omitempty"`
+	// QuotaUsed is the consumption of the project of the user counted by its quota, as of the last reconciliation
+	// +optional
+	QuotaUsed corev1.ResourceList `json:"quotaUsed,omitempty"`
+	// GiteaAccount is true once the user has signed up in Gitea
+	// +optional
+	GiteaAccount bool `json:"giteaAccount,omitempty"`
+	// ArgoCDAccount is true once the user has an Argo CD account and AppProject
+	// +optional
+	ArgoCDAccount bool `json:"argoCDAccount,omitempty"`
+	// WorkspaceID is the ID of the CodeReady workspace of the user
+	// +optional
+	WorkspaceID string `json:"workspaceID,omitempty"`
+	// WorkspaceState is the state of the CodeReady workspace when it was created
+	// +optional
+	WorkspaceState string `json:"workspaceState,omitempty"`
+	// BookbagURL is the URL of the guide of the user
+	// +optional
+	BookbagURL string `json:"bookbagURL,omitempty"`
+	// LastError is the last error provisioning the user, prefixed with the component, while it is not fixed
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+	// Provisioned gives, by component, the generation of the Workshop the user was provisioned for.
+	// The user is provisioned again by the component once the generation changes.
+	// +optional
+	Provisioned map[string]int64 `json:"provisioned,omitempty"`
+	// Errors gives, by component, the last error provisioning the user
+	// +optional
+	Errors map[string]string `json:"errors,omitempty"`
+}
+
+// InstalledOperatorStatus reports an operator of infrastructure.operators
+type InstalledOperatorStatus struct {
+	Name string `json:"name"`
+	// Namespace is the namespace of the Subscription
+	Namespace string `json:"namespace"`
+	// ClusterServiceVersion is the installed ClusterServiceVersion
+	// +optional
+	ClusterServiceVersion string `json:"clusterServiceVersion,omitempty"`
+	// Manifests are the resources applied from the manifests of the operator
+	// +optional
+	Manifests []ManifestReference `json:"manifests,omitempty"`
+}
+
+// ExtraManifestStatus reports the resources applied from extra manifests, to delete them once they leave the spec
+type ExtraManifestStatus struct {
+	Name string `json:"name"`
+	// +optional
+	Resources []ManifestReference `json:"resources,omitempty"`
+}
+
+// ManifestReference identifies a resource applied from a manifest
+type ManifestReference struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// Condition types reported in the Workshop status
+const (
+	// ConditionReady is True when every enabled component is installed
+	ConditionReady = "Ready"
+	// ConditionProgressing is True while a component is being installed
+	ConditionProgressing = "Progressing"
+	// ConditionDegraded is True when the last reconciliation failed
+	ConditionDegraded = "Degraded"
+	// ConditionOperatorSuffix ends the type of the conditions reporting the installation of the operator
+	// of a component through OLM, for instance GitOpsOperator. They are True once the ClusterServiceVersion succeeded.
+	ConditionOperatorSuffix = "Operator"
+)
+
+// Condition contains details for one aspect of the current state of the Workshop.
+// It follows the shape of the upstream metav1.Condition.
+type Condition struct {
+	// Type of condition in CamelCase
+	// +kubebuilder:validation:Required
+	Type string `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Enum=True;False;Unknown
+	Status metav1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation the condition was set upon
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is the last time the condition transitioned from one status to another
+	// +kubebuilder:validation:Required
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Reason contains a programmatic identifier indicating the reason for the last transition
+	// +kubebuilder:validation:Required
+	Reason string `json:"reason"`
+	// Message is a human readable message indicating details about the transition
+	// +optional
+	Message string `json:"message,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].reason"
+// +kubebuilder:printcolumn:name="Message",type="string",priority=1,JSONPath=".status.conditions[?(@.type==\"Ready\")].message"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 
 // Workshop is the Schema for the workshops API
 type Workshop struct {
@@ -0,0 +1,397 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"text/template"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// workshoplog is for logging in this package.
+var workshoplog = logf.Log.WithName("workshop-resource")
+
+// DefaultStagingName is the staging name of the user projects when none is set
+const DefaultStagingName = "project"
+
+// MaxUsers is the largest number of users of a Workshop, listed and generated
+const MaxUsers = 1000
+
+// longestNamespaceName is the longest of the names of the namespaces of the components, scoped to the Workshop
+const longestNamespaceName = "workshop-guides"
+
+// DefaultProvisioningConcurrency is the number of users provisioned at the same time when none is set
+const DefaultProvisioningConcurrency = 5
+
+// defaultImages is the catalogue of the images deployed by the operator
+var defaultImages = map[string]ImageSpec{
+	"gitea":              {Name: "quay.io/gpte-devops-automation/gitea-operator", Tag: "v0.17"},
+	"nexus":              {Name: "quay.io/mcouliba/nexus-operator", Tag: "v0.10"},
+	"vault":              {Name: "hashicorp/vault", Tag: "1.8.2"},
+	"vaultAgentInjector": {Name: "hashicorp/vault-k8s", Tag: "0.13.0"},
+}
+
+// defaultChannels is the catalogue of the channels of the operators installed from OperatorHub
+var defaultChannels = map[string]string{
+	"codeReadyWorkspace": "latest",
+	"gitops":             "stable",
+	"pipeline":           "stable",
+	"serviceMesh":        "stable",
+	"elasticSearch":      "stable",
+	"jaeger":             "stable",
+	"kiali":              "stable",
+}
+
+// defaultChartVersions is the catalogue of the versions of the charts standing in for the operators on Kubernetes,
+// the latest version is installed for the charts missing from it
+var defaultChartVersions = map[string]string{
+	"certManager": "v1.14.4",
+	"gitops":      "6.7.3",
+	"gitea":       "10.1.4",
+	"nexus":       "64.2.0",
+	"istioBase":   "1.21.0",
+	"istiod":      "1.21.0",
+	"kiali":       "1.82.0",
+	"dex":         "0.17.0",
+}
+
+// ComponentPlanner tells whether a component is installed for a spec, enabled by the spec
+// or as the dependency of another component
+// +kubebuilder:object:generate=false
+type ComponentPlanner interface {
+	IsEnabled(spec *WorkshopSpec, name string) bool
+}
+
+var (
+	// webhookClient reads the other Workshops of the cluster when validating a Workshop
+	webhookClient client.Reader
+	// webhookComponents plans the components of the Workshops, the ones the controller installs
+	webhookComponents ComponentPlanner
+)
+
+// SetupWebhookWithManager registers the defaulting and validating webhooks of the Workshop.
+// The spec is defaulted and validated for the components the planner installs, their dependencies included.
+func (r *Workshop) SetupWebhookWithManager(mgr ctrl.Manager, components ComponentPlanner) error {
+	webhookClient = mgr.GetClient()
+	webhookComponents = components
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-workshop-stakater-com-v1-workshop,mutating=true,failurePolicy=fail,groups=workshop.stakater.com,resources=workshops,verbs=create;update,versions=v1,name=mworkshop.kb.io,sideEffects=None,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Defaulter = &Workshop{}
+
+// Default fills the image tags, the operator channels, the staging name and the provisioning concurrency
+func (r *Workshop) Default() {
+	workshoplog.Info("default", "name", r.Name)
+
+	infrastructure := &r.Spec.Infrastructure
+
+	defaultImageTag(&infrastructure.Gitea.Image, defaultImages["gitea"])
+	defaultImageTag(&infrastructure.Nexus.Image, defaultImages["nexus"])
+	defaultImageTag(&infrastructure.Vault.Image, defaultImages["vault"])
+	defaultImageTag(&infrastructure.Vault.AgentInjectorImage, defaultImages["vaultAgentInjector"])
+
+	defaultChannel(&infrastructure.CodeReadyWorkspace.OperatorHub, defaultChannels["codeReadyWorkspace"])
+	defaultChannel(&infrastructure.GitOps.OperatorHub, defaultChannels["gitops"])
+	defaultChannel(&infrastructure.Pipeline.OperatorHub, defaultChannels["pipeline"])
+	defaultChannel(&infrastructure.ServiceMesh.ServiceMeshOperatorHub, defaultChannels["serviceMesh"])
+	defaultChannel(&infrastructure.ServiceMesh.ElasticSearchOperatorHub, defaultChannels["elasticSearch"])
+	defaultChannel(&infrastructure.ServiceMesh.JaegerOperatorHub, defaultChannels["jaeger"])
+	defaultChannel(&infrastructure.ServiceMesh.KialiOperatorHub, defaultChannels["kiali"])
+
+	defaultChartVersion(&infrastructure.CertManager.Helm, defaultChartVersions["certManager"])
+	defaultChartVersion(&infrastructure.GitOps.Helm, defaultChartVersions["gitops"])
+	defaultChartVersion(&infrastructure.Gitea.Helm, defaultChartVersions["gitea"])
+	defaultChartVersion(&infrastructure.Nexus.Helm, defaultChartVersions["nexus"])
+	defaultChartVersion(&infrastructure.ServiceMesh.IstioBaseHelm, defaultChartVersions["istioBase"])
+	defaultChartVersion(&infrastructure.ServiceMesh.IstiodHelm, defaultChartVersions["istiod"])
+	defaultChartVersion(&infrastructure.ServiceMesh.KialiHelm, defaultChartVersions["kiali"])
+	defaultChartVersion(&r.Spec.UserDetails.Dex, defaultChartVersions["dex"])
+
+	// The components depending on the user projects install them even when the spec doesn't enable them
+	if r.isPlanned("Project", infrastructure.Project.Enabled) && infrastructure.Project.StagingName == "" {
+		infrastructure.Project.StagingName = DefaultStagingName
+	}
+
+	if r.Spec.Provisioning.Concurrency == 0 {
+		r.Spec.Provisioning.Concurrency = DefaultProvisioningConcurrency
+	}
+}
+
+// defaultImageTag set the tag of the catalogue when the image is the one of the catalogue
+func defaultImageTag(image *ImageSpec, defaultImage ImageSpec) {
+	if image.Tag == "" && image.Name == defaultImage.Name {
+		image.Tag = defaultImage.Tag
+	}
+}
+
+// defaultChartVersion set the version of the catalogue when the chart is the one of the catalogue
+func defaultChartVersion(helm *HelmChartSpec, version string) {
+	if helm.Repository == "" && helm.Chart == "" && helm.Version == "" {
+		helm.Version = version
+	}
+}
+
+// defaultChannel set the channel of the catalogue when none is set
+func defaultChannel(operatorHub *OperatorHubSpec, channel string) {
+	if operatorHub.Channel == "" {
+		operatorHub.Channel = channel
+	}
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-workshop-stakater-com-v1-workshop,mutating=false,failurePolicy=fail,groups=workshop.stakater.com,resources=workshops,versions=v1,name=vworkshop.kb.io,sideEffects=None,admissionReviewVersions={v1,v1beta1}
+
+var _ webhook.Validator = &Workshop{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *Workshop) ValidateCreate() error {
+	workshoplog.Info("validate create", "name", r.Name)
+
+	allErrs := r.validateSpec()
+	allErrs = append(allErrs, r.validateUsernamesUnique()...)
+	return r.toInvalid(allErrs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (r *Workshop) ValidateUpdate(old runtime.Object) error {
+	workshoplog.Info("validate update", "name", r.Name)
+
+	allErrs := r.validateSpec()
+	allErrs = append(allErrs, r.validateUsernamesUnique()...)
+
+	// The lock holds for the update unlocking the Workshop: the users are removed by a later update
+	if oldWorkshop, ok := old.(*Workshop); ok && oldWorkshop.Spec.Locked {
+		usernames := r.Spec.UserDetails.usernames()
+		for username := range oldWorkshop.Spec.UserDetails.usernames() {
+			if !usernames[username] {
+				allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "userDetails"),
+					fmt.Sprintf("user %s cannot be removed while the Workshop is locked, unlock it in a separate update first", username)))
+			}
+		}
+	}
+
+	return r.toInvalid(allErrs)
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *Workshop) ValidateDelete() error {
+	return nil
+}
+
+// validateSpec returns the errors of the spec which would break the installation
+func (r *Workshop) validateSpec() field.ErrorList {
+	var allErrs field.ErrorList
+	specPath := field.NewPath("spec")
+
+	userDetails := r.Spec.UserDetails
+	userDetailsPath := specPath.Child("userDetails")
+	if userDetails.NumberOfUsers < 0 {
+		allErrs = append(allErrs, field.Invalid(userDetailsPath.Child("numberOfUsers"), userDetails.NumberOfUsers,
+			"must be greater than or equal to 0"))
+	}
+	if len(userDetails.usernames()) > 0 && userDetails.DefaultPassword == "" && userDetails.PasswordPolicy != PasswordPolicyGenerated {
+		allErrs = append(allErrs, field.Required(userDetailsPath.Child("defaultPassword"),
+			"the users need a password unless the passwordPolicy is Generated"))
+	}
+	if count := userDetails.NumberOfUsers + len(userDetails.Users); count > MaxUsers {
+		allErrs = append(allErrs, field.TooMany(userDetailsPath, count, MaxUsers))
+	}
+	projectSizes := map[string]bool{}
+	for _, size := range r.Spec.Infrastructure.Project.Sizes {
+		projectSizes[size.Name] = true
+	}
+	for i, user := range userDetails.Users {
+		if user.Username == "" {
+			allErrs = append(allErrs, field.Required(userDetailsPath.Child("users").Index(i).Child("username"), ""))
+		}
+		if user.ProjectSize != "" && !projectSizes[user.ProjectSize] {
+			allErrs = append(allErrs, field.NotFound(userDetailsPath.Child("users").Index(i).Child("projectSize"), user.ProjectSize))
+		}
+	}
+
+	allErrs = append(allErrs, r.validateNames()...)
+
+	gitURLPath := specPath.Child("source", "gitURL")
+	if gitURL, err := url.Parse(r.Spec.Source.GitURL); err != nil {
+		allErrs = append(allErrs, field.Invalid(gitURLPath, r.Spec.Source.GitURL, err.Error()))
+	} else if gitURL.Scheme == "" || gitURL.Host == "" {
+		allErrs = append(allErrs, field.Invalid(gitURLPath, r.Spec.Source.GitURL, "must be an absolute URL"))
+	}
+
+	infrastructure := r.Spec.Infrastructure
+	infrastructurePath := specPath.Child("infrastructure")
+	if infrastructure.Gitea.Enabled && infrastructure.Gitea.Image.Name == "" {
+		allErrs = append(allErrs, field.Required(infrastructurePath.Child("gitea", "image", "name"),
+			"Gitea needs an image"))
+	}
+	projectPlanned := r.isPlanned("Project", infrastructure.Project.Enabled)
+	if projectPlanned && infrastructure.Project.StagingName == "" {
+		allErrs = append(allErrs, field.Required(infrastructurePath.Child("project", "stagingName"),
+			"the user projects are installed, by the spec or for the components depending on them"))
+	}
+	if infrastructure.CodeReadyWorkspace.OperatorHub.TargetNamespace != "" {
+		allErrs = append(allErrs, field.Forbidden(infrastructurePath.Child("codeReadyWorkspace", "operatorHub", "targetNamespace"),
+			"the CodeReady operator is installed in the CodeReady namespace of the Workshop"))
+	}
+	hasManifests := len(r.Spec.ExtraManifests) > 0
+	for i, operator := range infrastructure.Operators {
+		hasManifests = hasManifests || operator.Manifests != ""
+		if operator.OperatorGroupMode == OperatorGroupOwnNamespace &&
+			(operator.TargetNamespace == "" || operator.TargetNamespace == "openshift-operators") {
+			allErrs = append(allErrs, field.Forbidden(infrastructurePath.Child("operators").Index(i).Child("operatorGroupMode"),
+				"openshift-operators has an OperatorGroup for all the namespaces, OwnNamespace needs a targetNamespace"))
+		}
+	}
+
+	if hasManifests && r.Spec.ServiceAccountName == "" {
+		allErrs = append(allErrs, field.Required(specPath.Child("serviceAccountName"),
+			"the manifests are applied as a Service Account of the namespace of the Workshop"))
+	}
+
+	manifestsPath := specPath.Child("extraManifests")
+	if len(r.Spec.ExtraManifests) > 0 && !projectPlanned {
+		allErrs = append(allErrs, field.Forbidden(manifestsPath, "the extra manifests are applied with the user projects, enable the project"))
+	}
+	for i, manifest := range r.Spec.ExtraManifests {
+		manifestPath := manifestsPath.Index(i)
+		if manifest.Scope != ManifestScopeWorkshop && infrastructure.Project.StagingName == "" {
+			allErrs = append(allErrs, field.Required(infrastructurePath.Child("project", "stagingName"),
+				fmt.Sprintf("the %s extra manifests are applied to the project of each user", manifest.Name)))
+		}
+		if (manifest.Template == "") == (manifest.ConfigMapRef == nil) {
+			allErrs = append(allErrs, field.Invalid(manifestPath, manifest.Name, "exactly one of template and configMapRef must be set"))
+		} else if _, err := template.New(manifest.Name).Parse(manifest.Template); err != nil {
+			allErrs = append(allErrs, field.Invalid(manifestPath.Child("template"), manifest.Template, err.Error()))
+		}
+	}
+
+	return allErrs
+}
+
+// validateNames returns the errors of the names of the namespaces the Workshop would create:
+// a namespace name is a DNS-1123 label of at most 63 characters
+func (r *Workshop) validateNames() field.ErrorList {
+	var allErrs field.ErrorList
+	if errs := validation.IsDNS1123Label(r.ScopedName(longestNamespaceName)); len(errs) > 0 {
+		allErrs = append(allErrs, field.Invalid(field.NewPath("metadata", "name"), r.Name,
+			fmt.Sprintf("the namespace %s would not be valid: %s", r.ScopedName(longestNamespaceName), strings.Join(errs, ", "))))
+	}
+
+	if !r.isPlanned("Project", r.Spec.Infrastructure.Project.Enabled) || r.Spec.Infrastructure.Project.StagingName == "" {
+		return allErrs
+	}
+	userDetails := r.Spec.UserDetails
+	userDetailsPath := field.NewPath("spec", "userDetails")
+	validateProject := func(path *field.Path, value interface{}, username string) {
+		projectName := r.ProjectName(username)
+		if errs := validation.IsDNS1123Label(projectName); len(errs) > 0 {
+			allErrs = append(allErrs, field.Invalid(path, value,
+				fmt.Sprintf("the project %s would not be valid: %s", projectName, strings.Join(errs, ", "))))
+		}
+	}
+	for i, user := range userDetails.Users {
+		if user.Username != "" {
+			validateProject(userDetailsPath.Child("users").Index(i).Child("username"), user.Username, user.Username)
+		}
+	}
+	// The last generated user has the longest name
+	if userDetails.NumberOfUsers > 0 {
+		validateProject(userDetailsPath.Child("userNamePrefix"), userDetails.UserNamePrefix,
+			fmt.Sprint(userDetails.UserNamePrefix, userDetails.NumberOfUsers))
+	}
+	return allErrs
+}
+
+// validateUsernamesUnique returns the errors of the users of the Workshop which are users of another Workshop:
+// the OpenShift users are cluster-scoped, so two Workshops with the same user would share its account
+func (r *Workshop) validateUsernamesUnique() field.ErrorList {
+	if webhookClient == nil {
+		return nil
+	}
+	userDetailsPath := field.NewPath("spec", "userDetails")
+	workshops := &WorkshopList{}
+	if err := webhookClient.List(context.Background(), workshops); err != nil {
+		return field.ErrorList{field.InternalError(userDetailsPath, err)}
+	}
+
+	var allErrs field.ErrorList
+	usernames := r.Spec.UserDetails.usernames()
+	for i := range workshops.Items {
+		other := &workshops.Items[i]
+		if other.Namespace == r.Namespace && other.Name == r.Name {
+			continue
+		}
+		var shared []string
+		for username := range other.Spec.UserDetails.usernames() {
+			if usernames[username] {
+				shared = append(shared, username)
+			}
+		}
+		if len(shared) > 0 {
+			sort.Strings(shared)
+			allErrs = append(allErrs, field.Forbidden(userDetailsPath, fmt.Sprintf("the users %v are users of Workshop %s/%s, use another userNamePrefix or username",
+				shared, other.Namespace, other.Name)))
+		}
+	}
+	return allErrs
+}
+
+// isPlanned returns true if the named component is installed for the spec, enabled being the flag of the spec
+// used when the webhooks don't know the components
+func (r *Workshop) isPlanned(name string, enabled bool) bool {
+	if webhookComponents == nil {
+		return enabled
+	}
+	return webhookComponents.IsEnabled(&r.Spec, name)
+}
+
+// toInvalid returns an Invalid error for the errors, nil if there are none
+func (r *Workshop) toInvalid(allErrs field.ErrorList) error {
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("Workshop").GroupKind(), r.Name, allErrs)
+}
+
+// usernames returns the names of all the users, listed and generated, the generated ones up to MaxUsers
+func (u UserDetailsSpec) usernames() map[string]bool {
+	usernames := map[string]bool{}
+	for _, user := range u.Users {
+		if user.Username != "" {
+			usernames[user.Username] = true
+		}
+	}
+	for id := 1; id <= u.NumberOfUsers && id <= MaxUsers; id++ {
+		usernames[fmt.Sprint(u.UserNamePrefix, id)] = true
+	}
+	return usernames
+}
@@ -22,9 +22,53 @@ limitations under the License.
 package v1
 
 import (
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AttendeeStatus) DeepCopyInto(out *AttendeeStatus) {
+	*out = *in
+	if in.QuotaHard != nil {
+		in, out := &in.QuotaHard, &out.QuotaHard
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.QuotaUsed != nil {
+		in, out := &in.QuotaUsed, &out.QuotaUsed
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.Provisioned != nil {
+		in, out := &in.Provisioned, &out.Provisioned
+		*out = make(map[string]int64, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Errors != nil {
+		in, out := &in.Errors, &out.Errors
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AttendeeStatus.
+func (in *AttendeeStatus) DeepCopy() *AttendeeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(AttendeeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BookbagSpec) DeepCopyInto(out *BookbagSpec) {
 	*out = *in
@@ -44,7 +88,8 @@ func (in *BookbagSpec) DeepCopy() *BookbagSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CertManagerSpec) DeepCopyInto(out *CertManagerSpec) {
 	*out = *in
-	out.OperatorHub = in.OperatorHub
+	in.OperatorHub.DeepCopyInto(&out.OperatorHub)
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertManagerSpec.
@@ -57,11 +102,27 @@ func (in *CertManagerSpec) DeepCopy() *CertManagerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
+func (in *ClusterSpec) DeepCopy() *ClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CodeReadyWorkspaceSpec) DeepCopyInto(out *CodeReadyWorkspaceSpec) {
 	*out = *in
-	out.OperatorHub = in.OperatorHub
+	in.OperatorHub.DeepCopyInto(&out.OperatorHub)
 	out.PluginRegistryImage = in.PluginRegistryImage
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CodeReadyWorkspaceSpec.
@@ -74,10 +135,67 @@ func (in *CodeReadyWorkspaceSpec) DeepCopy() *CodeReadyWorkspaceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtraManifestSpec) DeepCopyInto(out *ExtraManifestSpec) {
+	*out = *in
+	if in.ConfigMapRef != nil {
+		in, out := &in.ConfigMapRef, &out.ConfigMapRef
+		*out = new(ManifestConfigMapReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraManifestSpec.
+func (in *ExtraManifestSpec) DeepCopy() *ExtraManifestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraManifestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ExtraManifestStatus) DeepCopyInto(out *ExtraManifestStatus) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ManifestReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ExtraManifestStatus.
+func (in *ExtraManifestStatus) DeepCopy() *ExtraManifestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ExtraManifestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitOpsSpec) DeepCopyInto(out *GitOpsSpec) {
 	*out = *in
-	out.OperatorHub = in.OperatorHub
+	in.OperatorHub.DeepCopyInto(&out.OperatorHub)
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GitOpsSpec.
@@ -94,6 +212,7 @@ func (in *GitOpsSpec) DeepCopy() *GitOpsSpec {
 func (in *GiteaSpec) DeepCopyInto(out *GiteaSpec) {
 	*out = *in
 	out.Image = in.Image
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GiteaSpec.
@@ -123,6 +242,21 @@ func (in *GuideSpec) DeepCopy() *GuideSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmChartSpec) DeepCopyInto(out *HelmChartSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmChartSpec.
+func (in *HelmChartSpec) DeepCopy() *HelmChartSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmChartSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ImageSpec) DeepCopyInto(out *ImageSpec) {
 	*out = *in
@@ -141,17 +275,24 @@ func (in *ImageSpec) DeepCopy() *ImageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InfrastructureSpec) DeepCopyInto(out *InfrastructureSpec) {
 	*out = *in
-	out.CertManager = in.CertManager
-	out.CodeReadyWorkspace = in.CodeReadyWorkspace
+	in.CertManager.DeepCopyInto(&out.CertManager)
+	in.CodeReadyWorkspace.DeepCopyInto(&out.CodeReadyWorkspace)
 	out.Gitea = in.Gitea
-	out.GitOps = in.GitOps
+	in.GitOps.DeepCopyInto(&out.GitOps)
 	in.Guide.DeepCopyInto(&out.Guide)
 	out.Nexus = in.Nexus
-	out.Pipeline = in.Pipeline
-	out.Project = in.Project
-	out.ServiceMesh = in.ServiceMesh
-	out.Serverless = in.Serverless
+	in.Pipeline.DeepCopyInto(&out.Pipeline)
+	in.Project.DeepCopyInto(&out.Project)
+	in.ServiceMesh.DeepCopyInto(&out.ServiceMesh)
+	in.Serverless.DeepCopyInto(&out.Serverless)
 	out.Vault = in.Vault
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]OperatorSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InfrastructureSpec.
@@ -164,10 +305,61 @@ func (in *InfrastructureSpec) DeepCopy() *InfrastructureSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstalledOperatorStatus) DeepCopyInto(out *InstalledOperatorStatus) {
+	*out = *in
+	if in.Manifests != nil {
+		in, out := &in.Manifests, &out.Manifests
+		*out = make([]ManifestReference, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstalledOperatorStatus.
+func (in *InstalledOperatorStatus) DeepCopy() *InstalledOperatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(InstalledOperatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestConfigMapReference) DeepCopyInto(out *ManifestConfigMapReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestConfigMapReference.
+func (in *ManifestConfigMapReference) DeepCopy() *ManifestConfigMapReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestConfigMapReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestReference) DeepCopyInto(out *ManifestReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestReference.
+func (in *ManifestReference) DeepCopy() *ManifestReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NexusSpec) DeepCopyInto(out *NexusSpec) {
 	*out = *in
 	out.Image = in.Image
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NexusSpec.
@@ -183,6 +375,11 @@ func (in *NexusSpec) DeepCopy() *NexusSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OperatorHubSpec) DeepCopyInto(out *OperatorHubSpec) {
 	*out = *in
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = new(SubscriptionConfigSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorHubSpec.
@@ -195,10 +392,28 @@ func (in *OperatorHubSpec) DeepCopy() *OperatorHubSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorSpec) DeepCopyInto(out *OperatorSpec) {
+	*out = *in
+	in.OperatorHubSpec.DeepCopyInto(&out.OperatorHubSpec)
+	out.Helm = in.Helm
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorSpec.
+func (in *OperatorSpec) DeepCopy() *OperatorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PipelineSpec) DeepCopyInto(out *PipelineSpec) {
 	*out = *in
-	out.OperatorHub = in.OperatorHub
+	in.OperatorHub.DeepCopyInto(&out.OperatorHub)
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PipelineSpec.
@@ -211,9 +426,51 @@ func (in *PipelineSpec) DeepCopy() *PipelineSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectSizeSpec) DeepCopyInto(out *ProjectSizeSpec) {
+	*out = *in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(corev1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(corev1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectSizeSpec.
+func (in *ProjectSizeSpec) DeepCopy() *ProjectSizeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectSizeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProjectSpec) DeepCopyInto(out *ProjectSpec) {
 	*out = *in
+	if in.Quota != nil {
+		in, out := &in.Quota, &out.Quota
+		*out = new(corev1.ResourceQuotaSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LimitRange != nil {
+		in, out := &in.LimitRange, &out.LimitRange
+		*out = new(corev1.LimitRangeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sizes != nil {
+		in, out := &in.Sizes, &out.Sizes
+		*out = make([]ProjectSizeSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectSpec.
@@ -226,6 +483,21 @@ func (in *ProjectSpec) DeepCopy() *ProjectSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProvisioningSpec) DeepCopyInto(out *ProvisioningSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProvisioningSpec.
+func (in *ProvisioningSpec) DeepCopy() *ProvisioningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProvisioningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScholarsSpec) DeepCopyInto(out *ScholarsSpec) {
 	*out = *in
@@ -251,7 +523,8 @@ func (in *ScholarsSpec) DeepCopy() *ScholarsSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerlessSpec) DeepCopyInto(out *ServerlessSpec) {
 	*out = *in
-	out.OperatorHub = in.OperatorHub
+	in.OperatorHub.DeepCopyInto(&out.OperatorHub)
+	out.Helm = in.Helm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerlessSpec.
@@ -267,10 +540,14 @@ func (in *ServerlessSpec) DeepCopy() *ServerlessSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceMeshSpec) DeepCopyInto(out *ServiceMeshSpec) {
 	*out = *in
-	out.ServiceMeshOperatorHub = in.ServiceMeshOperatorHub
-	out.ElasticSearchOperatorHub = in.ElasticSearchOperatorHub
-	out.JaegerOperatorHub = in.JaegerOperatorHub
-	out.KialiOperatorHub = in.KialiOperatorHub
+	in.ServiceMeshOperatorHub.DeepCopyInto(&out.ServiceMeshOperatorHub)
+	in.ElasticSearchOperatorHub.DeepCopyInto(&out.ElasticSearchOperatorHub)
+	in.JaegerOperatorHub.DeepCopyInto(&out.JaegerOperatorHub)
+	in.KialiOperatorHub.DeepCopyInto(&out.KialiOperatorHub)
+	out.IstioBaseHelm = in.IstioBaseHelm
+	out.IstiodHelm = in.IstiodHelm
+	out.JaegerHelm = in.JaegerHelm
+	out.KialiHelm = in.KialiHelm
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMeshSpec.
@@ -298,9 +575,49 @@ func (in *SourceSpec) DeepCopy() *SourceSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionConfigSpec) DeepCopyInto(out *SubscriptionConfigSpec) {
+	*out = *in
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionConfigSpec.
+func (in *SubscriptionConfigSpec) DeepCopy() *SubscriptionConfigSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionConfigSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UserDetailsSpec) DeepCopyInto(out *UserDetailsSpec) {
 	*out = *in
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]UserSpec, len(*in))
+		copy(*out, *in)
+	}
+	out.Dex = in.Dex
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserDetailsSpec.
@@ -313,6 +630,21 @@ func (in *UserDetailsSpec) DeepCopy() *UserDetailsSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserSpec) DeepCopyInto(out *UserSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSpec.
+func (in *UserSpec) DeepCopy() *UserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultSpec) DeepCopyInto(out *VaultSpec) {
 	*out = *in
@@ -336,7 +668,7 @@ func (in *Workshop) DeepCopyInto(out *Workshop) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Workshop.
@@ -394,7 +726,16 @@ func (in *WorkshopSpec) DeepCopyInto(out *WorkshopSpec) {
 	*out = *in
 	out.Source = in.Source
 	in.Infrastructure.DeepCopyInto(&out.Infrastructure)
-	out.UserDetails = in.UserDetails
+	in.UserDetails.DeepCopyInto(&out.UserDetails)
+	out.Cluster = in.Cluster
+	out.Provisioning = in.Provisioning
+	if in.ExtraManifests != nil {
+		in, out := &in.ExtraManifests, &out.ExtraManifests
+		*out = make([]ExtraManifestSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkshopSpec.
@@ -410,6 +751,41 @@ func (in *WorkshopSpec) DeepCopy() *WorkshopSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkshopStatus) DeepCopyInto(out *WorkshopStatus) {
 	*out = *in
+	if in.AddOns != nil {
+		in, out := &in.AddOns, &out.AddOns
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.InstalledOperators != nil {
+		in, out := &in.InstalledOperators, &out.InstalledOperators
+		*out = make([]InstalledOperatorStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraManifests != nil {
+		in, out := &in.ExtraManifests, &out.ExtraManifests
+		*out = make([]ExtraManifestStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Attendees != nil {
+		in, out := &in.Attendees, &out.Attendees
+		*out = make([]AttendeeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkshopStatus.
@@ -4,6 +4,7 @@ import (
 	argocdoperator "github.com/argoproj-labs/argocd-operator/pkg/apis/argoproj/v1alpha1"
 	argocd "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -19,7 +20,7 @@ func NewArgoCDCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Sche
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: argocdoperator.ArgoCDSpec{
 			ApplicationInstanceLabelKey: "argocd.argoproj.io/instance",
@@ -50,7 +51,7 @@ func NewAppProjectCustomResource(workshop *workshopv1.Workshop, scheme *runtime.
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: argocd.AppProjectSpec{
 			Destinations: []argocd.ApplicationDestination{
@@ -1,9 +1,8 @@
 package bookbag
 
 import (
-	"fmt"
-
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,9 +12,8 @@ import (
 // NewDeployment create a deployment
 func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	name string, namespace string, labels map[string]string,
-	userID string, appsHostnameSuffix string, openshiftConsoleURL string) *appsv1.Deployment {
+	userID string, username string, password string, appsHostnameSuffix string, openshiftConsoleURL string) *appsv1.Deployment {
 
-	user := fmt.Sprintf("user%s", userID)
 	image := workshop.Spec.Infrastructure.Guide.Bookbag.Image.Name + ":" + workshop.Spec.Infrastructure.Guide.Bookbag.Image.Tag
 	consoleImage := "quay.io/openshift/origin-console:4.2"
 
@@ -23,13 +21,13 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	"OPENSHIFT_CONSOLE_URL": "` + openshiftConsoleURL + `",
 	"APPS_HOSTNAME_SUFFIX": "` + appsHostnameSuffix + `",
 	"USER_ID": "` + userID + `",
-	"OPENSHIFT_PASSWORD": "` + workshop.Spec.UserDetails.DefaultPassword + `",
-	"CHE_URL": "http://codeready-workspaces.` + appsHostnameSuffix + `",
-	"GIT_URL": "https://gitea-server-gitea.` + appsHostnameSuffix + `",
-	"JAEGER_URL": "https://jaeger-istio-system.` + appsHostnameSuffix + `",
-	"KIALI_URL": "https://kiali-istio-system.` + appsHostnameSuffix + `",
+	"OPENSHIFT_PASSWORD": "` + password + `",
+	"CHE_URL": "http://codeready-` + util.ScopedName(workshop, "workspaces") + `.` + appsHostnameSuffix + `",
+	"GIT_URL": "https://gitea-server-` + util.ScopedName(workshop, "gitea") + `.` + appsHostnameSuffix + `",
+	"JAEGER_URL": "https://jaeger-` + util.ScopedName(workshop, "istio-system") + `.` + appsHostnameSuffix + `",
+	"KIALI_URL": "https://kiali-` + util.ScopedName(workshop, "istio-system") + `.` + appsHostnameSuffix + `",
 	"KIBANA_URL": "https://kibana-openshift-logging.` + appsHostnameSuffix + `",
-	"GITOPS_URL": "https://argocd-server-argocd.` + appsHostnameSuffix + `",
+	"GITOPS_URL": "https://argocd-server-` + util.ScopedName(workshop, "argocd") + `.` + appsHostnameSuffix + `",
 	"WORKSHOP_GIT_REPO": "` + workshop.Spec.Source.GitURL + `",
 	"WORKSHOP_GIT_REF": "` + workshop.Spec.Source.GitBranch + `"
 }`
@@ -42,7 +40,7 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{MatchLabels: labels},
@@ -138,11 +136,11 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 								},
 								{
 									Name:  "AUTH_USERNAME",
-									Value: user,
+									Value: username,
 								},
 								{
 									Name:  "AUTH_PASSWORD",
-									Value: workshop.Spec.UserDetails.DefaultPassword,
+									Value: password,
 								},
 								{
 									Name:  "OAUTH_SERVICE_ACCOUNT",
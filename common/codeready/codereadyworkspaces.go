@@ -3,6 +3,7 @@ package codeready
 import (
 	che "github.com/eclipse/che-operator/pkg/apis/org/v1"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -24,6 +25,12 @@ type clientRoles struct {
 	RealmManagement []string `json:"realm-management"`
 }
 
+// Workspace is the part of a workspace returned by the CodeReady API which the operator reads
+type Workspace struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
 // NewCustomResource creates a Custom Resource
 func NewCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	name string, namespace string) *che.CheCluster {
@@ -43,6 +50,7 @@ func NewCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, nil),
 		},
 		Spec: che.CheClusterSpec{
 			Server: che.CheClusterSpecServer{
@@ -87,11 +95,11 @@ func NewCustomResource(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 }
 
 // NewUser creates a user
-func NewUser(username string, password string) *codeReadyUser {
+func NewUser(username string, password string, email string) *codeReadyUser {
 	return &codeReadyUser{
 		Username: username,
 		Enabled:  true,
-		Email:    username + "@none.com",
+		Email:    email,
 		Credentials: []credential{
 			{
 				Type:  "password",
@@ -0,0 +1,156 @@
+package helm
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"path"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// Image runs the Helm CLI in the Jobs installing and uninstalling the charts
+	Image = "alpine/helm:3.14.4"
+	// ReleaseLabel labels the Jobs and the values ConfigMap of a release with the name of the release
+	ReleaseLabel = "workshop.stakater.com/helm-release"
+	// DefaultsKey is the key of the values set by the operator in the values ConfigMap
+	DefaultsKey = "defaults.yaml"
+	// ValuesKey is the key of the values of the Workshop spec in the values ConfigMap, applied on top of the defaults
+	ValuesKey = "values.yaml"
+	// Timeout bounds the wait of Helm for the resources of a release to be ready
+	Timeout = "15m"
+
+	valuesMountPath = "/etc/helm-values"
+	backoffLimit    = 2
+)
+
+// ReleaseSecretLabels selects the Secrets in which Helm stores the revisions of a release
+func ReleaseSecretLabels(release string) map[string]string {
+	return map[string]string{
+		"owner": "helm",
+		"name":  release,
+	}
+}
+
+// ValuesConfigMapName returns the name of the ConfigMap holding the values of a release
+func ValuesConfigMapName(release string) string {
+	return release + "-values"
+}
+
+// InstallJobName returns the name of the Job installing a revision of a release, which changes with the chart and its values
+func InstallJobName(release string, chart workshopv1.HelmChartSpec, defaults string) string {
+	digest := sha256.Sum256([]byte(chart.Repository + "\n" + chart.Chart + "\n" + chart.Version + "\n" + defaults + "\n" + chart.Values))
+	return fmt.Sprintf("%s-%x", release, digest[:5])
+}
+
+// UninstallJobName returns the name of the Job uninstalling a release
+func UninstallJobName(release string) string {
+	return release + "-uninstall"
+}
+
+// NewInstallJob returns a Job installing or upgrading a release with the values of its ConfigMap
+func NewInstallJob(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, release string, chart workshopv1.HelmChartSpec, serviceAccountName string) *batchv1.Job {
+
+	args := []string{"upgrade", "--install", release, chart.Chart}
+	if chart.Repository != "" {
+		args = append(args, "--repo", chart.Repository)
+	}
+	if chart.Version != "" {
+		args = append(args, "--version", chart.Version)
+	}
+	args = append(args,
+		"--namespace", namespace,
+		"--create-namespace",
+		"--values", path.Join(valuesMountPath, DefaultsKey),
+		"--values", path.Join(valuesMountPath, ValuesKey),
+		"--wait",
+		"--timeout", Timeout,
+	)
+
+	job := newJob(workshop, name, namespace, release, serviceAccountName, args)
+	job.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{
+			Name: "values",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: ValuesConfigMapName(release),
+					},
+				},
+			},
+		},
+	}
+	job.Spec.Template.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+		{
+			Name:      "values",
+			MountPath: valuesMountPath,
+			ReadOnly:  true,
+		},
+	}
+	return job
+}
+
+// NewUninstallJob returns a Job uninstalling a release
+func NewUninstallJob(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	namespace string, release string, serviceAccountName string) *batchv1.Job {
+
+	args := []string{"uninstall", release, "--namespace", namespace, "--wait", "--timeout", Timeout}
+	return newJob(workshop, UninstallJobName(release), namespace, release, serviceAccountName, args)
+}
+
+func newJob(workshop *workshopv1.Workshop, name string, namespace string, release string, serviceAccountName string, args []string) *batchv1.Job {
+	labels := map[string]string{
+		ReleaseLabel: release,
+	}
+	backoff := int32(backoffLimit)
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, labels),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoff,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: serviceAccountName,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:            "helm",
+							Image:           Image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Args:            args,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// IsJobFinished returns whether the Job completed or failed, and whether it failed
+func IsJobFinished(job *batchv1.Job) (bool, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch condition.Type {
+		case batchv1.JobComplete:
+			return true, false
+		case batchv1.JobFailed:
+			return true, true
+		}
+	}
+	return false, false
+}
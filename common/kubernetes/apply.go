@@ -0,0 +1,23 @@
+package kubernetes
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// FieldManager owns the fields set by the operator
+const FieldManager = "workshop-operator"
+
+// Apply creates the object, or updates the fields the operator sets, with server-side apply.
+// The fields set by someone else are left alone, the fields of the operator changed by someone else are repaired.
+func Apply(ctx context.Context, c client.Client, scheme *runtime.Scheme, obj runtime.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, scheme)
+	if err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return c.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+}
@@ -0,0 +1,66 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// IngressGVK is the kind of the Ingresses, networking.k8s.io/v1 which the Kubernetes API types vendored here predate
+var IngressGVK = schema.GroupVersionKind{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress"}
+
+// NewIngressObject returns an empty Ingress, to watch, read or delete them
+func NewIngressObject() *unstructured.Unstructured {
+	ingress := &unstructured.Unstructured{}
+	ingress.SetGroupVersionKind(IngressGVK)
+	return ingress
+}
+
+// NewIngress creates an Ingress, the Kubernetes counterpart of an OpenShift Route
+func NewIngress(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, serviceName string, port int32, host string) *unstructured.Unstructured {
+
+	ingress := NewIngressObject()
+	ingress.SetName(name)
+	ingress.SetNamespace(namespace)
+	ingress.SetLabels(util.WithOwnerLabels(workshop, labels))
+	ingress.Object["spec"] = map[string]interface{}{
+		"rules": []interface{}{
+			map[string]interface{}{
+				"host": host,
+				"http": map[string]interface{}{
+					"paths": []interface{}{
+						map[string]interface{}{
+							"path":     "/",
+							"pathType": "Prefix",
+							"backend": map[string]interface{}{
+								"service": map[string]interface{}{
+									"name": serviceName,
+									"port": map[string]interface{}{
+										"number": int64(port),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return ingress
+}
+
+// NewSecuredIngress creates an Ingress terminating TLS with the default certificate of the ingress controller
+func NewSecuredIngress(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, serviceName string, port int32, host string) *unstructured.Unstructured {
+
+	ingress := NewIngress(workshop, scheme, name, namespace, labels, serviceName, port, host)
+	ingress.Object["spec"].(map[string]interface{})["tls"] = []interface{}{
+		map[string]interface{}{
+			"hosts": []interface{}{host},
+		},
+	}
+	return ingress
+}
@@ -0,0 +1,24 @@
+package kubernetes
+
+import (
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// NewLimitRange creates a Limit Range
+func NewLimitRange(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, spec corev1.LimitRangeSpec) *corev1.LimitRange {
+
+	limitrange := &corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, labels),
+		},
+		Spec: spec,
+	}
+	return limitrange
+}
@@ -0,0 +1,76 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// DecodeManifests returns the resources of a YAML stream, with the ownership labels of the Workshop added.
+// The empty documents are skipped, each resource needs an apiVersion, a kind and a name.
+func DecodeManifests(workshop *workshopv1.Workshop, manifests string, labels map[string]string) ([]*unstructured.Unstructured, error) {
+	objects := []*unstructured.Unstructured{}
+	decoder := yaml.NewYAMLOrJSONDecoder(strings.NewReader(manifests), 4096)
+	for i := 0; ; i++ {
+		object := &unstructured.Unstructured{}
+		if err := decoder.Decode(&object.Object); err == io.EOF {
+			return objects, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("manifest %d: %w", i, err)
+		}
+		if len(object.Object) == 0 {
+			continue
+		}
+		if object.GetAPIVersion() == "" || object.GetKind() == "" || object.GetName() == "" {
+			return nil, fmt.Errorf("manifest %d: apiVersion, kind and metadata.name are required", i)
+		}
+		object.SetLabels(util.WithOwnerLabels(workshop, mergeLabels(object.GetLabels(), labels)))
+		objects = append(objects, object)
+	}
+}
+
+// ManifestReferences returns the references of the resources, to find them once they leave the manifests
+func ManifestReferences(objects []*unstructured.Unstructured) []workshopv1.ManifestReference {
+	references := make([]workshopv1.ManifestReference, 0, len(objects))
+	for _, object := range objects {
+		references = append(references, ManifestReference(object))
+	}
+	return references
+}
+
+// ManifestReference returns the reference of the resource
+func ManifestReference(object *unstructured.Unstructured) workshopv1.ManifestReference {
+	return workshopv1.ManifestReference{
+		APIVersion: object.GetAPIVersion(),
+		Kind:       object.GetKind(),
+		Namespace:  object.GetNamespace(),
+		Name:       object.GetName(),
+	}
+}
+
+// NewManifestObject returns an object standing for the referenced resource, to delete it
+func NewManifestObject(reference workshopv1.ManifestReference) *unstructured.Unstructured {
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion(reference.APIVersion)
+	object.SetKind(reference.Kind)
+	object.SetNamespace(reference.Namespace)
+	object.SetName(reference.Name)
+	return object
+}
+
+func mergeLabels(labels map[string]string, extra map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+len(extra))
+	for k, v := range labels {
+		result[k] = v
+	}
+	for k, v := range extra {
+		result[k] = v
+	}
+	return result
+}
@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	admissionregistration "k8s.io/api/admissionregistration/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -14,7 +15,7 @@ func NewMutatingWebhookConfiguration(workshop *workshopv1.Workshop, scheme *runt
 	mwc := &admissionregistration.MutatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   name,
-			Labels: labels,
+			Labels: util.WithOwnerLabels(workshop, labels),
 		},
 		Webhooks: webhooks,
 	}
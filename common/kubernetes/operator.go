@@ -2,6 +2,7 @@ package kubernetes
 
 import (
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -20,7 +21,7 @@ func NewAnsibleOperatorDeployment(workshop *workshopv1.Workshop, scheme *runtime
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{MatchLabels: labels},
@@ -64,7 +65,7 @@ func NewAnsibleOperatorDeployment(workshop *workshopv1.Workshop, scheme *runtime
 							Env: []corev1.EnvVar{
 								{
 									Name:  "WATCH_NAMESPACE",
-									Value: "",
+									Value: namespace,
 								},
 								{
 									Name:  "OPERATOR_NAME",
@@ -110,7 +111,7 @@ func NewOperatorDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{MatchLabels: labels},
@@ -141,7 +142,7 @@ func NewOperatorDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme
 							Env: []corev1.EnvVar{
 								{
 									Name:  "WATCH_NAMESPACE",
-									Value: "",
+									Value: namespace,
 								},
 								{
 									Name:  "OPERATOR_NAME",
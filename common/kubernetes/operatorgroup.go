@@ -3,6 +3,7 @@ package kubernetes
 import (
 	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -15,6 +16,7 @@ func NewOperatorGroup(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, nil),
 		},
 		Spec: olmv1.OperatorGroupSpec{
 			TargetNamespaces: []string{
@@ -24,3 +26,12 @@ func NewOperatorGroup(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	}
 	return operatorgroup
 }
+
+// NewAllNamespacesOperatorGroup creates an Operator Group letting its operators watch every namespace
+func NewAllNamespacesOperatorGroup(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string) *olmv1.OperatorGroup {
+
+	operatorgroup := NewOperatorGroup(workshop, scheme, name, namespace)
+	operatorgroup.Spec.TargetNamespaces = nil
+	return operatorgroup
+}
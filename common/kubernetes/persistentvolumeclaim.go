@@ -1,8 +1,8 @@
 package kubernetes
 
 import (
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -30,7 +30,7 @@ func NewPersistentVolumeClaim(workshop *workshopv1.Workshop, scheme *runtime.Sch
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: pvcSpec,
 	}
@@ -38,7 +38,7 @@ func NewPersistentVolumeClaim(workshop *workshopv1.Workshop, scheme *runtime.Sch
 	// Set Workshop instance as the owner and controller
 	err := ctrl.SetControllerReference(workshop, pvc, scheme)
 	if err != nil {
-		log.Error(err, " - Failed to set SetControllerReference for PVC - %s", name)
+		util.WorkshopLogger(workshop).Error(err, "Failed to set the controller reference", util.LogKind, "PersistentVolumeClaim", util.LogName, name)
 	}
 	return pvc
 
@@ -0,0 +1,39 @@
+package kubernetes
+
+import (
+	routev1 "github.com/openshift/api/route/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/rest"
+)
+
+// Platform is the flavour of cluster the Workshops are installed on
+type Platform string
+
+const (
+	// PlatformOpenShift exposes the tools with Routes and logs the users in through OpenShift OAuth
+	PlatformOpenShift Platform = "OpenShift"
+	// PlatformKubernetes exposes the tools with Ingresses and gives each user a Service Account token
+	PlatformKubernetes Platform = "Kubernetes"
+)
+
+// DetectPlatform returns OpenShift if the cluster serves Routes, Kubernetes otherwise
+func DetectPlatform(config *rest.Config) (Platform, error) {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := discoveryClient.ServerResourcesForGroupVersion(routev1.GroupVersion.String()); err != nil {
+		if errors.IsNotFound(err) {
+			return PlatformKubernetes, nil
+		}
+		return "", err
+	}
+	return PlatformOpenShift, nil
+}
+
+// RouteHost returns the host OpenShift gives to a Route without one, the Ingresses use the same
+func RouteHost(name string, namespace string, appsHostnameSuffix string) string {
+	return name + "-" + namespace + "." + appsHostnameSuffix
+}
@@ -0,0 +1,97 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// ReadinessRequeueAfter is the delay before checking again a workload which is not ready.
+// Watches usually wake the controller up earlier.
+const ReadinessRequeueAfter = 15 * time.Second
+
+// progressDeadlineExceeded is the reason set by the deployment controller when a rollout is stuck
+const progressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+// IsDeploymentReady returns true when the latest generation of the Deployment is rolled out
+// and all the desired replicas are ready
+func IsDeploymentReady(deployment *appsv1.Deployment) bool {
+	desired := desiredReplicas(deployment.Spec.Replicas)
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation &&
+		status.UpdatedReplicas >= desired &&
+		status.ReadyReplicas >= desired &&
+		status.AvailableReplicas >= desired
+}
+
+// IsStatefulSetReady returns true when the latest revision of the StatefulSet is rolled out
+// and all the desired replicas are ready
+func IsStatefulSetReady(statefulSet *appsv1.StatefulSet) bool {
+	desired := desiredReplicas(statefulSet.Spec.Replicas)
+	status := statefulSet.Status
+	return status.ObservedGeneration >= statefulSet.Generation &&
+		status.ReadyReplicas >= desired &&
+		(status.UpdateRevision == "" || status.UpdateRevision == status.CurrentRevision)
+}
+
+// CheckDeploymentReady returns a RequeueAfter result until the Deployment is ready.
+// It returns an error when the rollout exceeded its progress deadline.
+func CheckDeploymentReady(ctx context.Context, c client.Client, name string, namespace string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogKind, "Deployment", util.LogName, name, util.LogNamespace, namespace)
+	deployment := &appsv1.Deployment{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, deployment); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Waiting for creation")
+			return reconcile.Result{RequeueAfter: ReadinessRequeueAfter}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentProgressing && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == progressDeadlineExceeded {
+			return reconcile.Result{}, fmt.Errorf("%s Deployment in %s: %s", name, namespace, condition.Message)
+		}
+	}
+
+	if !IsDeploymentReady(deployment) {
+		log.Info("Waiting for readiness", "ready", deployment.Status.ReadyReplicas, "desired", desiredReplicas(deployment.Spec.Replicas))
+		return reconcile.Result{RequeueAfter: ReadinessRequeueAfter}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+// CheckStatefulSetReady returns a RequeueAfter result until the StatefulSet is ready
+func CheckStatefulSetReady(ctx context.Context, c client.Client, name string, namespace string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogKind, "StatefulSet", util.LogName, name, util.LogNamespace, namespace)
+	statefulSet := &appsv1.StatefulSet{}
+	if err := c.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, statefulSet); err != nil {
+		if errors.IsNotFound(err) {
+			log.Info("Waiting for creation")
+			return reconcile.Result{RequeueAfter: ReadinessRequeueAfter}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	if !IsStatefulSetReady(statefulSet) {
+		log.Info("Waiting for readiness", "ready", statefulSet.Status.ReadyReplicas, "desired", desiredReplicas(statefulSet.Spec.Replicas))
+		return reconcile.Result{RequeueAfter: ReadinessRequeueAfter}, nil
+	}
+	return reconcile.Result{}, nil
+}
+
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -1,8 +1,8 @@
 package kubernetes
 
 import (
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -17,13 +17,31 @@ func NewStringDataSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		StringData: stringData,
 	}
 	return secret
 }
 
+// NewDataSecret creates a Secret of the data, stored as is
+func NewDataSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, data map[string]string) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, labels),
+		},
+		Data: make(map[string][]byte, len(data)),
+	}
+	for key, value := range data {
+		secret.Data[key] = []byte(value)
+	}
+	return secret
+}
+
 // NewCrtSecret create a CRT Secret
 func NewCrtSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	name string, namespace string, labels map[string]string, crt []byte) *corev1.Secret {
@@ -32,7 +50,7 @@ func NewCrtSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Data: map[string][]byte{
 			"ca.crt": crt,
@@ -41,7 +59,25 @@ func NewCrtSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	// Set Workshop instance as the owner and controller
 	err := ctrl.SetControllerReference(workshop, secret, scheme)
 	if err != nil {
-		log.Error(err, " - Failed to set SetControllerReference for CRT Secret - %s", name)
+		util.WorkshopLogger(workshop).Error(err, "Failed to set the controller reference", util.LogKind, "Secret", util.LogName, name)
+	}
+	return secret
+}
+
+// NewServiceAccountTokenSecret creates a Secret which Kubernetes fills with a token of the Service Account
+func NewServiceAccountTokenSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, namespace string, labels map[string]string, serviceAccountName string) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, labels),
+			Annotations: map[string]string{
+				corev1.ServiceAccountNameKey: serviceAccountName,
+			},
+		},
+		Type: corev1.SecretTypeServiceAccountToken,
 	}
 	return secret
 }
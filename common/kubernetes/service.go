@@ -1,8 +1,8 @@
 package kubernetes
 
 import (
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -27,7 +27,7 @@ func NewService(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: corev1.ServiceSpec{
 			Ports:    ports,
@@ -55,7 +55,7 @@ func NewCustomService(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: ports,
@@ -65,7 +65,7 @@ func NewCustomService(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	// Set Workshop instance as the owner and controller
 	err := ctrl.SetControllerReference(workshop, service, scheme)
 	if err != nil {
-		log.Error(err, " - Failed to set SetControllerReference for Custom Service - %s", name)
+		util.WorkshopLogger(workshop).Error(err, "Failed to set the controller reference", util.LogKind, "Service", util.LogName, name)
 	}
 	return service
 }
@@ -90,7 +90,7 @@ func NewServiceWithTarget(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: corev1.ServiceSpec{
 			Ports:    ports,
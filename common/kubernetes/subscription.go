@@ -3,92 +3,97 @@ package kubernetes
 import (
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
-	ctrl "sigs.k8s.io/controller-runtime"
+	"github.com/stakater/workshop-operator/common/util"
 
-	"github.com/prometheus/common/log"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// Catalogs of the operators
+const (
+	CatalogRedHat    = "redhat-operators"
+	CatalogCertified = "certified-operators"
+	CatalogCommunity = "community-operators"
+	// CatalogNamespace is the namespace of the catalogs of the cluster
+	CatalogNamespace = "openshift-marketplace"
+)
+
 // NewCertifiedSubscription creates a Certified Subscription
 func NewCertifiedSubscription(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, namespace string, packageName string, channel string, startingCSV string) *olmv1alpha1.Subscription {
+	name string, namespace string, packageName string, operatorHub workshopv1.OperatorHubSpec) *olmv1alpha1.Subscription {
 
-	subscription := &olmv1alpha1.Subscription{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"csc-owner-name":      "certified-operators",
-				"csc-owner-namespace": "openshift-marketplace",
-			},
-		},
-		Spec: &olmv1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			CatalogSource:          "certified-operators",
-			CatalogSourceNamespace: "openshift-marketplace",
-			StartingCSV:            startingCSV,
-			InstallPlanApproval:    olmv1alpha1.ApprovalManual,
-			Package:                packageName,
-		},
-	}
-
-	return subscription
+	return newSubscription(workshop, name, namespace, packageName, CatalogCertified, operatorHub)
 }
 
 // NewCommunitySubscription creates a Community Subscription
 func NewCommunitySubscription(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, namespace string, packageName string, channel string, startingCSV string) *olmv1alpha1.Subscription {
-
-	subscription := &olmv1alpha1.Subscription{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      name,
-			Namespace: namespace,
-			Labels: map[string]string{
-				"csc-owner-name":      "community-operators",
-				"csc-owner-namespace": "openshift-marketplace",
-			},
-		},
-		Spec: &olmv1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			CatalogSource:          "community-operators",
-			CatalogSourceNamespace: "openshift-marketplace",
-			StartingCSV:            startingCSV,
-			InstallPlanApproval:    olmv1alpha1.ApprovalManual,
-			Package:                packageName,
-		},
-	}
+	name string, namespace string, packageName string, operatorHub workshopv1.OperatorHubSpec) *olmv1alpha1.Subscription {
 
-	// Set Workshop instance as the owner and controller
-	err := ctrl.SetControllerReference(workshop, subscription, scheme)
-	if err != nil {
-		log.Error(err, " - Failed to set SetControllerReference for Community Subscription - %s", name)
-	}
-	return subscription
+	return newSubscription(workshop, name, namespace, packageName, CatalogCommunity, operatorHub)
 }
 
 // NewRedHatSubscription creates a Red Hat Subscription
 func NewRedHatSubscription(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, namespace string, packageName string, channel string, startingCSV string) *olmv1alpha1.Subscription {
+	name string, namespace string, packageName string, operatorHub workshopv1.OperatorHubSpec) *olmv1alpha1.Subscription {
+
+	return newSubscription(workshop, name, namespace, packageName, CatalogRedHat, operatorHub)
+}
+
+// SubscriptionNamespace returns the namespace of the Subscription of an operator: the target namespace of the spec, if any
+func SubscriptionNamespace(operatorHub workshopv1.OperatorHubSpec, namespace string) string {
+	if operatorHub.TargetNamespace != "" {
+		return operatorHub.TargetNamespace
+	}
+	return namespace
+}
+
+// newSubscription creates a Subscription to the package of the catalog, both replaced by the OperatorHub spec if it sets them.
+// The InstallPlans are approved manually unless the spec asks for the automatic approval.
+func newSubscription(workshop *workshopv1.Workshop, name string, namespace string, packageName string, catalogSource string,
+	operatorHub workshopv1.OperatorHubSpec) *olmv1alpha1.Subscription {
+	catalogSourceNamespace := CatalogNamespace
+	if operatorHub.CatalogSource != "" {
+		catalogSource = operatorHub.CatalogSource
+	}
+	if operatorHub.CatalogSourceNamespace != "" {
+		catalogSourceNamespace = operatorHub.CatalogSourceNamespace
+	}
+	if operatorHub.Package != "" {
+		packageName = operatorHub.Package
+	}
+	approval := olmv1alpha1.ApprovalManual
+	if operatorHub.InstallPlanApproval == workshopv1.InstallPlanApprovalAutomatic {
+		approval = olmv1alpha1.ApprovalAutomatic
+	}
 
 	subscription := &olmv1alpha1.Subscription{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels: map[string]string{
-				"csc-owner-name":      "redhat-operators",
-				"csc-owner-namespace": "openshift-marketplace",
-			},
+			Labels: util.WithOwnerLabels(workshop, map[string]string{
+				"csc-owner-name":      catalogSource,
+				"csc-owner-namespace": catalogSourceNamespace,
+			}),
 		},
 		Spec: &olmv1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			CatalogSource:          "redhat-operators",
-			CatalogSourceNamespace: "openshift-marketplace",
-			StartingCSV:            startingCSV,
-			InstallPlanApproval:    olmv1alpha1.ApprovalManual,
+			Channel:                operatorHub.Channel,
+			CatalogSource:          catalogSource,
+			CatalogSourceNamespace: catalogSourceNamespace,
+			StartingCSV:            operatorHub.ClusterServiceVersion,
+			InstallPlanApproval:    approval,
 			Package:                packageName,
 		},
 	}
+
+	if config := operatorHub.Config; config != nil {
+		subscription.Spec.Config = olmv1alpha1.SubscriptionConfig{
+			Env:          config.Env,
+			NodeSelector: config.NodeSelector,
+		}
+		if config.Resources != nil {
+			subscription.Spec.Config.Resources = *config.Resources
+		}
+	}
 	return subscription
 }
 
@@ -101,24 +106,18 @@ func NewCustomSubscription(workshop *workshopv1.Workshop, scheme *runtime.Scheme
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Labels: map[string]string{
+			Labels: util.WithOwnerLabels(workshop, map[string]string{
 				"csc-owner-name":      "custom-operators",
 				"csc-owner-namespace": "openshift-marketplace",
-			},
+			}),
 		},
 		Spec: &olmv1alpha1.SubscriptionSpec{
 			Channel:                channel,
 			CatalogSource:          catalogSource,
-			CatalogSourceNamespace: "openshift-marketplace",
+			CatalogSourceNamespace: CatalogNamespace,
 			Package:                packageName,
 		},
 	}
-
-	// Set Workshop instance as the owner and controller
-	err := ctrl.SetControllerReference(workshop, subscription, scheme)
-	if err != nil {
-		log.Error(err, " - Failed to set SetControllerReference for Custom Subscription - %s", name)
-	}
 	return subscription
 }
 
@@ -130,6 +129,7 @@ func NewRedHatClusterServiceVersion(workshop *workshopv1.Workshop, scheme *runti
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      startingCSV,
 			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, nil),
 		},
 	}
 	return csv
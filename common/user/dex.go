@@ -0,0 +1,63 @@
+package user
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"hash/fnv"
+	"strings"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// DexPasswordGroupVersionKind is the kind of the passwords of the Kubernetes storage of Dex
+var DexPasswordGroupVersionKind = schema.GroupVersionKind{Group: "dex.coreos.com", Version: "v1", Kind: "Password"}
+
+// dexNameEncoding is the encoding of the names of the resources of the Kubernetes storage of Dex
+var dexNameEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567")
+
+// NewDexPasswordObject returns an empty Dex password, to read one
+func NewDexPasswordObject() *unstructured.Unstructured {
+	password := &unstructured.Unstructured{}
+	password.SetGroupVersionKind(DexPasswordGroupVersionKind)
+	return password
+}
+
+// NewDexPasswordList returns an empty list of Dex passwords
+func NewDexPasswordList() *unstructured.UnstructuredList {
+	passwords := &unstructured.UnstructuredList{}
+	passwords.SetGroupVersionKind(DexPasswordGroupVersionKind.GroupVersion().WithKind(DexPasswordGroupVersionKind.Kind + "List"))
+	return passwords
+}
+
+// DexPasswordName returns the name Dex gives to the password of the email
+func DexPasswordName(email string) string {
+	return strings.TrimRight(dexNameEncoding.EncodeToString(fnv.New64().Sum([]byte(strings.ToLower(email)))), "=")
+}
+
+// NewDexPassword creates the password of a user in the Kubernetes storage of Dex, the user logs in with the email
+func NewDexPassword(workshop *workshopv1.Workshop, namespace string, email string, username string, hash string,
+	labels map[string]string) *unstructured.Unstructured {
+
+	password := NewDexPasswordObject()
+	password.SetName(DexPasswordName(email))
+	password.SetNamespace(namespace)
+	password.SetLabels(util.WithOwnerLabels(workshop, labels))
+	password.Object["email"] = strings.ToLower(email)
+	password.Object["hash"] = base64.StdEncoding.EncodeToString([]byte(hash))
+	password.Object["username"] = username
+	password.Object["userID"] = username
+	return password
+}
+
+// DexPasswordHash returns the bcrypt hash of a Dex password
+func DexPasswordHash(password *unstructured.Unstructured) string {
+	encoded, _, _ := unstructured.NestedString(password.Object, "hash")
+	hash, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return ""
+	}
+	return string(hash)
+}
@@ -0,0 +1,65 @@
+package user
+
+import (
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Htpasswd holds the entries of an htpasswd file, the bcrypt hash of the password by user name
+type Htpasswd map[string]string
+
+// ParseHtpasswd parses the content of an htpasswd file
+func ParseHtpasswd(data []byte) Htpasswd {
+	htpasswd := Htpasswd{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if parts := strings.SplitN(line, ":", 2); len(parts) == 2 {
+			htpasswd[parts[0]] = parts[1]
+		}
+	}
+	return htpasswd
+}
+
+// SetPassword sets the password of the user of the Workshop. The existing hash is kept when it already matches
+// the password, so that the file only changes when a password does. It returns true if the entry changed.
+func (h Htpasswd) SetPassword(passwords *PasswordCache, workshop types.UID, username string, password string) (bool, error) {
+	if hash, found := h[username]; found && passwords.Matches(workshop, username, hash, password) {
+		return false, nil
+	}
+
+	hash, err := passwords.Hash(workshop, username, password)
+	if err != nil {
+		return false, err
+	}
+	h[username] = hash
+	return true, nil
+}
+
+// Remove removes the entry of the user. It returns true if the entry existed.
+func (h Htpasswd) Remove(username string) bool {
+	if _, found := h[username]; !found {
+		return false
+	}
+	delete(h, username)
+	return true
+}
+
+// Bytes returns the content of the htpasswd file, sorted by user name
+func (h Htpasswd) Bytes() []byte {
+	usernames := make([]string, 0, len(h))
+	for username := range h {
+		usernames = append(usernames, username)
+	}
+	sort.Strings(usernames)
+
+	var htpasswds []byte
+	for _, username := range usernames {
+		htpasswds = append(htpasswds, []byte(username+":"+h[username]+"\n")...)
+	}
+	return htpasswds
+}
@@ -0,0 +1,111 @@
+package user
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"sync"
+
+	"golang.org/x/crypto/bcrypt"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// PasswordCache remembers the bcrypt hashes found to match the passwords of the users of the Workshops:
+// comparing a password with a bcrypt hash costs as much as hashing it, which the reconciliations of all
+// the users would otherwise pay every time. A password is only kept as its HMAC under a key drawn when
+// the cache is created, and only as long as one of the hashes of its user matches it.
+type PasswordCache struct {
+	key     []byte
+	lock    sync.Mutex
+	entries map[passwordEntry][]byte
+}
+
+// passwordEntry identifies a hash of the password of a user of a Workshop
+type passwordEntry struct {
+	workshop types.UID
+	username string
+	hash     string
+}
+
+// NewPasswordCache creates an empty PasswordCache
+func NewPasswordCache() (*PasswordCache, error) {
+	key := make([]byte, sha256.Size)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	return &PasswordCache{key: key, entries: map[passwordEntry][]byte{}}, nil
+}
+
+// Matches returns true if the bcrypt hash is the one of the password of the user of the Workshop.
+// The comparison only runs for the hashes not verified yet, or once the password changed.
+func (c *PasswordCache) Matches(workshop types.UID, username string, hash string, password string) bool {
+	entry := passwordEntry{workshop: workshop, username: username, hash: hash}
+	mac := c.mac(password)
+
+	c.lock.Lock()
+	verified, found := c.entries[entry]
+	c.lock.Unlock()
+	if found && hmac.Equal(verified, mac) {
+		return true
+	}
+
+	if bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) != nil {
+		c.lock.Lock()
+		delete(c.entries, entry)
+		c.lock.Unlock()
+		return false
+	}
+	c.store(entry, mac)
+	return true
+}
+
+// Hash returns the bcrypt hash of the password of the user of the Workshop, known to match it from now on
+func (c *PasswordCache) Hash(workshop types.UID, username string, password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	c.store(passwordEntry{workshop: workshop, username: username, hash: string(hash)}, c.mac(password))
+	return string(hash), nil
+}
+
+// Forget drops the hashes of the user of the Workshop
+func (c *PasswordCache) Forget(workshop types.UID, username string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for entry := range c.entries {
+		if entry.workshop == workshop && entry.username == username {
+			delete(c.entries, entry)
+		}
+	}
+}
+
+// ForgetWorkshop drops the hashes of all the users of the Workshop
+func (c *PasswordCache) ForgetWorkshop(workshop types.UID) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for entry := range c.entries {
+		if entry.workshop == workshop {
+			delete(c.entries, entry)
+		}
+	}
+}
+
+// store records the hash as matching the password, and drops the hashes of the former passwords of the user
+func (c *PasswordCache) store(entry passwordEntry, mac []byte) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for other, otherMAC := range c.entries {
+		if other.workshop == entry.workshop && other.username == entry.username && !hmac.Equal(otherMAC, mac) {
+			delete(c.entries, other)
+		}
+	}
+	c.entries[entry] = mac
+}
+
+// mac returns the HMAC of the password under the key of the cache
+func (c *PasswordCache) mac(password string) []byte {
+	h := hmac.New(sha256.New, c.key)
+	h.Write([]byte(password))
+	return h.Sum(nil)
+}
@@ -3,25 +3,40 @@ package user
 import (
 	userv1 "github.com/openshift/api/user/v1"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
 )
 
 // NewUser create an user
-func NewUser(workshop *workshopv1.Workshop, scheme *runtime.Scheme, username string, labels map[string]string) *userv1.User {
+func NewUser(workshop *workshopv1.Workshop, scheme *runtime.Scheme, username string, fullName string, labels map[string]string) *userv1.User {
 
 	user := &userv1.User{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   username,
-			Labels: labels,
+			Labels: util.WithOwnerLabels(workshop, labels),
 		},
-		FullName: username,
+		FullName: fullName,
 	}
 	return user
 }
 
+// NewGroup create a group of users
+func NewGroup(workshop *workshopv1.Workshop, scheme *runtime.Scheme, name string, labels map[string]string, usernames []string) *userv1.Group {
+
+	group := &userv1.Group{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: util.WithOwnerLabels(workshop, labels),
+		},
+		Users: usernames,
+	}
+	return group
+}
+
 // NewUserRoleBinding create a RoleBinding for user
 func NewUserRoleBinding(workshop *workshopv1.Workshop, scheme *runtime.Scheme, username string, namespace string,
 	roleName string, roleKind string) *rbac.RoleBinding {
@@ -30,6 +45,7 @@ func NewUserRoleBinding(workshop *workshopv1.Workshop, scheme *runtime.Scheme, u
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      username,
 			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, nil),
 		},
 		Subjects: []rbac.Subject{
 			{
@@ -68,7 +84,8 @@ func NewIdentity(workshop *workshopv1.Workshop, scheme *runtime.Scheme, username
 
 	identity := &userv1.Identity{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: identityName + ":" + username,
+			Name:   identityName + ":" + username,
+			Labels: util.WithOwnerLabels(workshop, nil),
 		},
 		ProviderName:     identityName,
 		ProviderUserName: username,
@@ -96,3 +113,28 @@ func NewUserIdentityMapping(workshop *workshopv1.Workshop, scheme *runtime.Schem
 	}
 	return userIdentity
 }
+
+// NewCredentialsSecret create a Secret holding the credentials of an user
+func NewCredentialsSecret(workshop *workshopv1.Workshop, scheme *runtime.Scheme, name string, namespace string,
+	labels map[string]string, username string, password string) *corev1.Secret {
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    util.WithOwnerLabels(workshop, labels),
+		},
+		Type: corev1.SecretTypeBasicAuth,
+		Data: map[string][]byte{
+			corev1.BasicAuthUsernameKey: []byte(username),
+			corev1.BasicAuthPasswordKey: []byte(password),
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	err := ctrl.SetControllerReference(workshop, secret, scheme)
+	if err != nil {
+		util.WorkshopLogger(workshop).Error(err, "Failed to set the controller reference", util.LogKind, "Secret", util.LogName, name)
+	}
+	return secret
+}
@@ -1,12 +1,13 @@
 package usernamedistribution
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
 	"strconv"
 
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -14,36 +15,54 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// NewDeployment create a deployment
-func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
-	name string, labels map[string]string, redisServiceName string, users int,
-	appsHostnameSuffix string, openshiftConsoleURL string) *appsv1.Deployment {
+// defaultModuleURLs are the guides of the portal when the Workshop has no Scholars guides
+const defaultModuleURLs = "https://docs.openshift.com/container-platform/latest/welcome/index.html;openshift_docs"
+
+// moduleURLs returns the LAB_MODULE_URLS of the portal: the Scholars guides of the Workshop, with the password of the user
+func moduleURLs(workshop *workshopv1.Workshop, appsHostnameSuffix string, password string) string {
+	if !workshop.Spec.Infrastructure.Guide.Scholars.Enabled {
+		return defaultModuleURLs
+	}
 
-	image := "quay.io/mcouliba/username-distribution:latest"
-	labModuleURLs := "https://docs.openshift.com/container-platform/latest/welcome/index.html;openshift_docs"
 	guideURLParameters := "APPS_HOSTNAME_SUFFIX=" + appsHostnameSuffix +
 		"&USER_ID=%USER_ID%" +
-		"&OPENSHIFT_PASSWORD=" + workshop.Spec.UserDetails.DefaultPassword +
+		"&OPENSHIFT_PASSWORD=" + password +
 		"&WORKSHOP_GIT_REPO=" + url.QueryEscape(workshop.Spec.Source.GitURL) +
 		"&WORKSHOP_GIT_REF=" + workshop.Spec.Source.GitBranch
 
-	if workshop.Spec.Infrastructure.Guide.Scholars.Enabled {
-		isFirst := true
-		for guideName, guideURL := range workshop.Spec.Infrastructure.Guide.Scholars.GuideURL {
-			if isFirst {
-				labModuleURLs = fmt.Sprintf("%s?%s;%s", guideURL, guideURLParameters, guideName)
-				isFirst = false
-			} else {
-				labModuleURLs = fmt.Sprintf("%s,%s?%s;%s", labModuleURLs, guideURL, guideURLParameters, guideName)
-			}
+	labModuleURLs := defaultModuleURLs
+	isFirst := true
+	for guideName, guideURL := range workshop.Spec.Infrastructure.Guide.Scholars.GuideURL {
+		if isFirst {
+			labModuleURLs = fmt.Sprintf("%s?%s;%s", guideURL, guideURLParameters, guideName)
+			isFirst = false
+		} else {
+			labModuleURLs = fmt.Sprintf("%s,%s?%s;%s", labModuleURLs, guideURL, guideURLParameters, guideName)
 		}
 	}
+	return labModuleURLs
+}
+
+// NewDeployment create a deployment
+func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string, redisServiceName string,
+	appsHostnameSuffix string, openshiftConsoleURL string) *appsv1.Deployment {
+
+	image := "quay.io/mcouliba/username-distribution:latest"
+
+	// The portal distributes the users generated from the user name prefix, not the users listed explicitly
+	users := workshop.Spec.UserDetails.NumberOfUsers
+	if users < 0 {
+		users = 0
+	}
+	userPassword := workshop.Spec.UserDetails.DefaultPassword
+	labModuleURLs := moduleURLs(workshop, appsHostnameSuffix, url.QueryEscape(userPassword))
 
 	dep := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: workshop.Namespace,
-			Labels:    labels,
+			Labels:    util.WithOwnerLabels(workshop, labels),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Selector: &metav1.LabelSelector{MatchLabels: labels},
@@ -85,11 +104,11 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 								},
 								{
 									Name:  "LAB_USER_PASS",
-									Value: workshop.Spec.UserDetails.DefaultPassword,
+									Value: userPassword,
 								},
 								{
 									Name:  "LAB_USER_PREFIX",
-									Value: "user",
+									Value: workshop.Spec.UserDetails.UserNamePrefix,
 								},
 								{
 									Name:  "LAB_USER_PAD_ZERO",
@@ -122,7 +141,91 @@ func NewDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
 	// Set Workshop instance as the owner and controller
 	err := ctrl.SetControllerReference(workshop, dep, scheme)
 	if err != nil {
-		log.Error(err, "Failed to set SetControllerReference")
+		util.WorkshopLogger(workshop).Error(err, "Failed to set the controller reference", util.LogKind, "Deployment", util.LogName, name)
 	}
 	return dep
 }
+
+// NewCredentialsDeployment creates the deployment of the portal of a Workshop with generated passwords: the operator
+// image serving each attendee the password of their user, read from its credentials Secret, see Server
+func NewCredentialsDeployment(workshop *workshopv1.Workshop, scheme *runtime.Scheme,
+	name string, labels map[string]string, image string, serviceAccountName string, assignments string, users []User,
+	appsHostnameSuffix string, openshiftConsoleURL string) (*appsv1.Deployment, error) {
+
+	portalUsers, err := json.Marshal(users)
+	if err != nil {
+		return nil, err
+	}
+
+	dep := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: workshop.Namespace,
+			Labels:    util.WithOwnerLabels(workshop, labels),
+		},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Strategy: appsv1.DeploymentStrategy{
+				Type: appsv1.RollingUpdateDeploymentStrategyType,
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labels,
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: serviceAccountName,
+					Containers: []corev1.Container{
+						{
+							Name:    name,
+							Command: []string{"/manager", "--portal"},
+							Env: []corev1.EnvVar{
+								{
+									Name:  EnvNamespace,
+									Value: workshop.Namespace,
+								},
+								{
+									Name:  EnvAssignments,
+									Value: assignments,
+								},
+								{
+									Name:  EnvUsers,
+									Value: string(portalUsers),
+								},
+								{
+									Name:  EnvTitle,
+									Value: "OpenShift Workshops",
+								},
+								{
+									Name:  EnvAccessToken,
+									Value: workshop.Spec.UserDetails.DefaultPassword,
+								},
+								{
+									Name:  EnvConsoleURL,
+									Value: openshiftConsoleURL,
+								},
+								{
+									Name:  EnvModuleURLs,
+									Value: moduleURLs(workshop, appsHostnameSuffix, "%PASSWORD%"),
+								},
+							},
+							Image:           image,
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: 8080,
+									Protocol:      "TCP",
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// Set Workshop instance as the owner and controller
+	if err := ctrl.SetControllerReference(workshop, dep, scheme); err != nil {
+		util.WorkshopLogger(workshop).Error(err, "Failed to set the controller reference", util.LogKind, "Deployment", util.LogName, name)
+	}
+	return dep, nil
+}
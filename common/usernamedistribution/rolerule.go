@@ -0,0 +1,24 @@
+package usernamedistribution
+
+import (
+	rbac "k8s.io/api/rbac/v1"
+)
+
+// NewRules returns the rules of the portal of a Workshop with generated passwords: recording the assignments
+// in their ConfigMap, and reading the credentials Secrets of the users, none of the other Secrets of the namespace
+func NewRules(assignments string, secrets []string) []rbac.PolicyRule {
+	return []rbac.PolicyRule{
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"configmaps"},
+			ResourceNames: []string{assignments},
+			Verbs:         []string{"get", "update"},
+		},
+		{
+			APIGroups:     []string{""},
+			Resources:     []string{"secrets"},
+			ResourceNames: secrets,
+			Verbs:         []string{"get"},
+		},
+	}
+}
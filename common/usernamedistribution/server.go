@@ -0,0 +1,213 @@
+package usernamedistribution
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Environment of the portal of the Workshops with generated passwords
+const (
+	EnvNamespace   = "PORTAL_NAMESPACE"
+	EnvUsers       = "PORTAL_USERS"
+	EnvAssignments = "PORTAL_ASSIGNMENTS"
+	EnvAccessToken = "LAB_USER_ACCESS_TOKEN"
+	EnvTitle       = "LAB_TITLE"
+	EnvModuleURLs  = "LAB_MODULE_URLS"
+	EnvConsoleURL  = "OPENSHIFT_CONSOLE_URL"
+)
+
+// maxAssignAttempts bounds the retries of an assignment conflicting with another one
+const maxAssignAttempts = 5
+
+// User is a user handed out by the portal, with the Secret of its credentials
+type User struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Secret   string `json:"secret"`
+}
+
+// Module is a guide of the Workshop, its URL holding the %USERNAME%, %USER_ID% and %PASSWORD% placeholders
+type Module struct {
+	Name string
+	URL  string
+}
+
+// Server hands out the users of a Workshop with generated passwords: an attendee signs in with an email address
+// and the access token of the portal, and gets a user of their own with its password, read from the credentials Secret
+// of the user. The users of the attendees are recorded by username in the assignments ConfigMap, so that an attendee
+// signing in again gets the same user, and that they survive the restarts of the portal.
+type Server struct {
+	Client      client.Client
+	Namespace   string
+	Users       []User
+	Assignments string
+	AccessToken string
+	Title       string
+	ConsoleURL  string
+	Modules     []Module
+
+	// lock serializes the assignments of the portal, the ConfigMap resource version those of several replicas
+	lock sync.Mutex
+}
+
+// NewServerFromEnv creates the Server configured by the environment of the portal Deployment
+func NewServerFromEnv(c client.Client) (*Server, error) {
+	server := &Server{
+		Client:      c,
+		Namespace:   os.Getenv(EnvNamespace),
+		Assignments: os.Getenv(EnvAssignments),
+		AccessToken: os.Getenv(EnvAccessToken),
+		Title:       os.Getenv(EnvTitle),
+		ConsoleURL:  os.Getenv(EnvConsoleURL),
+	}
+	if server.Namespace == "" || server.Assignments == "" || server.AccessToken == "" {
+		return nil, fmt.Errorf("%s, %s and %s must be set", EnvNamespace, EnvAssignments, EnvAccessToken)
+	}
+	if err := json.Unmarshal([]byte(os.Getenv(EnvUsers)), &server.Users); err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", EnvUsers, err)
+	}
+	for _, module := range strings.Split(os.Getenv(EnvModuleURLs), ",") {
+		if parts := strings.SplitN(module, ";", 2); len(parts) == 2 {
+			server.Modules = append(server.Modules, Module{URL: parts[0], Name: parts[1]})
+		}
+	}
+	return server, nil
+}
+
+// page is the content of the page of the portal
+type page struct {
+	Title      string
+	Error      string
+	Username   string
+	Password   string
+	ConsoleURL string
+	Modules    []Module
+}
+
+var pageTemplate = template.Must(template.New("portal").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Title}}</title></head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Username}}
+<p>Username: <code>{{.Username}}</code></p>
+<p>Password: <code>{{.Password}}</code></p>
+{{if .ConsoleURL}}<p><a href="{{.ConsoleURL}}">OpenShift console</a></p>{{end}}
+<ul>{{range .Modules}}<li><a href="{{.URL}}">{{.Name}}</a></li>{{end}}</ul>
+{{else}}
+{{if .Error}}<p><strong>{{.Error}}</strong></p>{{end}}
+<form method="post">
+<p><label>Email address <input type="email" name="email" required></label></p>
+<p><label>Access token <input type="password" name="accessToken" required></label></p>
+<p><input type="submit" value="Get a user"></p>
+</form>
+{{end}}
+</body>
+</html>
+`))
+
+// ServeHTTP serves the sign-in form, and the user of the attendee once signed in
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	content := page{Title: s.Title}
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		email := strings.ToLower(strings.TrimSpace(req.FormValue("email")))
+		if email == "" || subtle.ConstantTimeCompare([]byte(req.FormValue("accessToken")), []byte(s.AccessToken)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			content.Error = "Wrong email address or access token"
+			break
+		}
+		user, password, err := s.assign(req.Context(), email)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if user == nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			content.Error = "All the users are taken"
+			break
+		}
+		content.Username = user.Username
+		content.Password = password
+		content.ConsoleURL = s.ConsoleURL
+		for _, module := range s.Modules {
+			content.Modules = append(content.Modules, Module{Name: module.Name, URL: strings.NewReplacer(
+				"%USERNAME%", url.QueryEscape(user.Username),
+				"%USER_ID%", url.QueryEscape(user.ID),
+				"%PASSWORD%", url.QueryEscape(password)).Replace(module.URL)})
+		}
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = pageTemplate.Execute(w, content)
+}
+
+// assign returns the user of the attendee and its password, assigning the first free user to a new attendee.
+// It returns a nil user when all the users are taken.
+func (s *Server) assign(ctx context.Context, email string) (*User, string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		assignments := &corev1.ConfigMap{}
+		if err := s.Client.Get(ctx, types.NamespacedName{Name: s.Assignments, Namespace: s.Namespace}, assignments); err != nil {
+			return nil, "", err
+		}
+
+		var free *User
+		for i := range s.Users {
+			user := &s.Users[i]
+			switch assignments.Data[user.Username] {
+			case email:
+				password, err := s.password(ctx, user)
+				return user, password, err
+			case "":
+				if free == nil {
+					free = user
+				}
+			}
+		}
+		if free == nil {
+			return nil, "", nil
+		}
+
+		if assignments.Data == nil {
+			assignments.Data = map[string]string{}
+		}
+		assignments.Data[free.Username] = email
+		if err := s.Client.Update(ctx, assignments); errors.IsConflict(err) && attempt < maxAssignAttempts {
+			continue
+		} else if err != nil {
+			return nil, "", err
+		}
+		password, err := s.password(ctx, free)
+		return free, password, err
+	}
+}
+
+// password returns the password of the user, read from its credentials Secret
+func (s *Server) password(ctx context.Context, user *User) (string, error) {
+	secret := &corev1.Secret{}
+	if err := s.Client.Get(ctx, types.NamespacedName{Name: user.Secret, Namespace: s.Namespace}, secret); err != nil {
+		return "", err
+	}
+	return string(secret.Data[corev1.BasicAuthPasswordKey]), nil
+}
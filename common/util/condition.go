@@ -0,0 +1,52 @@
+package util
+
+import (
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FindCondition returns the condition of the given type or nil
+func FindCondition(conditions []workshopv1.Condition, conditionType string) *workshopv1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition of the same type.
+// LastTransitionTime only changes when the status changes.
+func SetCondition(conditions *[]workshopv1.Condition, newCondition workshopv1.Condition) {
+	existing := FindCondition(*conditions, newCondition.Type)
+	if existing == nil {
+		if newCondition.LastTransitionTime.IsZero() {
+			newCondition.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, newCondition)
+		return
+	}
+
+	if existing.Status != newCondition.Status {
+		existing.Status = newCondition.Status
+		if newCondition.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = metav1.Now()
+		} else {
+			existing.LastTransitionTime = newCondition.LastTransitionTime
+		}
+	}
+	existing.Reason = newCondition.Reason
+	existing.Message = newCondition.Message
+	existing.ObservedGeneration = newCondition.ObservedGeneration
+}
+
+// RemoveCondition removes the condition of the given type, if any
+func RemoveCondition(conditions *[]workshopv1.Condition, conditionType string) {
+	kept := (*conditions)[:0]
+	for _, condition := range *conditions {
+		if condition.Type != conditionType {
+			kept = append(kept, condition)
+		}
+	}
+	*conditions = kept
+}
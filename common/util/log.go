@@ -0,0 +1,44 @@
+package util
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// Keys of the structured logs
+const (
+	LogWorkshop  = "workshop"
+	LogComponent = "component"
+	LogUser      = "user"
+	LogNamespace = "namespace"
+	LogKind      = "kind"
+	LogName      = "name"
+)
+
+// Verbosity of the debug logs, enabled with --zap-log-level=debug
+const LogDebug = 1
+
+// Logger returns the logger of the reconciliation carried by the context,
+// or the controller-runtime logger outside of a reconciliation
+func Logger(ctx context.Context) logr.Logger {
+	if logger := logr.FromContext(ctx); logger != nil {
+		return logger
+	}
+	return ctrllog.Log
+}
+
+// WithLogValues returns a context carrying the logger of the context with the additional key/value pairs
+func WithLogValues(ctx context.Context, keysAndValues ...interface{}) context.Context {
+	return logr.NewContext(ctx, Logger(ctx).WithValues(keysAndValues...))
+}
+
+// WorkshopLogger returns the controller-runtime logger with the key of the Workshop, for the code which
+// has no reconciliation context
+func WorkshopLogger(workshop *workshopv1.Workshop) logr.Logger {
+	return ctrllog.Log.WithValues(LogWorkshop, types.NamespacedName{Namespace: workshop.Namespace, Name: workshop.Name})
+}
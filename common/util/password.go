@@ -0,0 +1,22 @@
+package util
+
+import (
+	"crypto/rand"
+	"math/big"
+)
+
+const passwordCharacters = "abcdefghijkmnopqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+// GeneratePassword returns a random password of the given length, without ambiguous characters
+func GeneratePassword(length int) (string, error) {
+	max := big.NewInt(int64(len(passwordCharacters)))
+	password := make([]byte, length)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		password[i] = passwordCharacters[n.Int64()]
+	}
+	return string(password), nil
+}
@@ -0,0 +1,59 @@
+package util
+
+import (
+	"fmt"
+	"strconv"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// Attendee is an user of the Workshop
+type Attendee struct {
+	// ID identifies the user in the guides: the number of a generated user, the username of a listed user.
+	// It only depends on the entry of the user, so that editing the roster doesn't change the other users.
+	ID          string
+	Username    string
+	DisplayName string
+	Email       string
+	Group       string
+	ProjectSize string
+}
+
+// Roster returns the users of the Workshop: the users listed in the spec, followed by the
+// users generated from the user name prefix and the number of users
+func Roster(workshop *workshopv1.Workshop) []Attendee {
+	userDetails := workshop.Spec.UserDetails
+	roster := []Attendee{}
+	seen := map[string]bool{}
+
+	add := func(id string, user workshopv1.UserSpec) {
+		if user.Username == "" || seen[user.Username] {
+			return
+		}
+		seen[user.Username] = true
+
+		attendee := Attendee{
+			ID:          id,
+			Username:    user.Username,
+			DisplayName: user.DisplayName,
+			Email:       user.Email,
+			Group:       user.Group,
+			ProjectSize: user.ProjectSize,
+		}
+		if attendee.DisplayName == "" {
+			attendee.DisplayName = user.Username
+		}
+		if attendee.Email == "" {
+			attendee.Email = user.Username + "@none.com"
+		}
+		roster = append(roster, attendee)
+	}
+
+	for _, user := range userDetails.Users {
+		add(user.Username, user)
+	}
+	for id := 1; id <= userDetails.NumberOfUsers; id++ {
+		add(strconv.Itoa(id), workshopv1.UserSpec{Username: fmt.Sprint(userDetails.UserNamePrefix, id)})
+	}
+	return roster
+}
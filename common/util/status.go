@@ -5,11 +5,15 @@ var OperatorStatus = struct {
 	Scheduled    string
 	InProgress   string
 	Installed    string
+	Failed       string
+	Uninstalling string
 }{
 	NotScheduled: "NOT SCHEDULED",
 	Scheduled:    "SCHEDULED",
 	InProgress:   "IN PROGRESS",
 	Installed:    "INSTALLED",
+	Failed:       "FAILED",
+	Uninstalling: "UNINSTALLING",
 }
 
 func IsScheduled(enabled bool) string {
@@ -19,3 +23,13 @@ func IsScheduled(enabled bool) string {
 	}
 	return result
 }
+
+// IsInstalled returns true if the phase is the one of a component which has been installed,
+// even partially, and not uninstalled since
+func IsInstalled(phase string) bool {
+	switch phase {
+	case OperatorStatus.InProgress, OperatorStatus.Installed, OperatorStatus.Failed, OperatorStatus.Uninstalling:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package util
+
+import (
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// Labels identifying the Workshop which owns a resource
+const (
+	WorkshopUIDLabel       = "workshop.stakater.com/uid"
+	WorkshopNameLabel      = "workshop.stakater.com/name"
+	WorkshopNamespaceLabel = "workshop.stakater.com/namespace"
+)
+
+// OwnerLabels returns the labels identifying the resources owned by the Workshop
+func OwnerLabels(workshop *workshopv1.Workshop) map[string]string {
+	return map[string]string{
+		WorkshopUIDLabel:       string(workshop.UID),
+		WorkshopNameLabel:      workshop.Name,
+		WorkshopNamespaceLabel: workshop.Namespace,
+	}
+}
+
+// WithOwnerLabels returns a copy of labels with the ownership labels of the Workshop added.
+// The labels passed in are often shared and used as selectors, so they are never modified.
+func WithOwnerLabels(workshop *workshopv1.Workshop, labels map[string]string) map[string]string {
+	result := make(map[string]string, len(labels)+3)
+	for k, v := range labels {
+		result[k] = v
+	}
+	for k, v := range OwnerLabels(workshop) {
+		result[k] = v
+	}
+	return result
+}
+
+// IsOwnedBy returns true if the labels identify a resource owned by the Workshop
+func IsOwnedBy(workshop *workshopv1.Workshop, labels map[string]string) bool {
+	return labels[WorkshopUIDLabel] == string(workshop.UID)
+}
+
+// ScopedName returns the name of a resource scoped to the Workshop instance, see Workshop.ScopedName
+func ScopedName(workshop *workshopv1.Workshop, name string) string {
+	return workshop.ScopedName(name)
+}
+
+// ProjectName returns the name of the staging project of the user
+func ProjectName(workshop *workshopv1.Workshop, username string) string {
+	return workshop.ProjectName(username)
+}
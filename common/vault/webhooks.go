@@ -2,10 +2,12 @@ package vault
 
 import (
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
-// NewAgentInjectorWebHook create webhook
-func NewAgentInjectorWebHook(namespace string) []admissionregistration.MutatingWebhook {
+// NewAgentInjectorWebHook create webhook. It only mutates the pods of the namespaces matching
+// namespaceLabels, so that the injectors of several Workshops do not inject the same pods.
+func NewAgentInjectorWebHook(namespace string, namespaceLabels map[string]string) []admissionregistration.MutatingWebhook {
 	path := "/mutate"
 
 	return []admissionregistration.MutatingWebhook{
@@ -19,6 +21,9 @@ func NewAgentInjectorWebHook(namespace string) []admissionregistration.MutatingW
 					Path:      &path,
 				},
 			},
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: namespaceLabels,
+			},
 			SideEffects:             sideEffect(),
 			FailurePolicy:           failurePolicy(),
 			AdmissionReviewVersions: []string{"v1"},
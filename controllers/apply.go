@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// apply enforces the desired state of a resource of the Workshop with server-side apply.
+// The operator forces the ownership of its fields, so it must only be used for the resources of a single Workshop:
+// the resources shared between Workshops go through applyShared.
+func (r *WorkshopReconciler) apply(ctx context.Context, obj runtime.Object) error {
+	log := util.Logger(ctx)
+	if err := kubernetes.Apply(ctx, r, r.Scheme, obj); err != nil {
+		return err
+	}
+	if object, err := meta.Accessor(obj); err == nil {
+		log.V(util.LogDebug).Info("Applied", util.LogKind, obj.GetObjectKind().GroupVersionKind().Kind, util.LogName, object.GetName(), util.LogNamespace, object.GetNamespace())
+	}
+	return nil
+}
+
+// applyShared creates a resource shared between the Workshops of the cluster, such as an operator Subscription.
+// Only the Workshop which created it enforces its state afterwards, or adopts it once that Workshop is gone,
+// so that Workshops with different specs do not take it over from each other. A resource not created by a Workshop is left alone.
+func (r *WorkshopReconciler) applyShared(ctx context.Context, workshop *workshopv1.Workshop, obj runtime.Object) error {
+	log := util.Logger(ctx)
+	object, err := meta.Accessor(obj)
+	if err != nil {
+		return err
+	}
+
+	found := obj.DeepCopyObject()
+	if err := r.Get(ctx, types.NamespacedName{Name: object.GetName(), Namespace: object.GetNamespace()}, found); errors.IsNotFound(err) {
+		return r.apply(ctx, obj)
+	} else if err != nil {
+		return err
+	}
+	foundObject, err := meta.Accessor(found)
+	if err != nil {
+		return err
+	}
+
+	owner := foundObject.GetLabels()[util.WorkshopUIDLabel]
+	if owner == string(workshop.UID) {
+		return r.apply(ctx, obj)
+	}
+	if owner == "" {
+		log.V(util.LogDebug).Info("Not created by a Workshop, left alone", util.LogName, object.GetName(), util.LogNamespace, object.GetNamespace())
+		return nil
+	}
+	if exists, err := r.workshopExists(ctx, types.UID(owner)); err != nil || exists {
+		return err
+	}
+	log.Info("Adopted", util.LogName, object.GetName(), util.LogNamespace, object.GetNamespace())
+	return r.apply(ctx, obj)
+}
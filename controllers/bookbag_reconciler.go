@@ -2,19 +2,13 @@ package controllers
 
 import (
 	"context"
-	"fmt"
-	"reflect"
-	"strconv"
 
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/bookbag"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 
 	"github.com/stakater/workshop-operator/common/util"
-	appsv1 "k8s.io/api/apps/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -32,201 +26,161 @@ var bookbagConfigData = map[string]string{
 }
 
 // Reconciling Bookbag
-func (r *WorkshopReconciler) reconcileBookbag(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) reconcileBookbag(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
-	enabled := workshop.Spec.Infrastructure.Guide.Bookbag.Enabled
-	id := 1
-	for {
-		if id <= users && enabled {
-
-			if result, err := r.addUpdateBookbag(workshop, strconv.Itoa(id),
-				appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-				return result, err
-			}
-		} else {
-
-			bookbagName := fmt.Sprintf("bookbag-%d", id)
-
-			depFound := &appsv1.Deployment{}
-			depErr := r.Get(context.TODO(), types.NamespacedName{Name: bookbagName, Namespace: BOOKBAG_NAMESPACE_NAME}, depFound)
-
-			if depErr != nil && errors.IsNotFound(depErr) {
-				break
-			}
+	for _, user := range users {
+		result, err := r.addUpdateBookbag(ctx, workshop, user, appsHostnameSuffix, openshiftConsoleURL)
+		setProvisioned(workshop, "Bookbag", user.Username, err)
+		if err == nil {
+			bookbagHost := kubernetes.RouteHost(user.Username+"-bookbag", util.ScopedName(workshop, BOOKBAG_NAMESPACE_NAME), appsHostnameSuffix)
+			attendeeStatus(workshop, user.Username).BookbagURL = "http://" + bookbagHost
+		}
+		if util.IsRequeued(result, err) {
+			return result, err
 		}
-		id++
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addUpdateBookbag(workshop *workshopv1.Workshop, userID string,
+func (r *WorkshopReconciler) addUpdateBookbag(ctx context.Context, workshop *workshopv1.Workshop, user util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+	bookbagNamespaceName := util.ScopedName(workshop, BOOKBAG_NAMESPACE_NAME)
 
 	// Create Namespace
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, BOOKBAG_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, bookbagNamespaceName)
+	if err := r.apply(ctx, namespace); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	password, err := r.userPassword(ctx, workshop, user.Username)
+	if err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Project", namespace.Name)
 	}
 
-	bookbagName := fmt.Sprintf("user%s-bookbag", userID)
+	bookbagName := user.Username + "-bookbag"
 	labels := map[string]string{
 		"app":                       bookbagName,
 		"app.kubernetes.io/part-of": "bookbag",
 	}
 
 	// Create ConfigMap
-	envConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-env", BOOKBAG_NAMESPACE_NAME, labels, bookbagConfigData)
-	if err := r.Create(context.TODO(), envConfigMap); err != nil && !errors.IsAlreadyExists(err) {
+	envConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-env", bookbagNamespaceName, labels, bookbagConfigData)
+	if err := r.apply(ctx, envConfigMap); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s ConfigMap", envConfigMap.Name)
 	}
 
 	// Create ConfigMap
-	varConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-vars", BOOKBAG_NAMESPACE_NAME, labels, nil)
-	if err := r.Create(context.TODO(), varConfigMap); err != nil && !errors.IsAlreadyExists(err) {
+	varConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-vars", bookbagNamespaceName, labels, nil)
+	if err := r.apply(ctx, varConfigMap); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s ConfigMap", varConfigMap.Name)
 	}
 
 	// Create Service Account
-	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels)
-	if err := r.Create(context.TODO(), serviceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels)
+	if err := r.apply(ctx, serviceAccount); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service Account", serviceAccount.Name)
 	}
 
 	// Create Role Binding
-	roleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels,
+	roleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels,
 		serviceAccount.Name, BOOKBAG_ROLE_BINDING_NAME, BOOKBAG_ROLE_KIND_NAME)
-	if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, roleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", roleBinding.Name)
 	}
 
 	// Deploy/Update Bookbag
-	dep := bookbag.NewDeployment(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels, userID, appsHostnameSuffix, openshiftConsoleURL)
-	if err := r.Create(context.TODO(), dep); err != nil && !errors.IsAlreadyExists(err) {
+	dep := bookbag.NewDeployment(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels, user.ID, user.Username, password, appsHostnameSuffix, openshiftConsoleURL)
+	if err := r.apply(ctx, dep); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Deployment", dep.Name)
-	} else if errors.IsAlreadyExists(err) {
-		deploymentFound := &appsv1.Deployment{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: BOOKBAG_NAMESPACE_NAME}, deploymentFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			if !reflect.DeepEqual(dep.Spec.Template.Spec.Containers[0].Env, deploymentFound.Spec.Template.Spec.Containers[0].Env) {
-				// Update Guide
-				if err := r.Update(context.TODO(), dep); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s Deployment", dep.Name)
-			}
-		}
 	}
 
 	// Create Service
-	service := kubernetes.NewService(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels, []string{"http"}, []int32{BOOKBAG_PORT})
-	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
+	service := kubernetes.NewService(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels, []string{"http"}, []int32{BOOKBAG_PORT})
+	if err := r.apply(ctx, service); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service", service.Name)
 	}
 
 	// Create Route
-	route := kubernetes.NewRoute(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels, bookbagName, BOOKBAG_PORT)
-	if err := r.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+	route := r.newRoute(workshop, bookbagName, bookbagNamespaceName, labels, bookbagName, BOOKBAG_PORT, appsHostnameSuffix)
+	if err := r.apply(ctx, route); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Route", route.Name)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) deleteBookbag(workshop *workshopv1.Workshop, userID int, appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteBookbag(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee, appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	bookbagNamespaceName := util.ScopedName(workshop, BOOKBAG_NAMESPACE_NAME)
 
-	id := 1
-	for {
-		bookbagName := fmt.Sprintf("user%s-bookbag", strconv.Itoa(id))
+	for _, user := range users {
+		bookbagName := user.Username + "-bookbag"
 		labels := map[string]string{
 			"app":                       bookbagName,
 			"app.kubernetes.io/part-of": "bookbag",
 		}
 
-		route := kubernetes.NewRoute(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels, bookbagName, BOOKBAG_PORT)
+		route := r.newRoute(workshop, bookbagName, bookbagNamespaceName, labels, bookbagName, BOOKBAG_PORT, appsHostnameSuffix)
 		// Delete route
-		if err := r.Delete(context.TODO(), route); err != nil {
+		if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Route", route.Name)
+		log.Info("Deleted", util.LogKind, "Route", util.LogName, bookbagName)
 
-		service := kubernetes.NewService(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels, []string{"http"}, []int32{BOOKBAG_PORT})
+		service := kubernetes.NewService(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels, []string{"http"}, []int32{BOOKBAG_PORT})
 		// Delete Service
-		if err := r.Delete(context.TODO(), service); err != nil {
+		if err := r.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Service", service.Name)
+		log.Info("Deleted", util.LogKind, "Service", util.LogName, service.Name)
 
-		dep := bookbag.NewDeployment(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels, strconv.Itoa(userID), appsHostnameSuffix, openshiftConsoleURL)
+		dep := bookbag.NewDeployment(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels, user.ID, user.Username, "", appsHostnameSuffix, openshiftConsoleURL)
 		// Delete Deployment
-		if err := r.Delete(context.TODO(), dep); err != nil {
+		if err := r.Delete(ctx, dep); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Deployment", dep.Name)
+		log.Info("Deleted", util.LogKind, "Deployment", util.LogName, dep.Name)
 
-		serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels)
+		serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels)
 
-		roleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, bookbagName, BOOKBAG_NAMESPACE_NAME, labels,
+		roleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, bookbagName, bookbagNamespaceName, labels,
 			serviceAccount.Name, BOOKBAG_ROLE_BINDING_NAME, BOOKBAG_ROLE_KIND_NAME)
 		//Delete  Role Binding
-		if err := r.Delete(context.TODO(), roleBinding); err != nil {
+		if err := r.Delete(ctx, roleBinding); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s RoleBinding", roleBinding.Name)
+		log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, roleBinding.Name)
 
 		// Delete  Service Account
-		if err := r.Delete(context.TODO(), serviceAccount); err != nil {
+		if err := r.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Service Account", serviceAccount.Name)
+		log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, serviceAccount.Name)
 
-		varConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-vars", BOOKBAG_NAMESPACE_NAME, labels, nil)
+		varConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-vars", bookbagNamespaceName, labels, nil)
 		// Delete ConfigMap
-		if err := r.Delete(context.TODO(), varConfigMap); err != nil {
+		if err := r.Delete(ctx, varConfigMap); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s ConfigMap", varConfigMap.Name)
+		log.Info("Deleted", util.LogKind, "ConfigMap", util.LogName, varConfigMap.Name)
 
-		envConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-env", BOOKBAG_NAMESPACE_NAME, labels, bookbagConfigData)
+		envConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, bookbagName+"-env", bookbagNamespaceName, labels, bookbagConfigData)
 		// Delete ConfigMap
-		if err := r.Delete(context.TODO(), envConfigMap); err != nil {
+		if err := r.Delete(ctx, envConfigMap); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s ConfigMap", envConfigMap.Name)
-
-		depFound := &appsv1.Deployment{}
-		depErr := r.Get(context.TODO(), types.NamespacedName{Name: bookbagName, Namespace: BOOKBAG_NAMESPACE_NAME}, depFound)
-		if depErr != nil && errors.IsNotFound(depErr) {
-			break
-		}
-		id++
+		log.Info("Deleted", util.LogKind, "ConfigMap", util.LogName, envConfigMap.Name)
 	}
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, BOOKBAG_NAMESPACE_NAME)
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, bookbagNamespaceName)
 	// delete namespace
-	if err := r.Delete(context.TODO(), namespace); err != nil {
+	if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s namespace", namespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, namespace.Name)
 
 	return reconcile.Result{}, nil
 	//Success
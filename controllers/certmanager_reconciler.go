@@ -3,7 +3,6 @@ package controllers
 import (
 	"context"
 
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	certmanager "github.com/stakater/workshop-operator/common/certmanager"
 	"github.com/stakater/workshop-operator/common/kubernetes"
@@ -18,6 +17,9 @@ const (
 	CERT_MANAGER_PACKAGE_NAME                = "cert-manager-operator"
 	CERT_MANAGER_NAMESPACE_NAME              = "cert-manager"
 	CERT_MANAGER_CUSTOM_RESOURCE_NAME        = "cert-manager"
+	CERT_MANAGER_HELM_RELEASE_NAME           = "cert-manager"
+	CERT_MANAGER_HELM_REPOSITORY             = "https://charts.jetstack.io"
+	CERT_MANAGER_HELM_CHART                  = "cert-manager"
 )
 
 var certManagerLabels = map[string]string{
@@ -25,87 +27,109 @@ var certManagerLabels = map[string]string{
 }
 
 // Reconciling CertManager
-func (r *WorkshopReconciler) reconcileCertManager(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-	enabledCertManager := workshop.Spec.Infrastructure.CertManager.Enabled
+func (r *WorkshopReconciler) reconcileCertManager(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		return r.installHelmRelease(ctx, workshop, certManagerHelmRelease(workshop))
+	}
 
-	if enabledCertManager {
-		if result, err := r.addCertManager(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addCertManager(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addCertManager(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addCertManager(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	channel := workshop.Spec.Infrastructure.CertManager.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.CertManager.OperatorHub.ClusterServiceVersion
+	operatorHub := workshop.Spec.Infrastructure.CertManager.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, CERT_MANAGER_SUBSCRIPTION_NAMESPACE_NAME)
 
 	// Create CertManager Subscription
-	CertManagerSubscription := kubernetes.NewCertifiedSubscription(workshop, r.Scheme, CERT_MANAGER_SUBSCRIPTION_NAME, CERT_MANAGER_SUBSCRIPTION_NAMESPACE_NAME,
-		CERT_MANAGER_PACKAGE_NAME, channel, clusterServiceVersion)
-	if err := r.Create(context.TODO(), CertManagerSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	CertManagerSubscription := kubernetes.NewCertifiedSubscription(workshop, r.Scheme, CERT_MANAGER_SUBSCRIPTION_NAME, subscriptionNamespace,
+		CERT_MANAGER_PACKAGE_NAME, operatorHub)
+	if err := r.applyShared(ctx, workshop, CertManagerSubscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", CertManagerSubscription.Name)
 	}
 
 	// Approve the installation
-	if err := r.ApproveInstallPlan(clusterServiceVersion, CERT_MANAGER_SUBSCRIPTION_NAME, CERT_MANAGER_SUBSCRIPTION_NAMESPACE_NAME); err != nil {
-		log.Infof("Waiting for Subscription to create InstallPlan for %s", CertManagerSubscription.Name)
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "CertManager", operatorHub.ClusterServiceVersion, CERT_MANAGER_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	// Create CertManager Namespace
 	namespace := kubernetes.NewNamespace(workshop, r.Scheme, CERT_MANAGER_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Namespace", namespace.Name)
+		log.Info("Created", util.LogKind, "Namespace", util.LogName, namespace.Name)
 	}
 
 	// Create CertManager CustomResource
 	customresource := certmanager.NewCustomResource(workshop, r.Scheme, CERT_MANAGER_CUSTOM_RESOURCE_NAME, namespace.Name, certManagerLabels)
-	if err := r.Create(context.TODO(), customresource); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, customresource); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource", customresource.Name)
+		log.Info("Created", util.LogKind, "CertManager", util.LogName, customresource.Name)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) deleteCertManager(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteCertManager(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	// The CertManager installation is shared by the Workshops of the cluster
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "CertManager")
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if isUsed {
+		// Another Workshop takes over the release on Kubernetes
+		return r.handOverHelmReleases(ctx, workshop, "CertManager", certManagerHelmRelease(workshop))
+	}
 
-	channel := workshop.Spec.Infrastructure.CertManager.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.CertManager.OperatorHub.ClusterServiceVersion
+	if !r.isOpenShift() {
+		return r.uninstallHelmRelease(ctx, workshop, certManagerHelmRelease(workshop))
+	}
+
+	operatorHub := workshop.Spec.Infrastructure.CertManager.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, CERT_MANAGER_SUBSCRIPTION_NAMESPACE_NAME)
 
 	namespace := kubernetes.NewNamespace(workshop, r.Scheme, CERT_MANAGER_NAMESPACE_NAME)
 
 	// Delete CertManager CustomResource
 	customresource := certmanager.NewCustomResource(workshop, r.Scheme, CERT_MANAGER_CUSTOM_RESOURCE_NAME, namespace.Name, certManagerLabels)
-	if err := r.Delete(context.TODO(), customresource); err != nil {
+	if err := r.Delete(ctx, customresource); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Custom Resource", customresource.Name)
+	log.Info("Deleted", util.LogKind, "CertManager", util.LogName, customresource.Name)
 
 	// Delete CertManager Namespace
-	if err := r.Delete(context.TODO(), namespace); err != nil {
+	if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  Namespace", namespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, namespace.Name)
 
 	// Delete certManager Subscription
-	CertManagerSubscription := kubernetes.NewCertifiedSubscription(workshop, r.Scheme, CERT_MANAGER_SUBSCRIPTION_NAME, CERT_MANAGER_SUBSCRIPTION_NAMESPACE_NAME,
-		CERT_MANAGER_PACKAGE_NAME, channel, clusterServiceVersion)
-	if err := r.Delete(context.TODO(), CertManagerSubscription); err != nil {
+	CertManagerSubscription := kubernetes.NewCertifiedSubscription(workshop, r.Scheme, CERT_MANAGER_SUBSCRIPTION_NAME, subscriptionNamespace,
+		CERT_MANAGER_PACKAGE_NAME, operatorHub)
+	if err := r.Delete(ctx, CertManagerSubscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", CertManagerSubscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, CertManagerSubscription.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// certManagerHelmRelease returns the release of cert-manager standing in for its operator on Kubernetes
+func certManagerHelmRelease(workshop *workshopv1.Workshop) helmRelease {
+	return newHelmRelease(CERT_MANAGER_HELM_RELEASE_NAME, CERT_MANAGER_NAMESPACE_NAME, workshop.Spec.Infrastructure.CertManager.Helm,
+		CERT_MANAGER_HELM_REPOSITORY, CERT_MANAGER_HELM_CHART, map[string]interface{}{
+			"installCRDs": true,
+		})
+}
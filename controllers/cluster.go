@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+const (
+	// CLUSTER_CONFIG_NAME is the name of the cluster-wide configuration resources
+	CLUSTER_CONFIG_NAME = "cluster"
+	// CONSOLE_HOSTNAME_PREFIX is the host name of the console under the apps domain, by default
+	CONSOLE_HOSTNAME_PREFIX = "console-openshift-console."
+)
+
+// clusterURLs returns the apps domain and the console URL from the Workshop spec, or else
+// from the Ingress and Console configurations of the cluster
+func (r *WorkshopReconciler) clusterURLs(ctx context.Context, workshop *workshopv1.Workshop) (string, string, error) {
+	log := util.Logger(ctx)
+	appsDomain := workshop.Spec.Cluster.AppsDomain
+	if appsDomain == "" && !r.isOpenShift() {
+		return "", "", fmt.Errorf("spec.cluster.appsDomain is required on %s", r.Platform)
+	}
+	if appsDomain == "" {
+		ingress := &configv1.Ingress{}
+		if err := r.Get(ctx, types.NamespacedName{Name: CLUSTER_CONFIG_NAME}, ingress); err != nil {
+			return "", "", fmt.Errorf("failed to read the apps domain from the %s Ingress config, set spec.cluster.appsDomain: %w",
+				CLUSTER_CONFIG_NAME, err)
+		}
+		appsDomain = ingress.Spec.Domain
+		if appsDomain == "" {
+			return "", "", fmt.Errorf("the %s Ingress config has no domain, set spec.cluster.appsDomain", CLUSTER_CONFIG_NAME)
+		}
+	}
+	log.V(util.LogDebug).Info("Apps domain", "appsDomain", appsDomain)
+
+	// Kubernetes has no console, unless the spec gives one
+	consoleURL := workshop.Spec.Cluster.ConsoleURL
+	if consoleURL == "" && r.isOpenShift() {
+		console := &configv1.Console{}
+		if err := r.Get(ctx, types.NamespacedName{Name: CLUSTER_CONFIG_NAME}, console); err == nil && console.Status.ConsoleURL != "" {
+			consoleURL = console.Status.ConsoleURL
+		} else {
+			// The console is served under the apps domain unless its hostname is customized
+			consoleURL = "https://" + CONSOLE_HOSTNAME_PREFIX + appsDomain
+		}
+	}
+	log.V(util.LogDebug).Info("Console URL", "consoleURL", consoleURL)
+
+	return appsDomain, consoleURL, nil
+}
+
+// environment returns the cluster information shared by the components of the Workshop.
+// The conditions report a missing apps domain as Degraded.
+func (r *WorkshopReconciler) environment(ctx context.Context, workshop *workshopv1.Workshop, status *statusRecorder) (*Environment, error) {
+	log := util.Logger(ctx)
+	appsHostnameSuffix, openshiftConsoleURL, err := r.clusterURLs(ctx, workshop)
+	if err != nil {
+		log.Error(err, "Failed to get the apps domain")
+		status.reason = ReasonAppsDomainUnavailable
+		status.message = err.Error()
+		return nil, err
+	}
+
+	return &Environment{
+		Users:               util.Roster(workshop),
+		AppsHostnameSuffix:  appsHostnameSuffix,
+		OpenshiftConsoleURL: openshiftConsoleURL,
+	}, nil
+}
+
+// teardownEnvironment returns the environment of the Workshop for its teardown, which deletes the resources by name:
+// without the apps domain the teardown goes on rather than keeping the Workshop forever.
+func (r *WorkshopReconciler) teardownEnvironment(ctx context.Context, workshop *workshopv1.Workshop) *Environment {
+	appsHostnameSuffix, openshiftConsoleURL, err := r.clusterURLs(ctx, workshop)
+	if err != nil {
+		util.Logger(ctx).Info("Tearing down without the apps domain", "reason", err.Error())
+	}
+	return &Environment{
+		Users:               util.Roster(workshop),
+		AppsHostnameSuffix:  appsHostnameSuffix,
+		OpenshiftConsoleURL: openshiftConsoleURL,
+	}
+}
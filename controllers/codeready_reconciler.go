@@ -3,7 +3,6 @@ package controllers
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -11,17 +10,16 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
-	"time"
 
 	_ "k8s.io/api/rbac/v1"
 
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/codeready"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/util"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/yaml"
 )
@@ -42,141 +40,154 @@ const (
 	CHE_CLUSTER_ROLE_BINDING_NAME       = "che"
 	CHE_SERVICEACCOUNT_NAME             = "che"
 	CHE_CODE_FLAVOR_NAME                = "codeready"
+	CODEREADY_HELM_RELEASE_NAME         = "che-operator"
 )
 
 // Reconciling CodeReadyWorkspace
-func (r *WorkshopReconciler) reconcileCodeReadyWorkspace(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) reconcileCodeReadyWorkspace(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
-
-	enabled := workshop.Spec.Infrastructure.CodeReadyWorkspace.Enabled
-
-	if enabled {
-		if result, err := r.addCodeReadyWorkspace(workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addCodeReadyWorkspace(ctx, workshop, users, appsHostnameSuffix); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) addCodeReadyWorkspace(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string) (reconcile.Result, error) {
+	codeReadyNamespaceName := util.ScopedName(workshop, CODEREADY_NAMESPACE_NAME)
 
-	channel := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub.ClusterServiceVersion
+	// The operator is installed in the CodeReady namespace, with its own OperatorGroup
+	operatorHub := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub
 
 	// Create Project
-	codeReadyWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, CODEREADY_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), codeReadyWorkspacesNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	codeReadyWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, codeReadyNamespaceName)
+	if err := r.apply(ctx, codeReadyWorkspacesNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Project", codeReadyWorkspacesNamespace.Name)
 	}
 
-	// Create OperatorGroup
-	codeReadyWorkspacesOperatorGroup := kubernetes.NewOperatorGroup(workshop, r.Scheme, CODEREADY_OPERATORGROUP_NAME, CODEREADY_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), codeReadyWorkspacesOperatorGroup); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s OperatorGroup", codeReadyWorkspacesOperatorGroup.Name)
-	}
+	if r.isOpenShift() {
+		// Create OperatorGroup
+		codeReadyWorkspacesOperatorGroup := kubernetes.NewOperatorGroup(workshop, r.Scheme, CODEREADY_OPERATORGROUP_NAME, codeReadyNamespaceName)
+		if err := r.apply(ctx, codeReadyWorkspacesOperatorGroup); err != nil {
+			return reconcile.Result{}, err
+		}
 
-	// Create Subscription
-	codeReadyWorkspacesSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, CODEREADY_SUBSCRIPTION_NAME, CODEREADY_NAMESPACE_NAME,
-		CODEREADY_SUBSCRIPTION_PACKAGE_NAME, channel, clusterServiceVersion)
-	if err := r.Create(context.TODO(), codeReadyWorkspacesSubscription); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", codeReadyWorkspacesSubscription.Name)
-	}
+		// Create Subscription
+		codeReadyWorkspacesSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, CODEREADY_SUBSCRIPTION_NAME, codeReadyNamespaceName,
+			CODEREADY_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+		if err := r.apply(ctx, codeReadyWorkspacesSubscription); err != nil {
+			return reconcile.Result{}, err
+		}
 
-	// Approve the Installation
-	if err := r.ApproveInstallPlan(clusterServiceVersion, CODEREADY_SUBSCRIPTION_NAME, CODEREADY_NAMESPACE_NAME); err != nil {
-		log.Warnf("Waiting for Subscription to create InstallPlan for %s", "codeready-workspaces")
-		return reconcile.Result{Requeue: true}, nil
-	}
+		// Approve the Installation
+		if result, err := r.ApproveInstallPlan(ctx, workshop, "CodeReadyWorkspace", operatorHub.ClusterServiceVersion, CODEREADY_SUBSCRIPTION_NAME, codeReadyNamespaceName); util.IsRequeued(result, err) {
+			return result, err
+		}
 
-	// Wait for CodeReadyWorkspace Operator to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(CODEREADY_OPERATOR_DEPLOYMENT_NAME, CODEREADY_NAMESPACE_NAME) {
-		return reconcile.Result{Requeue: true}, nil
+		// Wait for CodeReadyWorkspace Operator to be running
+		if result, err := kubernetes.CheckDeploymentReady(ctx, r, CODEREADY_OPERATOR_DEPLOYMENT_NAME, codeReadyNamespaceName); util.IsRequeued(result, err) {
+			return result, err
+		}
+	} else {
+		// The Che operator is installed with the chart of the spec, Helm waits for it to be running
+		if result, err := r.installHelmRelease(ctx, workshop, codeReadyHelmRelease(workshop)); util.IsRequeued(result, err) {
+			return result, err
+		}
 	}
 
-	codeReadyWorkspacesCustomResource := codeready.NewCustomResource(workshop, r.Scheme, CHE_CUSTOM_RESOURCE_NAME, CODEREADY_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), codeReadyWorkspacesCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+	codeReadyWorkspacesCustomResource := codeready.NewCustomResource(workshop, r.Scheme, CHE_CUSTOM_RESOURCE_NAME, codeReadyNamespaceName)
+	if !r.isOpenShift() {
+		// Che exposes itself and its Keycloak with Ingresses under the apps domain, and has no OpenShift OAuth to log in with
+		codeReadyWorkspacesCustomResource.Spec.K8s.IngressDomain = appsHostnameSuffix
+		codeReadyWorkspacesCustomResource.Spec.Auth.OpenShiftoAuth = false
+	}
+	if err := r.apply(ctx, codeReadyWorkspacesCustomResource); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Custom Resource", codeReadyWorkspacesCustomResource.Name)
 	}
 
 	// Wait for CodeReadyWorkspace to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(CODEREADY_DEPLOYMENT_NAME, CODEREADY_NAMESPACE_NAME) {
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 1}, nil
+	if result, err := kubernetes.CheckDeploymentReady(ctx, r, CODEREADY_DEPLOYMENT_NAME, codeReadyNamespaceName); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	// Initialize Workspaces from devfile
-	devfile, result, err := getDevFile(workshop)
+	devfile, result, err := r.getDevFile(ctx, workshop)
 	if err != nil {
 		return result, err
 	}
 
 	// Users and Workspaces
-	if !workshop.Spec.Infrastructure.CodeReadyWorkspace.OpenshiftOAuth {
-		masterAccessToken, result, err := getKeycloakAdminToken(workshop, CODEREADY_NAMESPACE_NAME, appsHostnameSuffix)
+	if !r.codeReadyOpenShiftOAuth(workshop) {
+		masterAccessToken, result, err := getKeycloakAdminToken(ctx, workshop, codeReadyNamespaceName, appsHostnameSuffix)
 		if err != nil {
 			return result, err
 		}
 
 		// Create Che Cluster Role
 		cheClusterRole :=
-			kubernetes.NewClusterRole(workshop, r.Scheme, CHE_CLUSTER_ROLE_NAME, CODEREADY_NAMESPACE_NAME, codeReadyLabels, kubernetes.CheRules())
-		if err := r.Create(context.TODO(), cheClusterRole); err != nil && !errors.IsAlreadyExists(err) {
+			kubernetes.NewClusterRole(workshop, r.Scheme, util.ScopedName(workshop, CHE_CLUSTER_ROLE_NAME), codeReadyNamespaceName, codeReadyLabels, kubernetes.CheRules())
+		if err := r.apply(ctx, cheClusterRole); err != nil {
 			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Cluster Role", cheClusterRole.Name)
 		}
 
 		//Create Che Cluster Role Binding
-		cheClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, CHE_CLUSTER_ROLE_BINDING_NAME, CODEREADY_NAMESPACE_NAME, codeReadyLabels, CHE_SERVICEACCOUNT_NAME, cheClusterRole.Name, KIND_CLUSTER_ROLE)
-		if err := r.Create(context.TODO(), cheClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		cheClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, CHE_CLUSTER_ROLE_BINDING_NAME), codeReadyNamespaceName, codeReadyLabels, CHE_SERVICEACCOUNT_NAME, cheClusterRole.Name, KIND_CLUSTER_ROLE)
+		if err := r.apply(ctx, cheClusterRoleBinding); err != nil {
 			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s Cluster Role Binding", cheClusterRoleBinding.Name)
 		}
 
-		for id := 1; id <= users; id++ {
-			username := fmt.Sprintf("user%d", id)
+		if err := provisionUsers(ctx, workshop, "CodeReadyWorkspace", users, true, func(ctx context.Context, user util.Attendee, attendee *workshopv1.AttendeeStatus) error {
+			username := user.Username
+			password, err := r.userPassword(ctx, workshop, username)
+			if err != nil {
+				return err
+			}
 
-			if result, err := createUser(workshop, username, CHE_CODE_FLAVOR_NAME, CODEREADY_NAMESPACE_NAME, appsHostnameSuffix, masterAccessToken); err != nil {
-				return result, err
+			if _, err := createUser(ctx, workshop, username, password, user.Email, CHE_CODE_FLAVOR_NAME, codeReadyNamespaceName, appsHostnameSuffix, masterAccessToken); err != nil {
+				return err
 			}
 
-			userAccessToken, result, err := getUserToken(workshop, username, CHE_CODE_FLAVOR_NAME, CODEREADY_NAMESPACE_NAME, appsHostnameSuffix)
+			userAccessToken, _, err := getUserToken(ctx, workshop, username, password, CHE_CODE_FLAVOR_NAME, codeReadyNamespaceName, appsHostnameSuffix)
 			if err != nil {
-				return result, err
+				return err
 			}
 
-			if result, err := initWorkspace(workshop, username, CHE_CODE_FLAVOR_NAME, CODEREADY_NAMESPACE_NAME, userAccessToken, devfile, appsHostnameSuffix); err != nil {
-				return result, err
+			workspace, _, err := initWorkspace(ctx, workshop, username, attendee.WorkspaceID, CHE_CODE_FLAVOR_NAME, codeReadyNamespaceName, userAccessToken, devfile, appsHostnameSuffix)
+			if err != nil {
+				return err
 			}
-
+			attendee.WorkspaceID, attendee.WorkspaceState = workspace.ID, workspace.Status
+			return nil
+		}); err != nil {
+			return reconcile.Result{}, err
 		}
 	} else {
-		for id := 1; id <= users; id++ {
-			username := fmt.Sprintf("user%d", id)
+		if err := provisionUsers(ctx, workshop, "CodeReadyWorkspace", users, true, func(ctx context.Context, user util.Attendee, attendee *workshopv1.AttendeeStatus) error {
+			username := user.Username
+			password, err := r.userPassword(ctx, workshop, username)
+			if err != nil {
+				return err
+			}
 
-			userAccessToken, result, err := getOAuthUserToken(workshop, username, CHE_CODE_FLAVOR_NAME, CODEREADY_NAMESPACE_NAME, appsHostnameSuffix)
+			userAccessToken, _, err := getOAuthUserToken(ctx, workshop, username, password, CHE_CODE_FLAVOR_NAME, codeReadyNamespaceName, appsHostnameSuffix)
 			if err != nil {
-				return result, err
+				return err
 			}
 
-			if result, err := updateUserEmail(workshop, username, CHE_CODE_FLAVOR_NAME, CODEREADY_NAMESPACE_NAME, appsHostnameSuffix); err != nil {
-				return result, err
+			if _, err := updateUserEmail(ctx, workshop, username, user.Email, CHE_CODE_FLAVOR_NAME, codeReadyNamespaceName, appsHostnameSuffix); err != nil {
+				return err
 			}
 
-			if result, err := initWorkspace(workshop, username, CHE_CODE_FLAVOR_NAME, CODEREADY_NAMESPACE_NAME, userAccessToken, devfile, appsHostnameSuffix); err != nil {
-				return result, err
+			workspace, _, err := initWorkspace(ctx, workshop, username, attendee.WorkspaceID, CHE_CODE_FLAVOR_NAME, codeReadyNamespaceName, userAccessToken, devfile, appsHostnameSuffix)
+			if err != nil {
+				return err
 			}
+			attendee.WorkspaceID, attendee.WorkspaceState = workspace.ID, workspace.Status
+			return nil
+		}); err != nil {
+			return reconcile.Result{}, err
 		}
 	}
 
@@ -185,21 +196,14 @@ func (r *WorkshopReconciler) addCodeReadyWorkspace(workshop *workshopv1.Workshop
 }
 
 // Get DevFile
-func getDevFile(workshop *workshopv1.Workshop) (string, reconcile.Result, error) {
+func (r *WorkshopReconciler) getDevFile(ctx context.Context, workshop *workshopv1.Workshop) (string, reconcile.Result, error) {
+	log := util.Logger(ctx)
 
 	var (
 		httpResponse *http.Response
 		httpRequest  *http.Request
 		devfile      string
-		client       = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		client       = newHTTPClient(workshop, httpTargetGitHub)
 	)
 
 	gitURL, err := url.Parse(workshop.Spec.Source.GitURL)
@@ -207,66 +211,63 @@ func getDevFile(workshop *workshopv1.Workshop) (string, reconcile.Result, error)
 		return "", reconcile.Result{}, err
 	}
 	devfileRawURL := fmt.Sprintf("https://raw.githubusercontent.com%s/%s/devfile.yaml", gitURL.Path, workshop.Spec.Source.GitBranch)
-	httpRequest, err = http.NewRequest("GET", devfileRawURL, nil)
+	httpRequest, err = http.NewRequestWithContext(ctx, "GET", devfileRawURL, nil)
 	if err != nil {
 		log.Error(err, "Failed http GET Request")
 	}
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
-		log.Errorf("Error when getting Devfile from %s", devfileRawURL)
+		log.Error(err, "Failed to get the devfile", "url", devfileRawURL)
+		r.warningEvent(workshop, EventDevfileFetchFailed, "CodeReadyWorkspace", "", "Failed to get the devfile from %s: %s", devfileRawURL, err)
 		return "", reconcile.Result{}, err
 	}
+	defer httpResponse.Body.Close()
 
 	if httpResponse.StatusCode == http.StatusOK {
 		bodyBytes, err := ioutil.ReadAll(httpResponse.Body)
 		if err != nil {
-			log.Errorf("Error when reading %s", devfileRawURL)
+			log.Error(err, "Failed to read the devfile", "url", devfileRawURL)
 			return "", reconcile.Result{}, err
 		}
 
 		bodyJSON, err := yaml.YAMLToJSON(bodyBytes)
 		if err != nil {
-			log.Errorf("Error to converting %s to JSON", devfileRawURL)
+			log.Error(err, "Failed to convert the devfile to JSON", "url", devfileRawURL)
+			r.warningEvent(workshop, EventDevfileFetchFailed, "CodeReadyWorkspace", "", "Invalid devfile %s: %s", devfileRawURL, err)
 			return "", reconcile.Result{}, err
 		}
 		devfile = string(bodyJSON)
 	} else {
-		log.Errorf("Error (%v) when getting Devfile from %s", httpResponse.StatusCode, devfileRawURL)
-		return "", reconcile.Result{}, err
+		log.Info("Failed to get the devfile", "url", devfileRawURL, "status", httpResponse.StatusCode)
+		r.warningEvent(workshop, EventDevfileFetchFailed, "CodeReadyWorkspace", "", "Getting the devfile from %s returned %s", devfileRawURL, httpResponse.Status)
+		return "", reconcile.Result{}, fmt.Errorf("getting the devfile from %s returned %s", devfileRawURL, httpResponse.Status)
 	}
 
 	return devfile, reconcile.Result{}, nil
 }
 
 // Create user
-func createUser(workshop *workshopv1.Workshop, username string, codeflavor string,
+func createUser(ctx context.Context, workshop *workshopv1.Workshop, username string, password string, email string, codeflavor string,
 	namespace string, appsHostnameSuffix string, masterToken string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	var (
-		openshiftUserPassword = workshop.Spec.UserDetails.DefaultPassword
+		openshiftUserPassword = password
 		body                  []byte
 		err                   error
 		httpResponse          *http.Response
 		httpRequest           *http.Request
 		keycloakCheUserURL    = "https://keycloak-" + namespace + "." + appsHostnameSuffix + "/auth/admin/realms/" + codeflavor + "/users"
 
-		client = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		client = newHTTPClient(workshop, httpTargetKeycloak)
 	)
 
-	body, err = json.Marshal(codeready.NewUser(username, openshiftUserPassword))
+	body, err = json.Marshal(codeready.NewUser(username, openshiftUserPassword, email))
 	if err != nil {
 		return reconcile.Result{}, err
 	}
 
-	httpRequest, err = http.NewRequest("POST", keycloakCheUserURL, bytes.NewBuffer(body))
+	httpRequest, err = http.NewRequestWithContext(ctx, "POST", keycloakCheUserURL, bytes.NewBuffer(body))
 	if err != nil {
 		log.Error(err, "Failed http POST Request")
 	}
@@ -277,33 +278,32 @@ func createUser(workshop *workshopv1.Workshop, username string, codeflavor strin
 	if err != nil {
 		return reconcile.Result{}, err
 	}
-	if httpResponse.StatusCode == http.StatusCreated {
-		log.Infof("Created %s in CodeReady Workspaces", username)
+	defer httpResponse.Body.Close()
+	switch httpResponse.StatusCode {
+	case http.StatusCreated:
+		log.Info("Created", util.LogKind, "KeycloakUser")
+	case http.StatusConflict:
+		// Created by a previous reconcile
+	default:
+		return reconcile.Result{}, unexpectedStatusError("creating the Keycloak user", httpResponse)
 	}
 
 	return reconcile.Result{}, nil
 }
 
 // Get user token
-func getUserToken(workshop *workshopv1.Workshop, username string, codeflavor string, namespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
+func getUserToken(ctx context.Context, workshop *workshopv1.Workshop, username string, password string, codeflavor string, namespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	var (
-		openshiftUserPassword = workshop.Spec.UserDetails.DefaultPassword
+		openshiftUserPassword = password
 		err                   error
 		httpResponse          *http.Response
 		httpRequest           *http.Request
 		keycloakCheTokenURL   = "https://keycloak-" + namespace + "." + appsHostnameSuffix + "/auth/realms/" + codeflavor + "/protocol/openid-connect/token"
 
 		userToken util.Token
-		client    = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		client    = newHTTPClient(workshop, httpTargetKeycloak)
 	)
 
 	// Get User Access Token
@@ -313,14 +313,14 @@ func getUserToken(workshop *workshopv1.Workshop, username string, codeflavor str
 	data.Set("client_id", codeflavor+"-public")
 	data.Set("grant_type", "password")
 
-	httpRequest, err = http.NewRequest("POST", keycloakCheTokenURL, strings.NewReader(data.Encode()))
+	httpRequest, err = http.NewRequestWithContext(ctx, "POST", keycloakCheTokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		log.Error(err, "Failed http POST Request")
 	}
 	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
-		log.Errorf("Error to get the user access  token from %s keycloak (%v)", codeflavor, err)
+		log.Error(err, "Failed to get the user access token from Keycloak")
 		return "", reconcile.Result{}, err
 	}
 
@@ -328,22 +328,22 @@ func getUserToken(workshop *workshopv1.Workshop, username string, codeflavor str
 
 	if httpResponse.StatusCode == http.StatusOK {
 		if err := json.NewDecoder(httpResponse.Body).Decode(&userToken); err != nil {
-			log.Errorf("Error to get the user access  token from %s keycloak (%v)", codeflavor, err)
+			log.Error(err, "Failed to get the user access token from Keycloak")
 			return "", reconcile.Result{}, err
 		}
 	} else {
-		log.Errorf("Error to get the user access token from %s keycloak (%d)", codeflavor, httpResponse.StatusCode)
-		return "", reconcile.Result{}, err
+		return "", reconcile.Result{}, unexpectedStatusError("getting the user access token from Keycloak", httpResponse)
 	}
 
 	return userToken.AccessToken, reconcile.Result{}, nil
 }
 
 // Get oauthUserToken
-func getOAuthUserToken(workshop *workshopv1.Workshop, username string,
+func getOAuthUserToken(ctx context.Context, workshop *workshopv1.Workshop, username string, password string,
 	codeflavor string, namespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 	var (
-		openshiftUserPassword = workshop.Spec.UserDetails.DefaultPassword
+		openshiftUserPassword = password
 		err                   error
 		httpResponse          *http.Response
 		httpRequest           *http.Request
@@ -351,19 +351,11 @@ func getOAuthUserToken(workshop *workshopv1.Workshop, username string,
 		oauthOpenShiftURL     = "https://oauth-openshift." + appsHostnameSuffix + "/oauth/authorize?client_id=openshift-challenging-client&response_type=token"
 
 		userToken util.Token
-		client    = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		client    = newHTTPClient(workshop, httpTargetOAuth)
 	)
 
 	// GET TOKEN
-	httpRequest, err = http.NewRequest("GET", oauthOpenShiftURL, nil)
+	httpRequest, err = http.NewRequestWithContext(ctx, "GET", oauthOpenShiftURL, nil)
 	if err != nil {
 		log.Error(err, "Failed http GET Request")
 	}
@@ -372,9 +364,10 @@ func getOAuthUserToken(workshop *workshopv1.Workshop, username string,
 
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
-		log.Errorf("Error when getting Token Exchange for %s: %v", username, err)
+		log.Error(err, "Failed to get the OpenShift OAuth token")
 		return "", reconcile.Result{}, err
 	}
+	defer httpResponse.Body.Close()
 
 	if httpResponse.StatusCode == http.StatusFound {
 		locationURL, err := url.Parse(httpResponse.Header.Get("Location"))
@@ -384,6 +377,9 @@ func getOAuthUserToken(workshop *workshopv1.Workshop, username string,
 
 		regex := regexp.MustCompile("access_token=([^&]+)")
 		subjectToken := regex.FindStringSubmatch(locationURL.Fragment)
+		if len(subjectToken) < 2 {
+			return "", reconcile.Result{}, fmt.Errorf("the OpenShift OAuth redirect has no access token")
+		}
 
 		// Get User Access Token
 		data := url.Values{}
@@ -393,36 +389,35 @@ func getOAuthUserToken(workshop *workshopv1.Workshop, username string,
 		data.Set("subject_issuer", "openshift-v4")
 		data.Set("subject_token_type", "urn:ietf:params:oauth:token-type:access_token")
 
-		httpRequest, err = http.NewRequest("POST", keycloakCheTokenURL, strings.NewReader(data.Encode()))
+		httpRequest, err = http.NewRequestWithContext(ctx, "POST", keycloakCheTokenURL, strings.NewReader(data.Encode()))
 		if err != nil {
 			log.Error(err, "Failed http POST Request")
 		}
 		httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		httpResponse, err = client.Do(httpRequest)
 		if err != nil {
-			log.Errorf("Error to get the oauth user access  token from %s keycloak (%v)", codeflavor, err)
+			log.Error(err, "Failed to exchange the OpenShift OAuth token in Keycloak")
 			return "", reconcile.Result{}, err
 		}
 		defer httpResponse.Body.Close()
 		if httpResponse.StatusCode == http.StatusOK {
 			if err := json.NewDecoder(httpResponse.Body).Decode(&userToken); err != nil {
-				log.Errorf("Error to get the oauth user access  token from %s keycloak (%v)", codeflavor, err)
+				log.Error(err, "Failed to exchange the OpenShift OAuth token in Keycloak")
 				return "", reconcile.Result{}, err
 			}
 		} else {
-			log.Errorf("Error to get the oauth user access token from %s keycloak (%d)", codeflavor, httpResponse.StatusCode)
-			return "", reconcile.Result{}, err
+			return "", reconcile.Result{}, unexpectedStatusError("exchanging the OpenShift OAuth token in Keycloak", httpResponse)
 		}
 	} else {
-		log.Errorf("Error when getting Token Exchange for %s (%d)", username, httpResponse.StatusCode)
-		return "", reconcile.Result{}, err
+		return "", reconcile.Result{}, unexpectedStatusError("getting the OpenShift OAuth token", httpResponse)
 	}
 
 	return userToken.AccessToken, reconcile.Result{}, nil
 }
 
 // Get KeyCloak Admin Token
-func getKeycloakAdminToken(workshop *workshopv1.Workshop, namespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
+func getKeycloakAdminToken(ctx context.Context, workshop *workshopv1.Workshop, namespace string, appsHostnameSuffix string) (string, reconcile.Result, error) {
+	log := util.Logger(ctx)
 	var (
 		err                 error
 		httpResponse        *http.Response
@@ -430,19 +425,11 @@ func getKeycloakAdminToken(workshop *workshopv1.Workshop, namespace string, apps
 		keycloakCheTokenURL = "https://keycloak-" + namespace + "." + appsHostnameSuffix + "/auth/realms/master/protocol/openid-connect/token"
 
 		masterToken util.Token
-		client      = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		client      = newHTTPClient(workshop, httpTargetKeycloak)
 	)
 
 	// GET TOKEN
-	httpRequest, err = http.NewRequest("POST", keycloakCheTokenURL, strings.NewReader("username=admin&password=admin&grant_type=password&client_id=admin-cli"))
+	httpRequest, err = http.NewRequestWithContext(ctx, "POST", keycloakCheTokenURL, strings.NewReader("username=admin&password=admin&grant_type=password&client_id=admin-cli"))
 	if err != nil {
 		log.Error(err, "Failed http POST Request")
 	}
@@ -452,18 +439,20 @@ func getKeycloakAdminToken(workshop *workshopv1.Workshop, namespace string, apps
 		return "", reconcile.Result{}, err
 	}
 	defer httpResponse.Body.Close()
-	if httpResponse.StatusCode == http.StatusOK {
-		if err := json.NewDecoder(httpResponse.Body).Decode(&masterToken); err != nil {
-			return "", reconcile.Result{}, err
-		}
+	if httpResponse.StatusCode != http.StatusOK {
+		return "", reconcile.Result{}, unexpectedStatusError("getting the master token from Keycloak", httpResponse)
+	}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&masterToken); err != nil {
+		return "", reconcile.Result{}, err
 	}
 
 	return masterToken.AccessToken, reconcile.Result{}, nil
 }
 
 // Update User Email
-func updateUserEmail(workshop *workshopv1.Workshop, username string,
+func updateUserEmail(ctx context.Context, workshop *workshopv1.Workshop, username string, email string,
 	codeflavor string, namespace string, appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 	var (
 		err                    error
 		httpResponse           *http.Response
@@ -471,45 +460,36 @@ func updateUserEmail(workshop *workshopv1.Workshop, username string,
 		keycloakMasterTokenURL = "https://keycloak-" + namespace + "." + appsHostnameSuffix + "/auth/realms/master/protocol/openid-connect/token"
 		keycloakUserURL        = "https://keycloak-" + namespace + "." + appsHostnameSuffix + "/auth/admin/realms/" + codeflavor + "/users"
 		masterToken            util.Token
-		client                 = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
-		cheUser []struct {
+		client                 = newHTTPClient(workshop, httpTargetKeycloak)
+		cheUser                []struct {
 			ID    string `json:"id"`
 			Email string `json:"email"`
 		}
 	)
 
 	// Get Keycloak Admin Token
-	httpRequest, err = http.NewRequest("POST", keycloakMasterTokenURL, strings.NewReader("username=admin&password=admin&grant_type=password&client_id=admin-cli"))
+	httpRequest, err = http.NewRequestWithContext(ctx, "POST", keycloakMasterTokenURL, strings.NewReader("username=admin&password=admin&grant_type=password&client_id=admin-cli"))
 	if err != nil {
 		log.Error(err, "Failed http POST Request")
 	}
 	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
-		log.Errorf("Error when getting the master token from %s keycloak (%v)", codeflavor, err)
+		log.Error(err, "Failed to get the master token from Keycloak")
 		return reconcile.Result{}, err
 	}
 	defer httpResponse.Body.Close()
 	if httpResponse.StatusCode == http.StatusOK {
 		if err := json.NewDecoder(httpResponse.Body).Decode(&masterToken); err != nil {
-			log.Errorf("Error when reading the master token: %v", err)
+			log.Error(err, "Failed to read the master token from Keycloak")
 			return reconcile.Result{}, err
 		}
 	} else {
-		log.Errorf("Error when getting the master token from %s keycloak (%d)", codeflavor, httpResponse.StatusCode)
-		return reconcile.Result{}, err
+		return reconcile.Result{}, unexpectedStatusError("getting the master token from Keycloak", httpResponse)
 	}
 
 	// GET USER
-	httpRequest, err = http.NewRequest("GET", keycloakUserURL+"?username="+username, nil)
+	httpRequest, err = http.NewRequestWithContext(ctx, "GET", keycloakUserURL+"?username="+username, nil)
 	if err != nil {
 		log.Error(err, "Failed http GET Request")
 	}
@@ -517,65 +497,92 @@ func updateUserEmail(workshop *workshopv1.Workshop, username string,
 
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
-		log.Errorf("Error when getting %s user: %v", username, err)
+		log.Error(err, "Failed to get the Keycloak user")
 		return reconcile.Result{}, err
 	}
 
 	defer httpResponse.Body.Close()
 	if httpResponse.StatusCode == http.StatusOK {
 		if err := json.NewDecoder(httpResponse.Body).Decode(&cheUser); err != nil {
-			log.Errorf("Error to get the user info (%v)", err)
+			log.Error(err, "Failed to read the Keycloak user")
 			return reconcile.Result{}, err
 		}
 
+		if len(cheUser) == 0 {
+			return reconcile.Result{}, fmt.Errorf("no Keycloak user %s", username)
+		}
 		if cheUser[0].Email == "" {
-			httpRequest, err = http.NewRequest("PUT", keycloakUserURL+"/"+cheUser[0].ID,
-				strings.NewReader(`{"email":"`+username+`@none.com"}`))
+			httpRequest, err = http.NewRequestWithContext(ctx, "PUT", keycloakUserURL+"/"+cheUser[0].ID,
+				strings.NewReader(`{"email":"`+email+`"}`))
 			if err != nil {
 				log.Error(err, "Failed http PUT Request")
 			}
 			httpRequest.Header.Set("Content-Type", "application/json")
 			httpRequest.Header.Set("Authorization", "Bearer "+masterToken.AccessToken)
 
-			// remove httpResponse because it is unused
-			_, err = client.Do(httpRequest)
-
+			updateResponse, err := client.Do(httpRequest)
 			if err != nil {
-				log.Errorf("Error when update email address for %s: %v", username, err)
+				log.Error(err, "Failed to update the email address")
 				return reconcile.Result{}, err
 			}
+			defer updateResponse.Body.Close()
+			if updateResponse.StatusCode != http.StatusNoContent && updateResponse.StatusCode != http.StatusOK {
+				return reconcile.Result{}, unexpectedStatusError("updating the email address in Keycloak", updateResponse)
+			}
 		}
 	} else {
-		log.Errorf("Error when getting %s user: %v", username, httpResponse.StatusCode)
-		return reconcile.Result{}, err
+		return reconcile.Result{}, unexpectedStatusError("getting the Keycloak user", httpResponse)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-// Initialize workspace
-func initWorkspace(workshop *workshopv1.Workshop, username string,
+// Initialize workspace, unless the user already has the one created before
+func initWorkspace(ctx context.Context, workshop *workshopv1.Workshop, username string, workspaceID string,
 	codeflavor string, namespace string, userAccessToken string, devfile string,
-	appsHostnameSuffix string) (reconcile.Result, error) {
+	appsHostnameSuffix string) (codeready.Workspace, reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	var (
 		err                 error
 		httpResponse        *http.Response
 		httpRequest         *http.Request
-		devfileWorkspaceURL = "https://" + codeflavor + "-" + namespace + "." + appsHostnameSuffix + "/api/workspace/devfile?start-after-create=true&namespace=" + username
-		client              = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		workspacesURL       = "https://" + codeflavor + "-" + namespace + "." + appsHostnameSuffix + "/api/workspace"
+		devfileWorkspaceURL = workspacesURL + "/devfile?start-after-create=true&namespace=" + username
+		client              = newHTTPClient(workshop, httpTargetCodeReady)
 	)
 
-	httpRequest, err = http.NewRequest("POST", devfileWorkspaceURL, strings.NewReader(devfile))
+	// Get the workspaces of the user
+	httpRequest, err = http.NewRequestWithContext(ctx, "GET", workspacesURL, nil)
+	if err != nil {
+		log.Error(err, "Failed http GET Request")
+	}
+	httpRequest.Header.Set("Authorization", "Bearer "+userAccessToken)
+	httpRequest.Header.Set("Accept", "application/json")
+
+	httpResponse, err = client.Do(httpRequest)
+	if err != nil {
+		log.Error(err, "Failed to get the workspaces")
+		return codeready.Workspace{}, reconcile.Result{}, err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return codeready.Workspace{}, reconcile.Result{}, unexpectedStatusError("getting the workspaces", httpResponse)
+	}
+	workspaces := []codeready.Workspace{}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&workspaces); err != nil {
+		log.Error(err, "Failed to decode the workspaces")
+		return codeready.Workspace{}, reconcile.Result{}, err
+	}
+	// The workspace recorded in the status, or else the one created before the status was lost
+	for _, workspace := range workspaces {
+		if workspace.ID == workspaceID || workspaceID == "" {
+			return workspace, reconcile.Result{}, nil
+		}
+	}
+
+	httpRequest, err = http.NewRequestWithContext(ctx, "POST", devfileWorkspaceURL, strings.NewReader(devfile))
 	if err != nil {
 		log.Error(err, "Failed http POST Request")
 	}
@@ -585,82 +592,108 @@ func initWorkspace(workshop *workshopv1.Workshop, username string,
 
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
-		log.Errorf("Error when creating the workspace for %s: %v", username, err)
-		return reconcile.Result{}, err
+		log.Error(err, "Failed to create the workspace")
+		return codeready.Workspace{}, reconcile.Result{}, err
 	}
 	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusCreated && httpResponse.StatusCode != http.StatusOK {
+		return codeready.Workspace{}, reconcile.Result{}, unexpectedStatusError("creating the workspace", httpResponse)
+	}
+
+	workspace := codeready.Workspace{}
+	if err := json.NewDecoder(httpResponse.Body).Decode(&workspace); err != nil {
+		log.Error(err, "Failed to decode the workspace")
+		return codeready.Workspace{}, reconcile.Result{}, err
+	}
+	log.Info("Created", util.LogKind, "Workspace", util.LogName, workspace.ID)
 
 	//Success
-	return reconcile.Result{}, nil
+	return workspace, reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) deleteCodeReadyWorkspace(workshop *workshopv1.Workshop, users int, appsHostnameSuffix string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteCodeReadyWorkspace(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee, appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	codeReadyNamespaceName := util.ScopedName(workshop, CODEREADY_NAMESPACE_NAME)
 
-	channel := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub.ClusterServiceVersion
+	operatorHub := workshop.Spec.Infrastructure.CodeReadyWorkspace.OperatorHub
 
-	if !workshop.Spec.Infrastructure.CodeReadyWorkspace.OpenshiftOAuth {
-
-		for id := 1; id <= users; id++ {
-			username := fmt.Sprintf("user%d", id)
+	if !r.codeReadyOpenShiftOAuth(workshop) {
 
+		for _, user := range users {
+			username := user.Username
 			userWorkspacesNamespaceName := username + "-" + "workspace"
 			userWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, userWorkspacesNamespaceName)
 			// Delete Project
-			if err := r.Delete(context.TODO(), userWorkspacesNamespace); err != nil {
-				log.Errorf("Failed to Delete %s Namespace", userWorkspacesNamespace.Name)
+			if err := r.Delete(ctx, userWorkspacesNamespace); err != nil && !errors.IsNotFound(err) {
+				log.Error(err, "Failed to delete", util.LogKind, "Namespace", util.LogName, userWorkspacesNamespace.Name)
 
 				return reconcile.Result{}, err
 			}
-			log.Infof("Deleted %s Namespace", userWorkspacesNamespace.Name)
+			log.Info("Deleted", util.LogKind, "Namespace", util.LogName, userWorkspacesNamespace.Name)
 
 		}
 
-		cheClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, CHE_CLUSTER_ROLE_NAME, CODEREADY_NAMESPACE_NAME, codeReadyLabels, kubernetes.CheRules())
+		cheClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, util.ScopedName(workshop, CHE_CLUSTER_ROLE_NAME), codeReadyNamespaceName, codeReadyLabels, kubernetes.CheRules())
 		// Delete che Cluster Role
-		if err := r.Delete(context.TODO(), cheClusterRole); err != nil {
+		if err := r.Delete(ctx, cheClusterRole); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Cluster Role ", cheClusterRole.Name)
+		log.Info("Deleted", util.LogKind, "ClusterRole", util.LogName, cheClusterRole.Name)
 
-		cheClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, CHE_CLUSTER_ROLE_BINDING_NAME, CODEREADY_NAMESPACE_NAME, codeReadyLabels, CHE_SERVICEACCOUNT_NAME, cheClusterRole.Name, KIND_CLUSTER_ROLE)
+		cheClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, CHE_CLUSTER_ROLE_BINDING_NAME), codeReadyNamespaceName, codeReadyLabels, CHE_SERVICEACCOUNT_NAME, cheClusterRole.Name, KIND_CLUSTER_ROLE)
 		// Delete che Cluster RoleBinding
-		if err := r.Delete(context.TODO(), cheClusterRoleBinding); err != nil {
+		if err := r.Delete(ctx, cheClusterRoleBinding); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Cluster RoleBinding ", cheClusterRoleBinding.Name)
+		log.Info("Deleted", util.LogKind, "ClusterRoleBinding", util.LogName, cheClusterRoleBinding.Name)
 
 	}
 
-	codeReadyWorkspacesCustomResource := codeready.NewCustomResource(workshop, r.Scheme, CHE_CUSTOM_RESOURCE_NAME, CODEREADY_NAMESPACE_NAME)
-	// Delete codeReadyWorkspaces CustomResource
-	if err := r.Delete(context.TODO(), codeReadyWorkspacesCustomResource); err != nil {
+	codeReadyWorkspacesCustomResource := codeready.NewCustomResource(workshop, r.Scheme, CHE_CUSTOM_RESOURCE_NAME, codeReadyNamespaceName)
+	// Delete codeReadyWorkspaces CustomResource, whose CRD is gone if the operator was never installed
+	if err := r.Delete(ctx, codeReadyWorkspacesCustomResource); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  CustomResource", codeReadyWorkspacesCustomResource.Name)
+	log.Info("Deleted", util.LogKind, "CheCluster", util.LogName, codeReadyWorkspacesCustomResource.Name)
 
-	codeReadyWorkspacesSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, CODEREADY_SUBSCRIPTION_NAME, CODEREADY_NAMESPACE_NAME,
-		CODEREADY_SUBSCRIPTION_PACKAGE_NAME, channel, clusterServiceVersion)
-	// Delete Subscription
-	if err := r.Delete(context.TODO(), codeReadyWorkspacesSubscription); err != nil {
-		return reconcile.Result{}, err
-	}
-	log.Infof("Deleted %s Subscription", codeReadyWorkspacesSubscription.Name)
+	if r.isOpenShift() {
+		codeReadyWorkspacesSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, CODEREADY_SUBSCRIPTION_NAME, codeReadyNamespaceName,
+			CODEREADY_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+		// Delete Subscription
+		if err := r.Delete(ctx, codeReadyWorkspacesSubscription); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Subscription", util.LogName, codeReadyWorkspacesSubscription.Name)
 
-	codeReadyWorkspacesOperatorGroup := kubernetes.NewOperatorGroup(workshop, r.Scheme, CODEREADY_OPERATORGROUP_NAME, CODEREADY_NAMESPACE_NAME)
-	// Delete OperatorGroup
-	if err := r.Delete(context.TODO(), codeReadyWorkspacesOperatorGroup); err != nil {
-		return reconcile.Result{}, err
+		codeReadyWorkspacesOperatorGroup := kubernetes.NewOperatorGroup(workshop, r.Scheme, CODEREADY_OPERATORGROUP_NAME, codeReadyNamespaceName)
+		// Delete OperatorGroup
+		if err := r.Delete(ctx, codeReadyWorkspacesOperatorGroup); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "OperatorGroup", util.LogName, codeReadyWorkspacesOperatorGroup.Name)
+	} else if result, err := r.uninstallHelmRelease(ctx, workshop, codeReadyHelmRelease(workshop)); util.IsRequeued(result, err) {
+		return result, err
 	}
-	log.Infof("Deleted %s OperatorGroup", codeReadyWorkspacesOperatorGroup.Name)
 
-	codeReadyWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, CODEREADY_NAMESPACE_NAME)
+	codeReadyWorkspacesNamespace := kubernetes.NewNamespace(workshop, r.Scheme, codeReadyNamespaceName)
 	// Delete Project
-	if err := r.Delete(context.TODO(), codeReadyWorkspacesNamespace); err != nil {
+	if err := r.Delete(ctx, codeReadyWorkspacesNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Namespace", codeReadyWorkspacesNamespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, codeReadyWorkspacesNamespace.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// codeReadyOpenShiftOAuth returns true if the users log in to CodeReady Workspaces with OpenShift, rather than with its Keycloak
+func (r *WorkshopReconciler) codeReadyOpenShiftOAuth(workshop *workshopv1.Workshop) bool {
+	return r.isOpenShift() && workshop.Spec.Infrastructure.CodeReadyWorkspace.OpenshiftOAuth
+}
+
+// codeReadyHelmRelease returns the release of the Che operator standing in for the CodeReady Workspaces operator on Kubernetes.
+// The chart has no default, the spec sets it.
+func codeReadyHelmRelease(workshop *workshopv1.Workshop) helmRelease {
+	return newHelmRelease(CODEREADY_HELM_RELEASE_NAME, util.ScopedName(workshop, CODEREADY_NAMESPACE_NAME),
+		workshop.Spec.Infrastructure.CodeReadyWorkspace.Helm, "", "", nil)
+}
@@ -0,0 +1,141 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// Environment is the cluster information shared by the components of a Workshop
+type Environment struct {
+	Users               []util.Attendee
+	AppsHostnameSuffix  string
+	OpenshiftConsoleURL string
+}
+
+// Component is a tool installed by the Workshop
+type Component interface {
+	// Name identifies the component in the dependencies, the logs and the status
+	Name() string
+	// Enabled returns true if the spec asks for the component
+	Enabled(spec *workshopv1.WorkshopSpec) bool
+	// DependsOn returns the names of the components installed before this one.
+	// They are enabled whenever this component is.
+	DependsOn() []string
+	// Reconcile installs the component, it returns a requeue until the component is ready
+	Reconcile(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error)
+	// Finalize uninstalls the component
+	Finalize(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error)
+	// Status returns the status field holding the phase of the component,
+	// nil to report it in the addOns of the status
+	Status(status *workshopv1.WorkshopStatus) *string
+}
+
+// Registry holds the components installed by the Workshops
+type Registry struct {
+	components []Component
+}
+
+// NewRegistry returns a Registry with the given components
+func NewRegistry(components ...Component) (*Registry, error) {
+	registry := &Registry{}
+	if err := registry.Register(components...); err != nil {
+		return nil, err
+	}
+	return registry, nil
+}
+
+// Register adds components to the registry, the names must be unique
+func (r *Registry) Register(components ...Component) error {
+	for _, component := range components {
+		if r.Get(component.Name()) != nil {
+			return fmt.Errorf("component %s is already registered", component.Name())
+		}
+		r.components = append(r.components, component)
+	}
+	return nil
+}
+
+// Get returns the named component, nil if it is not registered
+func (r *Registry) Get(name string) Component {
+	for _, component := range r.components {
+		if component.Name() == name {
+			return component
+		}
+	}
+	return nil
+}
+
+// Sorted returns the components ordered so that each one comes after its dependencies.
+// Independent components keep the registration order.
+func (r *Registry) Sorted() ([]Component, error) {
+	for _, component := range r.components {
+		for _, dependency := range component.DependsOn() {
+			if r.Get(dependency) == nil {
+				return nil, fmt.Errorf("component %s depends on %s which is not registered", component.Name(), dependency)
+			}
+		}
+	}
+
+	sorted := make([]Component, 0, len(r.components))
+	placed := map[string]bool{}
+	for len(sorted) < len(r.components) {
+		progress := false
+		for _, component := range r.components {
+			if placed[component.Name()] || !allPlaced(component.DependsOn(), placed) {
+				continue
+			}
+			sorted = append(sorted, component)
+			placed[component.Name()] = true
+			progress = true
+			break
+		}
+		if !progress {
+			return nil, fmt.Errorf("the dependencies of the components have a cycle")
+		}
+	}
+	return sorted, nil
+}
+
+// Plan returns the components in dependency order, and the names of the components
+// enabled by the spec or by an enabled component depending on them
+func (r *Registry) Plan(spec *workshopv1.WorkshopSpec) ([]Component, map[string]bool, error) {
+	sorted, err := r.Sorted()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	enabled := map[string]bool{}
+	// Dependents come after their dependencies, so walk backwards to propagate
+	for i := len(sorted) - 1; i >= 0; i-- {
+		component := sorted[i]
+		if component.Enabled(spec) {
+			enabled[component.Name()] = true
+		}
+		if enabled[component.Name()] {
+			for _, dependency := range component.DependsOn() {
+				enabled[dependency] = true
+			}
+		}
+	}
+	return sorted, enabled, nil
+}
+
+// IsEnabled returns true if the named component is installed for the spec
+func (r *Registry) IsEnabled(spec *workshopv1.WorkshopSpec, name string) bool {
+	_, enabled, err := r.Plan(spec)
+	return err == nil && enabled[name]
+}
+
+func allPlaced(names []string, placed map[string]bool) bool {
+	for _, name := range names {
+		if !placed[name] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package controllers
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// componentFunc installs or uninstalls a component
+type componentFunc func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error)
+
+// builtinComponent adapts the reconcile and delete functions of the operator to the Component interface
+type builtinComponent struct {
+	name      string
+	dependsOn []string
+	enabled   func(spec *workshopv1.WorkshopSpec) bool
+	reconcile componentFunc
+	finalize  componentFunc
+	status    func(status *workshopv1.WorkshopStatus) *string
+}
+
+func (c *builtinComponent) Name() string {
+	return c.name
+}
+
+func (c *builtinComponent) Enabled(spec *workshopv1.WorkshopSpec) bool {
+	return c.enabled(spec)
+}
+
+func (c *builtinComponent) DependsOn() []string {
+	return c.dependsOn
+}
+
+func (c *builtinComponent) Reconcile(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+	return c.reconcile(ctx, r, workshop, env)
+}
+
+func (c *builtinComponent) Finalize(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+	return c.finalize(ctx, r, workshop, env)
+}
+
+func (c *builtinComponent) Status(status *workshopv1.WorkshopStatus) *string {
+	return c.status(status)
+}
+
+func always(spec *workshopv1.WorkshopSpec) bool {
+	return true
+}
+
+// builtinComponents returns the components shipped with the operator
+func builtinComponents() []Component {
+	return []Component{
+		&builtinComponent{
+			name:    "Users",
+			enabled: always,
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileUser(ctx, workshop, env.AppsHostnameSuffix)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteUsers(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Users },
+		},
+		&builtinComponent{
+			name:      "Portal",
+			dependsOn: []string{"Users"},
+			enabled:   always,
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcilePortal(ctx, workshop, env.Users, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deletePortal(ctx, workshop, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.UsernameDistribution },
+		},
+		&builtinComponent{
+			name:      "Project",
+			dependsOn: []string{"Users"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Project.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileProject(ctx, workshop, env.Users, env.AppsHostnameSuffix)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteProject(ctx, workshop, env.Users)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Project },
+		},
+		&builtinComponent{
+			name:      "Bookbag",
+			dependsOn: []string{"Users"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Guide.Bookbag.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileBookbag(ctx, workshop, env.Users, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteBookbag(ctx, workshop, env.Users, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Bookbag },
+		},
+		&builtinComponent{
+			name:    "Nexus",
+			enabled: func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Nexus.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileNexus(ctx, workshop, env.AppsHostnameSuffix)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteNexus(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Nexus },
+		},
+		&builtinComponent{
+			name:      "Gitea",
+			dependsOn: []string{"Users"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Gitea.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileGitea(ctx, workshop, env.Users, env.AppsHostnameSuffix)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteGitea(ctx, workshop, env.AppsHostnameSuffix)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Gitea },
+		},
+		&builtinComponent{
+			name:    "Pipeline",
+			enabled: func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Pipeline.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcilePipelines(ctx, workshop)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deletePipelines(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Pipeline },
+		},
+		&builtinComponent{
+			name: "GitOps",
+			// Argo CD manages the user projects
+			dependsOn: []string{"Users", "Project"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.GitOps.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileGitOps(ctx, workshop, env.Users, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteGitOps(ctx, workshop, env.Users, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.GitOps },
+		},
+		&builtinComponent{
+			name:      "CodeReadyWorkspace",
+			dependsOn: []string{"Users"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.CodeReadyWorkspace.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileCodeReadyWorkspace(ctx, workshop, env.Users, env.AppsHostnameSuffix, env.OpenshiftConsoleURL)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteCodeReadyWorkspace(ctx, workshop, env.Users, env.AppsHostnameSuffix)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.CodeReadyWorkspace },
+		},
+		&builtinComponent{
+			name: "ServiceMesh",
+			// The user projects are members of the mesh
+			dependsOn: []string{"Project"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.ServiceMesh.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileServiceMesh(ctx, workshop, env.Users, env.AppsHostnameSuffix)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteServiceMeshService(ctx, workshop, env.Users, env.AppsHostnameSuffix)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.ServiceMesh },
+		},
+		&builtinComponent{
+			name: "Serverless",
+			// Knative Serving runs on the mesh
+			dependsOn: []string{"ServiceMesh"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Serverless.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileServerless(ctx, workshop)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteServerless(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Serverless },
+		},
+		&builtinComponent{
+			name:    "Operators",
+			enabled: func(spec *workshopv1.WorkshopSpec) bool { return len(spec.Infrastructure.Operators) > 0 },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileOperators(ctx, workshop)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteOperators(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Operators },
+		},
+		&builtinComponent{
+			name:      "Vault",
+			dependsOn: []string{"Users"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.Vault.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileVault(ctx, workshop, env.Users)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteVault(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.Vault },
+		},
+		&builtinComponent{
+			name:      "CertManager",
+			dependsOn: []string{"Users"},
+			enabled:   func(spec *workshopv1.WorkshopSpec) bool { return spec.Infrastructure.CertManager.Enabled },
+			reconcile: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.reconcileCertManager(ctx, workshop, env.Users)
+			},
+			finalize: func(ctx context.Context, r *WorkshopReconciler, workshop *workshopv1.Workshop, env *Environment) (reconcile.Result, error) {
+				return r.deleteCertManager(ctx, workshop)
+			},
+			status: func(status *workshopv1.WorkshopStatus) *string { return &status.CertManager },
+		},
+	}
+}
+
+// NewDefaultRegistry returns a Registry with the components shipped with the operator,
+// add-ons can be registered on top of them
+func NewDefaultRegistry() *Registry {
+	registry, err := NewRegistry(builtinComponents()...)
+	if err != nil {
+		// The names of the builtin components are unique
+		panic(err)
+	}
+	return registry
+}
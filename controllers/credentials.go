@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"context"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	openshiftuser "github.com/stakater/workshop-operator/common/user"
+	"github.com/stakater/workshop-operator/common/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	USER_CREDENTIALS_SECRET_SUFFIX = "-credentials"
+	USER_PASSWORD_LENGTH           = 16
+)
+
+var userCredentialsLabels = map[string]string{
+	"app.kubernetes.io/part-of": "user-credentials",
+}
+
+// userCredentialsSecretName returns the name of the Secret holding the credentials of the user
+func userCredentialsSecretName(workshop *workshopv1.Workshop, username string) string {
+	return util.ScopedName(workshop, username+USER_CREDENTIALS_SECRET_SUFFIX)
+}
+
+// userPassword returns the password of the user. With the Generated password policy, the password
+// is read from the credentials Secret of the user, which is created on first use.
+func (r *WorkshopReconciler) userPassword(ctx context.Context, workshop *workshopv1.Workshop, username string) (string, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
+	if workshop.Spec.UserDetails.PasswordPolicy != workshopv1.PasswordPolicyGenerated {
+		return workshop.Spec.UserDetails.DefaultPassword, nil
+	}
+
+	secretName := userCredentialsSecretName(workshop, username)
+	secretFound := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: secretName, Namespace: workshop.Namespace}, secretFound); err == nil {
+		return string(secretFound.Data[corev1.BasicAuthPasswordKey]), nil
+	} else if !errors.IsNotFound(err) {
+		return "", err
+	}
+
+	password, err := util.GeneratePassword(USER_PASSWORD_LENGTH)
+	if err != nil {
+		return "", err
+	}
+
+	secret := openshiftuser.NewCredentialsSecret(workshop, r.Scheme, secretName, workshop.Namespace,
+		userCredentialsLabels, username, password)
+	if err := r.Create(ctx, secret); errors.IsAlreadyExists(err) {
+		// The cache is not synced yet with the Secret created by a previous reconcile
+		if err := r.apiReader.Get(ctx, types.NamespacedName{Name: secretName, Namespace: workshop.Namespace}, secretFound); err != nil {
+			return "", err
+		}
+		return string(secretFound.Data[corev1.BasicAuthPasswordKey]), nil
+	} else if err != nil {
+		return "", err
+	}
+	log.Info("Created", util.LogKind, "Secret", util.LogName, secret.Name)
+
+	return password, nil
+}
+
+// deleteUserCredentials deletes the credentials Secrets of the users which are no longer part of the Workshop
+func (r *WorkshopReconciler) deleteUserCredentials(ctx context.Context, workshop *workshopv1.Workshop, users map[string]bool) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	secrets := &corev1.SecretList{}
+	selector := labels.SelectorFromSet(util.WithOwnerLabels(workshop, userCredentialsLabels))
+	if err := r.List(ctx, secrets, client.InNamespace(workshop.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	generated := workshop.Spec.UserDetails.PasswordPolicy == workshopv1.PasswordPolicyGenerated
+	for i := range secrets.Items {
+		secret := &secrets.Items[i]
+		if _, ok := users[string(secret.Data[corev1.BasicAuthUsernameKey])]; ok && generated {
+			continue
+		}
+		if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Secret", util.LogName, secret.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
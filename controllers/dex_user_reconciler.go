@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	openshiftuser "github.com/stakater/workshop-operator/common/user"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+const (
+	DEX_NAMESPACE_NAME    = "dex"
+	DEX_HELM_RELEASE_NAME = "dex"
+	DEX_HELM_REPOSITORY   = "https://charts.dexidp.io"
+	DEX_HELM_CHART        = "dex"
+	DEX_SERVICE_NAME      = "dex"
+	DEX_SERVICE_PORT      = 5556
+	// DEX_CLIENT_ID is the client of the API server, given to it with --oidc-client-id
+	DEX_CLIENT_ID = "kubernetes"
+)
+
+var dexLabels = map[string]string{
+	"app.kubernetes.io/part-of": "dex",
+}
+
+// isDexUser returns true if the users of the Workshop log in to Kubernetes with a password in Dex
+func isDexUser(workshop *workshopv1.Workshop) bool {
+	return workshop.Spec.UserDetails.KubernetesIdentity == workshopv1.KubernetesIdentityDex
+}
+
+// dexIssuerURL returns the URL of Dex, the OIDC issuer given to the API server with --oidc-issuer-url
+func dexIssuerURL(appsHostnameSuffix string) string {
+	return "https://" + kubernetes.RouteHost(DEX_SERVICE_NAME, DEX_NAMESPACE_NAME, appsHostnameSuffix)
+}
+
+// reconcileDexUsers gives each user a password in Dex on Kubernetes, which the API server trusts as its OIDC issuer,
+// and deletes the passwords of the users no longer in the roster. Dex is shared by the Workshops of the cluster.
+func (r *WorkshopReconciler) reconcileDexUsers(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	// The users had a Service Account before
+	usersNamespace := &corev1.Namespace{}
+	if err := kubernetes.GetObject(ctx, r, util.ScopedName(workshop, USERS_NAMESPACE_NAME), "", usersNamespace); err == nil {
+		if result, err := r.deleteServiceAccountUsers(ctx, workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
+	} else if !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	if result, err := r.installHelmRelease(ctx, workshop, dexHelmRelease(workshop, appsHostnameSuffix)); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	dexRoute := r.newSecuredRoute(workshop, DEX_SERVICE_NAME, DEX_NAMESPACE_NAME, dexLabels, DEX_SERVICE_NAME, DEX_SERVICE_PORT, appsHostnameSuffix)
+	if err := r.applyShared(ctx, workshop, dexRoute); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	passwords, err := r.ownedDexPasswords(ctx, workshop)
+	if meta.IsNoMatchError(err) {
+		// Dex creates its CRDs once it runs
+		log.Info("Waiting for the API", util.LogKind, openshiftuser.DexPasswordGroupVersionKind.Kind)
+		return reconcile.Result{RequeueAfter: kubernetes.ReadinessRequeueAfter}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+	existing := make(map[string]bool, len(passwords.Items))
+	for _, password := range passwords.Items {
+		existing[password.GetName()] = true
+	}
+
+	roster := make(map[string]bool, len(users))
+	passwordNames := make(map[string]bool, len(users))
+	for provisioned, user := range users {
+		roster[user.Username] = true
+		passwordNames[openshiftuser.DexPasswordName(user.Email)] = true
+		result, err := r.addDexUser(ctx, workshop, user)
+		setProvisioned(workshop, "Users", user.Username, err)
+		attendeeStatus(workshop, user.Username).UserReady = err == nil
+		if util.IsRequeued(result, err) {
+			if err != nil {
+				r.warningEvent(workshop, EventUserFailed, "Users", user.Username, "Failed to create the Dex password: %s", err)
+			}
+			recordUsers(workshop, len(users), provisioned)
+			return result, err
+		}
+		if !existing[openshiftuser.DexPasswordName(user.Email)] {
+			r.normalEvent(workshop, EventUserCreated, "Users", user.Username, "Created the Dex password")
+		}
+	}
+	recordUsers(workshop, len(users), len(users))
+
+	for i := range passwords.Items {
+		password := &passwords.Items[i]
+		if passwordNames[password.GetName()] {
+			continue
+		}
+		username, _, _ := unstructured.NestedString(password.Object, "username")
+		if result, err := r.deleteDexUser(ctx, workshop, password, username, !roster[username]); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	if result, err := r.deleteUserCredentials(ctx, workshop, roster); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// addDexUser creates the Dex password of a user and its role binding, the hash of the password is kept as long as it matches
+func (r *WorkshopReconciler) addDexUser(ctx context.Context, workshop *workshopv1.Workshop, user util.Attendee) (reconcile.Result, error) {
+	username := user.Username
+	password, err := r.userPassword(ctx, workshop, username)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	hash := ""
+	found := openshiftuser.NewDexPasswordObject()
+	if err := kubernetes.GetObject(ctx, r, openshiftuser.DexPasswordName(user.Email), DEX_NAMESPACE_NAME, found); err == nil {
+		if !util.IsOwnedBy(workshop, found.GetLabels()) {
+			return reconcile.Result{}, fmt.Errorf("the Dex password of %s already exists and is not owned by Workshop %s/%s, use another email",
+				user.Email, workshop.Namespace, workshop.Name)
+		}
+		hash = openshiftuser.DexPasswordHash(found)
+	} else if !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	if !r.passwords.Matches(workshop.UID, username, hash, password) {
+		if hash, err = r.passwords.Hash(workshop.UID, username, password); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	dexPassword := openshiftuser.NewDexPassword(workshop, DEX_NAMESPACE_NAME, user.Email, username, hash, userLabels)
+	if err := r.apply(ctx, dexPassword); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username, USER_ROLE_BINDING_NAMESPACE_NAME, userLabels,
+		[]rbac.Subject{r.userSubject(workshop, username)}, USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
+	if err := r.apply(ctx, userRoleBinding); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteDexUsers deletes the Dex passwords of the users, then Dex unless another Workshop uses it
+func (r *WorkshopReconciler) deleteDexUsers(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	passwords, err := r.ownedDexPasswords(ctx, workshop)
+	if err != nil && !meta.IsNoMatchError(err) {
+		return reconcile.Result{}, err
+	}
+	for i := range passwords.Items {
+		username, _, _ := unstructured.NestedString(passwords.Items[i].Object, "username")
+		if result, err := r.deleteDexUser(ctx, workshop, &passwords.Items[i], username, true); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	// Dex is shared by the Workshops of the cluster
+	uses := func(other *workshopv1.Workshop) bool {
+		return isDexUser(other)
+	}
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, uses)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	release := dexHelmRelease(workshop, "")
+	if isUsed {
+		// Another Workshop takes over the release
+		return r.handOverHelmReleasesUsing(ctx, workshop, uses, release)
+	}
+
+	dexRoute := kubernetes.NewIngressObject()
+	dexRoute.SetName(DEX_SERVICE_NAME)
+	dexRoute.SetNamespace(DEX_NAMESPACE_NAME)
+	if err := kubernetes.GetObject(ctx, r, dexRoute.GetName(), dexRoute.GetNamespace(), dexRoute); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if err == nil && util.IsOwnedBy(workshop, dexRoute.GetLabels()) {
+		if err := r.Delete(ctx, dexRoute); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Ingress", util.LogName, dexRoute.GetName())
+	}
+
+	return r.uninstallHelmRelease(ctx, workshop, release)
+}
+
+// deleteDexUser deletes the Dex password of a user, and its role binding unless the user is still in the roster with another email
+func (r *WorkshopReconciler) deleteDexUser(ctx context.Context, workshop *workshopv1.Workshop, password *unstructured.Unstructured, username string,
+	removed bool) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
+
+	if err := r.Delete(ctx, password); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, openshiftuser.DexPasswordGroupVersionKind.Kind, util.LogName, password.GetName())
+	if !removed {
+		return reconcile.Result{}, nil
+	}
+
+	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username, USER_ROLE_BINDING_NAMESPACE_NAME, userLabels,
+		nil, USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
+	if err := r.Delete(ctx, userRoleBinding); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, userRoleBinding.Name)
+	r.passwords.Forget(workshop.UID, username)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// ownedDexPasswords returns the Dex passwords created by the Workshop for its users
+func (r *WorkshopReconciler) ownedDexPasswords(ctx context.Context, workshop *workshopv1.Workshop) (*unstructured.UnstructuredList, error) {
+	passwords := openshiftuser.NewDexPasswordList()
+	err := r.List(ctx, passwords, client.InNamespace(DEX_NAMESPACE_NAME), client.MatchingLabels{util.WorkshopUIDLabel: string(workshop.UID)})
+	return passwords, err
+}
+
+// dexHelmRelease returns the release of Dex, with the password database of the users and the client of the API server
+func dexHelmRelease(workshop *workshopv1.Workshop, appsHostnameSuffix string) helmRelease {
+	return newHelmRelease(DEX_HELM_RELEASE_NAME, DEX_NAMESPACE_NAME, workshop.Spec.UserDetails.Dex,
+		DEX_HELM_REPOSITORY, DEX_HELM_CHART, map[string]interface{}{
+			"fullnameOverride": DEX_SERVICE_NAME,
+			"config": map[string]interface{}{
+				"issuer": dexIssuerURL(appsHostnameSuffix),
+				"storage": map[string]interface{}{
+					"type":   "kubernetes",
+					"config": map[string]interface{}{"inCluster": true},
+				},
+				"enablePasswordDB": true,
+				"oauth2": map[string]interface{}{
+					"skipApprovalScreen": true,
+					"passwordConnector":  "local",
+				},
+				"staticClients": []interface{}{
+					map[string]interface{}{
+						"id":           DEX_CLIENT_ID,
+						"name":         "Kubernetes",
+						"public":       true,
+						"redirectURIs": []interface{}{"http://localhost:8000", "http://localhost:18000"},
+					},
+				},
+			},
+		})
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// Annotations of the Events giving the component and the user they are about
+const (
+	EventComponentAnnotation = "workshop.stakater.com/component"
+	EventUserAnnotation      = "workshop.stakater.com/user"
+)
+
+// Event reasons
+const (
+	EventComponentInstalled   = "ComponentInstalled"
+	EventComponentUninstalled = "ComponentUninstalled"
+	EventComponentFailed      = "ComponentFailed"
+	EventUserCreated          = "UserCreated"
+	EventUserFailed           = "UserFailed"
+	EventInstallPlanApproved  = "InstallPlanApproved"
+	EventInstallPlanPending   = "InstallPlanPending"
+	EventGiteaSignUpFailed    = "GiteaSignUpFailed"
+	EventDevfileFetchFailed   = "DevfileFetchFailed"
+)
+
+// recordEvent records an Event on the Workshop about a component and, if not empty, a user.
+// The message starts with them so that they show in `oc describe workshop`.
+func recordEvent(recorder record.EventRecorder, workshop *workshopv1.Workshop, eventType string, reason string,
+	component string, username string, messageFmt string, args ...interface{}) {
+	if recorder == nil {
+		return
+	}
+
+	annotations := map[string]string{EventComponentAnnotation: component}
+	prefix := component
+	if username != "" {
+		annotations[EventUserAnnotation] = username
+		prefix = fmt.Sprintf("%s, user %s", component, username)
+	}
+	recorder.AnnotatedEventf(workshop, annotations, eventType, reason, "%s: %s", prefix, fmt.Sprintf(messageFmt, args...))
+}
+
+// normalEvent records a Normal Event on the Workshop
+func (r *WorkshopReconciler) normalEvent(workshop *workshopv1.Workshop, reason string, component string, username string,
+	messageFmt string, args ...interface{}) {
+	recordEvent(r.Recorder, workshop, corev1.EventTypeNormal, reason, component, username, messageFmt, args...)
+}
+
+// warningEvent records a Warning Event on the Workshop
+func (r *WorkshopReconciler) warningEvent(workshop *workshopv1.Workshop, reason string, component string, username string,
+	messageFmt string, args ...interface{}) {
+	recordEvent(r.Recorder, workshop, corev1.EventTypeWarning, reason, component, username, messageFmt, args...)
+}
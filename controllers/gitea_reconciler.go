@@ -1,21 +1,21 @@
 package controllers
 
 import (
+	"bytes"
 	"context"
-	"crypto/tls"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	routev1 "github.com/openshift/api/route/v1"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/gitea"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/util"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -43,16 +43,39 @@ const (
 	GITEAROLEBINDINGNAME       = "gitea-operator"
 	GITEASERVICEACCOUNTNAME    = "gitea-operator"
 	GITEACLUSTERROLENAME       = "gitea-operator"
+	GITEAHELMRELEASENAME       = "gitea"
+	GITEAHELMREPOSITORY        = "https://dl.gitea.com/charts/"
+	GITEAHELMCHART             = "gitea"
+	GITEAADMINSECRETNAME       = "gitea-admin"
+	GITEAADMINUSERNAME         = "workshop-admin"
+	GITEASERVICEPORT           = 3000
 )
 
+// giteaHelmLabels select the pods of the Gitea release on Kubernetes
+var giteaHelmLabels = map[string]string{
+	"app.kubernetes.io/name":     "gitea",
+	"app.kubernetes.io/instance": GITEAHELMRELEASENAME,
+}
+
+// giteaServiceURL returns the in-cluster URL of the Gitea server of the Workshop
+func giteaServiceURL(workshop *workshopv1.Workshop) string {
+	return "http://" + GITEADEPLOYMENTNAME + "." + util.ScopedName(workshop, GITEANAMESPACENAME) + ".svc:3000"
+}
+
+// giteaRouteURL returns the URL of the Route of the Gitea server of the Workshop
+func giteaRouteURL(workshop *workshopv1.Workshop, appsHostnameSuffix string) string {
+	return "https://" + kubernetes.RouteHost(GITEADEPLOYMENTNAME, util.ScopedName(workshop, GITEANAMESPACENAME), appsHostnameSuffix)
+}
+
 // Reconciling Gitea
-func (r *WorkshopReconciler) reconcileGitea(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-	enabledGitea := workshop.Spec.Infrastructure.Gitea.Enabled
+func (r *WorkshopReconciler) reconcileGitea(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		return r.addKubernetesGitea(ctx, workshop, users, appsHostnameSuffix)
+	}
 
-	if enabledGitea {
-		if result, err := r.addGitea(workshop, users); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addGitea(ctx, workshop, users); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -60,88 +83,82 @@ func (r *WorkshopReconciler) reconcileGitea(workshop *workshopv1.Workshop, users
 }
 
 // Add Gitea
-func (r *WorkshopReconciler) addGitea(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addGitea(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
 	imageName := workshop.Spec.Infrastructure.Gitea.Image.Name
 	imageTag := workshop.Spec.Infrastructure.Gitea.Image.Tag
 
 	// Create Project
-	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, GITEANAMESPACENAME)
-	if err := r.Create(context.TODO(), giteaNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, util.ScopedName(workshop, GITEANAMESPACENAME))
+	if err := r.apply(ctx, giteaNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Project", giteaNamespace.Name)
 	}
 
 	// Create CRD
 	giteaCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, GITEACRDNAME, GITEACRDGROUPNAME, GITEACRDKINDNAME, GITEACRDLISTKINDNAME, GITEACRDPLURALNAME, GITEACRDSINGULARNAME, GITEACRDVERSIONAME, nil, nil)
-	if err := r.Create(context.TODO(), giteaCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, giteaCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Custom Resource Definition", giteaCustomResourceDefinition.Name)
+		log.Info("Created", util.LogKind, "CustomResourceDefinition", util.LogName, giteaCustomResourceDefinition.Name)
 	}
 
 	// Create Service Account
 	giteaServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, GITEASERVICEACCOUNTNAME, giteaNamespace.Name, gitealabels)
-	if err := r.Create(context.TODO(), giteaServiceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, giteaServiceAccount); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service Account", giteaServiceAccount.Name)
 	}
 
 	// Create Cluster Role
-	giteaClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, GITEACLUSTERROLENAME, giteaNamespace.Name, gitealabels, kubernetes.GiteaRules())
-	if err := r.Create(context.TODO(), giteaClusterRole); err != nil && !errors.IsAlreadyExists(err) {
+	giteaClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, util.ScopedName(workshop, GITEACLUSTERROLENAME), giteaNamespace.Name, gitealabels, kubernetes.GiteaRules())
+	if err := r.apply(ctx, giteaClusterRole); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Cluster Role", giteaClusterRole.Name)
 	}
 
 	// Create Cluster Role Binding
-	giteaClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, GITEAROLEBINDINGNAME, giteaNamespace.Name, gitealabels, GITEASERVICEACCOUNTNAME, GITEAROLEBINDINGNAME, CLUSTERROLEKINDNAME)
-	if err := r.Create(context.TODO(), giteaClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	giteaClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, GITEAROLEBINDINGNAME), giteaNamespace.Name, gitealabels, GITEASERVICEACCOUNTNAME, giteaClusterRole.Name, CLUSTERROLEKINDNAME)
+	if err := r.apply(ctx, giteaClusterRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Cluster Role Binding", giteaClusterRoleBinding.Name)
 	}
 
 	giteaOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, GITEAANSIBLEDEPLOYMENTNAME, giteaNamespace.Name, gitealabels, imageName+":"+imageTag, GITEASERVICEACCOUNTNAME)
 
 	// Create Operator
-	if err := r.Create(context.TODO(), giteaOperator); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, giteaOperator); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Operator", giteaOperator.Name)
 	}
 
 	// Create Custom Resource
 	giteaCustomResource := gitea.NewCustomResource(workshop, r.Scheme, GITEACRNAME, giteaNamespace.Name, gitealabels)
-	if err := r.Create(context.TODO(), giteaCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, giteaCustomResource); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Custom Resource", giteaCustomResource.Name)
 	}
 
 	// Wait for server to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(GITEADEPLOYMENTNAME, giteaNamespace.Name) {
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 1}, nil
+	if result, err := kubernetes.CheckDeploymentReady(ctx, r, GITEADEPLOYMENTNAME, giteaNamespace.Name); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	// Extract app route suffix from openshift-console
 	giteaRouteFound := &routev1.Route{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: GITEADEPLOYMENTNAME, Namespace: giteaNamespace.Name}, giteaRouteFound); err != nil {
-		log.Errorf("Failed to find %s route", "gitea-server")
+	if err := r.Get(ctx, types.NamespacedName{Name: GITEADEPLOYMENTNAME, Namespace: giteaNamespace.Name}, giteaRouteFound); err != nil {
+		log.Error(err, "Failed to get", util.LogKind, "Route", util.LogName, GITEADEPLOYMENTNAME)
 		return reconcile.Result{}, err
 	}
 
 	giteaURL := "https://" + giteaRouteFound.Spec.Host
 
 	// Create workshop users in gitea
-	for id := 1; id <= users; id++ {
-		username := fmt.Sprintf("user%d", id)
-		if result, err := createGitUser(workshop, username, giteaURL); err != nil {
-			return result, err
+	if err := provisionUsers(ctx, workshop, "Gitea", users, true, func(ctx context.Context, user util.Attendee, attendee *workshopv1.AttendeeStatus) error {
+		password, err := r.userPassword(ctx, workshop, user.Username)
+		if err != nil {
+			return err
 		}
+		_, err = r.createGitUser(ctx, workshop, user.Username, password, user.Email, giteaURL)
+		attendee.GiteaAccount = err == nil
+		return err
+	}); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	//Success
@@ -149,34 +166,27 @@ func (r *WorkshopReconciler) addGitea(workshop *workshopv1.Workshop, users int)
 }
 
 // Create GitUser
-func createGitUser(workshop *workshopv1.Workshop, username string, giteaURL string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) createGitUser(ctx context.Context, workshop *workshopv1.Workshop, username string, password string, email string, giteaURL string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	var (
-		openshiftUserPassword = workshop.Spec.UserDetails.DefaultPassword
+		openshiftUserPassword = password
 		err                   error
 		httpResponse          *http.Response
 		httpRequest           *http.Request
 		requestURL            = giteaURL + "/user/sign_up"
 		body                  = url.Values{}
-		client                = &http.Client{
-			Transport: &http.Transport{
-				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
-			},
-			// Do not follow Redirect
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				return http.ErrUseLastResponse
-			},
-		}
+		client                = newHTTPClient(workshop, httpTargetGitea)
 	)
 
 	body.Set("user_name", username)
-	body.Set("email", username+"@none.com")
+	body.Set("email", email)
 	body.Set("password", openshiftUserPassword)
 	body.Set("retype", openshiftUserPassword)
 
-	httpRequest, err = http.NewRequest("POST", requestURL, strings.NewReader(body.Encode()))
+	httpRequest, err = http.NewRequestWithContext(ctx, "POST", requestURL, strings.NewReader(body.Encode()))
 	if err != nil {
-		log.Error(err, "Failed http POST Request  ")
+		log.Error(err, "Failed http POST Request")
 	}
 	httpRequest.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	httpRequest.Header.Set("Accept", "application/json")
@@ -184,76 +194,279 @@ func createGitUser(workshop *workshopv1.Workshop, username string, giteaURL stri
 
 	httpResponse, err = client.Do(httpRequest)
 	if err != nil {
+		r.warningEvent(workshop, EventGiteaSignUpFailed, "Gitea", username, "Sign-up failed: %s", err)
 		return reconcile.Result{}, err
 	}
+	defer httpResponse.Body.Close()
 	if httpResponse.StatusCode == http.StatusCreated {
-		log.Infof("Created %s user in Gitea", username)
+		log.Info("Created", util.LogKind, "GiteaUser")
+		r.normalEvent(workshop, EventUserCreated, "Gitea", username, "Signed up in Gitea")
+		return reconcile.Result{}, nil
 	}
 
-	defer httpResponse.Body.Close()
+	// The sign-up page answers 200 with a form error when the user already exists
+	exists, err := giteaUserExists(ctx, client, giteaURL, username)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if !exists {
+		r.warningEvent(workshop, EventGiteaSignUpFailed, "Gitea", username, "Sign-up returned %s", httpResponse.Status)
+		return reconcile.Result{}, fmt.Errorf("gitea sign-up returned %s and the user does not exist", httpResponse.Status)
+	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
+// giteaUserExists returns true if Gitea has an account for the user
+func giteaUserExists(ctx context.Context, client *http.Client, giteaURL string, username string) (bool, error) {
+	httpRequest, err := http.NewRequestWithContext(ctx, "GET", giteaURL+"/api/v1/users/"+url.PathEscape(username), nil)
+	if err != nil {
+		return false, err
+	}
+	httpRequest.Header.Set("Accept", "application/json")
+
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		return false, err
+	}
+	defer httpResponse.Body.Close()
+
+	switch httpResponse.StatusCode {
+	case http.StatusOK:
+		return true, nil
+	case http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("getting the gitea user returned %s", httpResponse.Status)
+	}
+}
+
 // Delete Gitea
-func (r *WorkshopReconciler) deleteGitea(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteGitea(ctx context.Context, workshop *workshopv1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	if !r.isOpenShift() {
+		return r.deleteKubernetesGitea(ctx, workshop, appsHostnameSuffix)
+	}
 
 	log.Info("Deleting gitea")
 
+	giteaNamespaceName := util.ScopedName(workshop, GITEANAMESPACENAME)
 	imageName := workshop.Spec.Infrastructure.Gitea.Image.Name
 	imageTag := workshop.Spec.Infrastructure.Gitea.Image.Tag
 
-	giteaCustomResource := gitea.NewCustomResource(workshop, r.Scheme, GITEACRNAME, GITEANAMESPACENAME, gitealabels)
+	giteaCustomResource := gitea.NewCustomResource(workshop, r.Scheme, GITEACRNAME, giteaNamespaceName, gitealabels)
 	// Delete Custom Resource
-	if err := r.Delete(context.TODO(), giteaCustomResource); err != nil {
+	if err := r.Delete(ctx, giteaCustomResource); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Custom Resource", giteaCustomResource.Name)
+	log.Info("Deleted", util.LogKind, "Gitea", util.LogName, giteaCustomResource.Name)
 
-	giteaOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, GITEAANSIBLEDEPLOYMENTNAME, GITEANAMESPACENAME, gitealabels, imageName+":"+imageTag, GITEASERVICEACCOUNTNAME)
+	giteaOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, GITEAANSIBLEDEPLOYMENTNAME, giteaNamespaceName, gitealabels, imageName+":"+imageTag, GITEASERVICEACCOUNTNAME)
 	// Delete Operator
-	if err := r.Delete(context.TODO(), giteaOperator); err != nil {
+	if err := r.Delete(ctx, giteaOperator); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Operator", giteaOperator.Name)
+	log.Info("Deleted", util.LogKind, "Deployment", util.LogName, giteaOperator.Name)
 
-	giteaClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, GITEAROLEBINDINGNAME, GITEANAMESPACENAME, gitealabels, GITEASERVICEACCOUNTNAME, GITEACLUSTERROLENAME, CLUSTERROLEKINDNAME)
+	giteaClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, GITEAROLEBINDINGNAME), giteaNamespaceName, gitealabels, GITEASERVICEACCOUNTNAME, util.ScopedName(workshop, GITEACLUSTERROLENAME), CLUSTERROLEKINDNAME)
 	// Delete Cluster Role Binding
-	if err := r.Delete(context.TODO(), giteaClusterRoleBinding); err != nil {
+	if err := r.Delete(ctx, giteaClusterRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Cluster  Role Binding", giteaClusterRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRoleBinding", util.LogName, giteaClusterRoleBinding.Name)
 
-	giteaClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, GITEACLUSTERROLENAME, GITEANAMESPACENAME, gitealabels, kubernetes.GiteaRules())
+	giteaClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, util.ScopedName(workshop, GITEACLUSTERROLENAME), giteaNamespaceName, gitealabels, kubernetes.GiteaRules())
 	// Delete Cluster Role
-	if err := r.Delete(context.TODO(), giteaClusterRole); err != nil {
+	if err := r.Delete(ctx, giteaClusterRole); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Cluster Role", giteaClusterRole.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRole", util.LogName, giteaClusterRole.Name)
 
-	giteaServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, GITEASERVICEACCOUNTNAME, GITEANAMESPACENAME, gitealabels)
+	giteaServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, GITEASERVICEACCOUNTNAME, giteaNamespaceName, gitealabels)
 	// Delete Service Account
-	if err := r.Delete(context.TODO(), giteaServiceAccount); err != nil {
+	if err := r.Delete(ctx, giteaServiceAccount); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Service Account", giteaServiceAccount.Name)
+	log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, giteaServiceAccount.Name)
 
-	giteaCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, GITEACRDNAME, GITEACRDGROUPNAME, GITEACRDKINDNAME, GITEACRDLISTKINDNAME, GITEACRDPLURALNAME, GITEACRDSINGULARNAME, GITEACRDVERSIONAME, nil, nil)
-	// Delete CRD
-	if err := r.Delete(context.TODO(), giteaCustomResourceDefinition); err != nil {
+	// The CRD is shared by the Gitea operators of all Workshops
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "Gitea")
+	})
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Custom Resource Definition", giteaCustomResourceDefinition.Name)
+	if !isUsed {
+		giteaCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, GITEACRDNAME, GITEACRDGROUPNAME, GITEACRDKINDNAME, GITEACRDLISTKINDNAME, GITEACRDPLURALNAME, GITEACRDSINGULARNAME, GITEACRDVERSIONAME, nil, nil)
+		// Delete CRD
+		if err := r.Delete(ctx, giteaCustomResourceDefinition); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "CustomResourceDefinition", util.LogName, giteaCustomResourceDefinition.Name)
+	}
 
-	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, GITEANAMESPACENAME)
+	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, giteaNamespaceName)
 	// Delete Project
-	if err := r.Delete(context.TODO(), giteaNamespace); err != nil {
+	if err := r.Delete(ctx, giteaNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s gitea Project ", GITEANAMESPACENAME)
+	log.Info("Deleted", util.LogKind, "Project", util.LogName, giteaNamespaceName)
 	log.Info("Gitea deleted succesfully")
 
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// addKubernetesGitea installs Gitea with its chart on Kubernetes, exposes it as the Gitea operator does on OpenShift,
+// and creates the users with the API of the admin user, the sign-up form requiring a CSRF token
+func (r *WorkshopReconciler) addKubernetesGitea(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, util.ScopedName(workshop, GITEANAMESPACENAME))
+	if err := r.apply(ctx, giteaNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The password of the admin user is generated once
+	adminPassword, err := util.GeneratePassword(16)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	adminSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, GITEAADMINSECRETNAME, giteaNamespace.Name, gitealabels,
+		map[string]string{
+			"username": GITEAADMINUSERNAME,
+			"password": adminPassword,
+		})
+	admin := GITEAADMINUSERNAME
+	if err := r.Create(ctx, adminSecret); errors.IsAlreadyExists(err) {
+		adminSecretFound := &corev1.Secret{}
+		if err := r.Get(ctx, types.NamespacedName{Name: GITEAADMINSECRETNAME, Namespace: giteaNamespace.Name}, adminSecretFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		admin = string(adminSecretFound.Data["username"])
+		adminPassword = string(adminSecretFound.Data["password"])
+	} else if err != nil {
+		return reconcile.Result{}, err
+	} else {
+		log.Info("Created", util.LogKind, "Secret", util.LogName, adminSecret.Name)
+	}
+
+	if result, err := r.installHelmRelease(ctx, workshop, giteaHelmRelease(workshop, appsHostnameSuffix)); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	giteaService := kubernetes.NewService(workshop, r.Scheme, GITEADEPLOYMENTNAME, giteaNamespace.Name, giteaHelmLabels,
+		[]string{"http"}, []int32{GITEASERVICEPORT})
+	if err := r.apply(ctx, giteaService); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	giteaRoute := r.newSecuredRoute(workshop, GITEADEPLOYMENTNAME, giteaNamespace.Name, gitealabels, GITEADEPLOYMENTNAME, GITEASERVICEPORT, appsHostnameSuffix)
+	if err := r.apply(ctx, giteaRoute); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Create workshop users in gitea
+	if err := provisionUsers(ctx, workshop, "Gitea", users, true, func(ctx context.Context, user util.Attendee, attendee *workshopv1.AttendeeStatus) error {
+		password, err := r.userPassword(ctx, workshop, user.Username)
+		if err != nil {
+			return err
+		}
+		err = r.createGiteaUserAsAdmin(ctx, workshop, user.Username, password, user.Email, giteaServiceURL(workshop), admin, adminPassword)
+		attendee.GiteaAccount = err == nil
+		return err
+	}); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// createGiteaUserAsAdmin creates a Gitea user with the API of the admin user, unless it exists
+func (r *WorkshopReconciler) createGiteaUserAsAdmin(ctx context.Context, workshop *workshopv1.Workshop, username string, password string, email string,
+	giteaURL string, admin string, adminPassword string) error {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
+	client := newHTTPClient(workshop, httpTargetGitea)
+
+	if exists, err := giteaUserExists(ctx, client, giteaURL, username); err != nil || exists {
+		return err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"username":             username,
+		"email":                email,
+		"password":             password,
+		"must_change_password": false,
+	})
+	if err != nil {
+		return err
+	}
+	httpRequest, err := http.NewRequestWithContext(ctx, "POST", giteaURL+"/api/v1/admin/users", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "application/json")
+	httpRequest.Header.Set("Accept", "application/json")
+	httpRequest.Header.Set("Authorization", "Basic "+util.GetBasicAuth(admin, adminPassword))
+
+	httpResponse, err := client.Do(httpRequest)
+	if err != nil {
+		r.warningEvent(workshop, EventGiteaSignUpFailed, "Gitea", username, "Creation failed: %s", err)
+		return err
+	}
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusCreated {
+		err := unexpectedStatusError("creating the gitea user", httpResponse)
+		r.warningEvent(workshop, EventGiteaSignUpFailed, "Gitea", username, "Creation failed: %s", err)
+		return err
+	}
+	log.Info("Created", util.LogKind, "GiteaUser")
+	r.normalEvent(workshop, EventUserCreated, "Gitea", username, "Created in Gitea")
+	return nil
+}
+
+// deleteKubernetesGitea uninstalls the release of Gitea and deletes its namespace
+func (r *WorkshopReconciler) deleteKubernetesGitea(ctx context.Context, workshop *workshopv1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	if result, err := r.uninstallHelmRelease(ctx, workshop, giteaHelmRelease(workshop, appsHostnameSuffix)); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	giteaNamespace := kubernetes.NewNamespace(workshop, r.Scheme, util.ScopedName(workshop, GITEANAMESPACENAME))
+	if err := r.Delete(ctx, giteaNamespace); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, giteaNamespace.Name)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// giteaHelmRelease returns the release of Gitea standing in for the Gitea operator on Kubernetes,
+// a single pod keeping its data in SQLite as the workshops need no more
+func giteaHelmRelease(workshop *workshopv1.Workshop, appsHostnameSuffix string) helmRelease {
+	return newHelmRelease(GITEAHELMRELEASENAME, util.ScopedName(workshop, GITEANAMESPACENAME), workshop.Spec.Infrastructure.Gitea.Helm,
+		GITEAHELMREPOSITORY, GITEAHELMCHART, map[string]interface{}{
+			"gitea": map[string]interface{}{
+				"admin": map[string]interface{}{
+					"existingSecret": GITEAADMINSECRETNAME,
+				},
+				"config": map[string]interface{}{
+					"database": map[string]interface{}{"DB_TYPE": "sqlite3"},
+					"session":  map[string]interface{}{"PROVIDER": "memory"},
+					"cache":    map[string]interface{}{"ADAPTER": "memory"},
+					"queue":    map[string]interface{}{"TYPE": "level"},
+					"service":  map[string]interface{}{"DISABLE_REGISTRATION": true},
+					"server":   map[string]interface{}{"ROOT_URL": giteaRouteURL(workshop, appsHostnameSuffix) + "/"},
+				},
+			},
+			"postgresql":    map[string]interface{}{"enabled": false},
+			"postgresql-ha": map[string]interface{}{"enabled": false},
+			"redis-cluster": map[string]interface{}{"enabled": false},
+		})
+}
@@ -3,18 +3,18 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"sync"
+
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	argocdoperatorv1 "github.com/argoproj-labs/argocd-operator/pkg/apis/argoproj/v1alpha1"
-	argocdv1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/argocd"
 	"github.com/stakater/workshop-operator/common/kubernetes"
-	"golang.org/x/crypto/bcrypt"
 	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
 
 	"github.com/stakater/workshop-operator/common/util"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -36,18 +36,24 @@ const (
 	ARGOCD_CUSTOMRESOURCE_NAME       = "argocd"
 	ARGOCD_DEPLOYMENT_NAME           = "argocd-server"
 	ARGOCD_CONFIG_SECRET_NAME        = "argocd-default-cluster-config"
+	ARGOCD_RBAC_CONFIGMAP_NAME       = "argocd-rbac-cm"
+	ARGOCD_SERVICE_PORT              = 80
+	ARGOCD_APPLICATIONS_CRD_NAME     = "applications.argoproj.io"
+	GITOPS_HELM_RELEASE_NAME         = "argocd"
+	GITOPS_HELM_REPOSITORY           = "https://argoproj.github.io/argo-helm"
+	GITOPS_HELM_CHART                = "argo-cd"
 )
 
+// argocdApplicationControllerUser returns the user of the Argo CD application controller of the Workshop
+func argocdApplicationControllerUser(workshop *workshopv1.Workshop) string {
+	return "system:serviceaccount:" + util.ScopedName(workshop, ARGOCD_NAMESPACE_NAME) + ":argocd-argocd-application-controller"
+}
+
 // Reconciling GitOps
-func (r *WorkshopReconciler) reconcileGitOps(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) reconcileGitOps(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
-
-	enabledGitOps := workshop.Spec.Infrastructure.GitOps.Enabled
-
-	if enabledGitOps {
-		if result, err := r.addGitOps(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addGitOps(ctx, workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -55,61 +61,56 @@ func (r *WorkshopReconciler) reconcileGitOps(workshop *workshopv1.Workshop, user
 }
 
 // Add GitOps
-func (r *WorkshopReconciler) addGitOps(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) addGitOps(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
-	log.Infoln("Creating GitOps ")
-	channel := workshop.Spec.Infrastructure.GitOps.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.GitOps.OperatorHub.ClusterServiceVersion
+	log := util.Logger(ctx)
+	argocdNamespaceName := util.ScopedName(workshop, ARGOCD_NAMESPACE_NAME)
+	log.Info("Creating GitOps")
+	operatorHub := workshop.Spec.Infrastructure.GitOps.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, GITOPS_OPERATOR_NAMESPACE_NAME)
 
 	labels := map[string]string{
 		"app.kubernetes.io/part-of": "argocd",
 	}
 
-	// Create subscription
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, GITOPS_SUBSCRIPTION_NAME, GITOPS_OPERATOR_NAMESPACE_NAME,
-		GITOPS_SUBSCRIPTION_PACKAGE_NAME, channel, clusterServiceVersion)
-	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s  Subscription", subscription.Name)
-	}
+	// Argo CD is installed with its chart on Kubernetes, once its configuration exists
+	if r.isOpenShift() {
+		// Create subscription
+		subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, GITOPS_SUBSCRIPTION_NAME, subscriptionNamespace,
+			GITOPS_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+		if err := r.applyShared(ctx, workshop, subscription); err != nil {
+			return reconcile.Result{}, err
+		}
 
-	// Approve the installation
-	if err := r.ApproveInstallPlan(clusterServiceVersion, GITOPS_SUBSCRIPTION_NAME, GITOPS_OPERATOR_NAMESPACE_NAME); err != nil {
-		log.Infof("Waiting for  Subscription to create InstallPlan for %s", GITOPS_SUBSCRIPTION_NAME)
-		return reconcile.Result{Requeue: true}, nil
-	}
+		// Approve the installation
+		if result, err := r.ApproveInstallPlan(ctx, workshop, "GitOps", operatorHub.ClusterServiceVersion, GITOPS_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+			return result, err
+		}
 
-	// Wait for Operator to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(GITOPS_DEPLOYMENT_NAME, GITOPS_OPERATOR_NAMESPACE_NAME) {
-		return reconcile.Result{Requeue: true}, nil
+		// Wait for Operator to be running
+		if result, err := kubernetes.CheckDeploymentReady(ctx, r, GITOPS_DEPLOYMENT_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+			return result, err
+		}
 	}
 
 	// Create a Project
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, ARGOCD_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s  Project", namespace.Name)
-	}
-
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(workshop.Spec.UserDetails.DefaultPassword), bcrypt.DefaultCost)
-	if err != nil {
-		log.Errorf("Error when Bcrypt encrypt password for Argo CD: %v", err)
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, argocdNamespaceName)
+	if err := r.apply(ctx, namespace); err != nil {
 		return reconcile.Result{}, err
 	}
-	bcryptPassword := string(hashedPassword)
 
 	argocdPolicy := ""
 	namespaceList := ""
-	secretData := map[string]string{}
 	configMapData := map[string]string{}
+	// projectPolicies holds the policy of the AppProject of each user, with the policies of the users before
+	projectPolicies := map[string]string{}
 
-	for id := 1; id <= users; id++ {
-		username := fmt.Sprintf("user%d", id)
+	for _, user := range users {
+		username := user.Username
 		userRole := fmt.Sprintf("role:%s", username)
-		projectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
-		if id == 1 {
+		projectName := util.ProjectName(workshop, username)
+
+		if namespaceList == "" {
 			namespaceList = projectName
 		} else {
 			namespaceList = fmt.Sprintf("%s,%s", namespaceList, projectName)
@@ -118,150 +119,139 @@ func (r *WorkshopReconciler) addGitOps(workshop *workshopv1.Workshop, users int,
 		userPolicy := `p, ` + userRole + `, applications, *, ` + projectName + `/*, allow
 p, ` + userRole + `, clusters, get, https://kubernetes.default.svc, allow
 p, ` + userRole + `, projects, *,` + projectName + `, allow
-p, ` + userRole + `, repositories, *, http://gitea-server.gitea.svc:3000/` + username + `/*, allow
+p, ` + userRole + `, repositories, *, ` + giteaServiceURL(workshop) + `/` + username + `/*, allow
 g, ` + username + `, ` + userRole + `
 `
 		argocdPolicy = fmt.Sprintf("%s%s", argocdPolicy, userPolicy)
-
-		secretData[fmt.Sprintf("accounts.%s.password", username)] = bcryptPassword
+		projectPolicies[username] = argocdPolicy
 
 		configMapData[fmt.Sprintf("accounts.%s", username)] = "login"
+	}
 
-		labels["app.kubernetes.io/name"] = "appproject-cr"
-		appProjectCustomResource := argocd.NewAppProjectCustomResource(workshop, r.Scheme, projectName, ARGOCD_NAMESPACE_NAME, labels, argocdPolicy)
-		if err := r.Create(context.TODO(), appProjectCustomResource); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s  Custom Resource", appProjectCustomResource.Name)
-		} else if errors.IsAlreadyExists(err) {
-			customResourceFound := &argocdv1.AppProject{}
-			if err := r.Get(context.TODO(), types.NamespacedName{Name: appProjectCustomResource.Name, Namespace: ARGOCD_NAMESPACE_NAME}, customResourceFound); err != nil {
-				return reconcile.Result{}, err
-			} else if err == nil {
-				if !reflect.DeepEqual(appProjectCustomResource.Spec, customResourceFound.Spec) {
-					customResourceFound.Spec = appProjectCustomResource.Spec
-					if err := r.Update(context.TODO(), customResourceFound); err != nil {
-						return reconcile.Result{}, err
-					}
-					log.Infof("Updated %s  Custom Resource", customResourceFound.Name)
-				}
+	if !r.isOpenShift() {
+		if result, err := r.addKubernetesArgoCD(ctx, workshop, argocdNamespaceName, labels, configMapData, argocdPolicy); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	// The hashes of the passwords in the Secret are kept as long as they match
+	secretFound := &corev1.Secret{}
+	if err := kubernetes.GetObject(ctx, r, ARGOCD_SECRET_NAME, argocdNamespaceName, secretFound); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+
+	// The Secret needs the passwords of all the users, so they are all provisioned on every reconciliation
+	var secretDataLock sync.Mutex
+	secretData := map[string]string{}
+	provisionErr := provisionUsers(ctx, workshop, "GitOps", users, false, func(ctx context.Context, user util.Attendee, attendee *workshopv1.AttendeeStatus) error {
+		username := user.Username
+		projectName := util.ProjectName(workshop, username)
+
+		password, err := r.userPassword(ctx, workshop, username)
+		if err != nil {
+			return err
+		}
+		key := fmt.Sprintf("accounts.%s.password", username)
+		hashedPassword := string(secretFound.Data[key])
+		if !r.passwords.Matches(workshop.UID, username, hashedPassword, password) {
+			if hashedPassword, err = r.passwords.Hash(workshop.UID, username, password); err != nil {
+				util.Logger(ctx).Error(err, "Failed to hash the Argo CD password")
+				return err
 			}
 		}
+		secretDataLock.Lock()
+		secretData[key] = hashedPassword
+		secretDataLock.Unlock()
+
+		userLabels := map[string]string{
+			"app.kubernetes.io/part-of": "argocd",
+			"app.kubernetes.io/name":    "appproject-cr",
+		}
+		appProjectCustomResource := argocd.NewAppProjectCustomResource(workshop, r.Scheme, projectName, argocdNamespaceName, userLabels, projectPolicies[username])
+		if err := r.apply(ctx, appProjectCustomResource); err != nil {
+			return err
+		}
 
 		subjects := []rbac.Subject{}
 		argocdSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
-			Name:     "system:serviceaccount:argocd:argocd-argocd-application-controller",
+			Name:     argocdApplicationControllerUser(workshop),
 			APIGroup: "rbac.authorization.k8s.io",
 		}
 
 		subjects = append(subjects, argocdSubject)
 
 		role := kubernetes.NewRole(workshop, r.Scheme,
-			ARGOCD_ROLE_NAME, projectName, labels, kubernetes.ArgoCDRules())
-		if err := r.Create(context.TODO(), role); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s  Role in %s namespace", role.Name, projectName)
+			ARGOCD_ROLE_NAME, projectName, userLabels, kubernetes.ArgoCDRules())
+		if err := r.apply(ctx, role); err != nil {
+			return err
 		}
 
-		roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, ARGOCD_ROLE_BINDING_NAME, projectName, labels, subjects, role.Name, ARGOCD_ROLE_KIND_NAME)
-		if err := r.Create(context.TODO(), roleBinding); err != nil && !errors.IsAlreadyExists(err) {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			log.Infof("Created %s  Role Binding in %s namespace", roleBinding.Name, projectName)
-		} else if errors.IsAlreadyExists(err) {
-			found := &rbac.RoleBinding{}
-			if err := r.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: projectName}, found); err != nil {
-				return reconcile.Result{}, err
-			} else if err == nil {
-				if !reflect.DeepEqual(subjects, found.Subjects) {
-					found.Subjects = subjects
-					if err := r.Update(context.TODO(), found); err != nil {
-						return reconcile.Result{}, err
-					}
-					log.Infof("Updated %s  Role Binding in %s namespace", found.Name, projectName)
-				}
-			}
+		roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, ARGOCD_ROLE_BINDING_NAME, projectName, userLabels, subjects, role.Name, ARGOCD_ROLE_KIND_NAME)
+		if err := r.apply(ctx, roleBinding); err != nil {
+			return err
+		}
+		return nil
+	})
+
+	// The users who failed before their password was hashed keep the hash they had
+	for _, user := range users {
+		key := fmt.Sprintf("accounts.%s.password", user.Username)
+		if _, found := secretData[key]; !found && len(secretFound.Data[key]) > 0 {
+			secretData[key] = string(secretFound.Data[key])
 		}
 	}
 
+	// Only the hashes of the changed passwords differ from the Secret
 	labels["app.kubernetes.io/name"] = "argocd-secret"
-	secret := kubernetes.NewStringDataSecret(workshop, r.Scheme, ARGOCD_SECRET_NAME, ARGOCD_NAMESPACE_NAME, labels, secretData)
-	if err := r.Create(context.TODO(), secret); err != nil && !errors.IsAlreadyExists(err) {
+	secret := kubernetes.NewDataSecret(workshop, r.Scheme, ARGOCD_SECRET_NAME, argocdNamespaceName, labels, secretData)
+	if err := r.apply(ctx, secret); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s  Secret", secret.Name)
-		// } else if errors.IsAlreadyExists(err) {
-		// 	secretFound := &corev1.Secret{}
-		// 	if err := r.Get(context.TODO(), types.NamespacedName{Name: secret.Name, Namespace: namespace.Name}, secretFound); err != nil {
-		// 		return reconcile.Result{}, err
-		// 	} else if err == nil {
-		// 		if !util.IsIntersectMap(secretData, secretFound.StringData) {
-		// 			secretFound.StringData = secretData
-		// 			if err := r.Update(context.TODO(), secretFound); err != nil {
-		// 				return reconcile.Result{}, err
-		// 			}
-		// 			log.Infof("Updated %s Secret", secretFound.Name)
-		// 		}
-		// 	}
+	}
+
+	// The users have an account once the Secret holds their password
+	for _, user := range users {
+		_, found := secretData[fmt.Sprintf("accounts.%s.password", user.Username)]
+		attendeeStatus(workshop, user.Username).ArgoCDAccount = found
+	}
+	if provisionErr != nil {
+		return reconcile.Result{}, provisionErr
 	}
 
 	labels["app.kubernetes.io/name"] = "argocd-cm"
-	configmap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_CONFIGMAP_NAME, ARGOCD_NAMESPACE_NAME, labels, configMapData)
-	if err := r.Create(context.TODO(), configmap); err != nil && !errors.IsAlreadyExists(err) {
+	configmap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_CONFIGMAP_NAME, argocdNamespaceName, labels, configMapData)
+	if err := r.apply(ctx, configmap); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s  ConfigMap", configmap.Name)
-	} else if errors.IsAlreadyExists(err) {
-		configmapFound := &corev1.ConfigMap{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: configmap.Name, Namespace: namespace.Name}, configmapFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			if !util.IsIntersectMap(configMapData, configmapFound.Data) {
-				configmapFound.Data = configMapData
-				if err := r.Update(context.TODO(), configmapFound); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s  ConfigMap", configmapFound.Name)
-			}
-		}
 	}
 
-	labels["app.kubernetes.io/name"] = "argocd-cr"
-	argoCDCustomResource := argocd.NewArgoCDCustomResource(workshop, r.Scheme, ARGOCD_CUSTOMRESOURCE_NAME, ARGOCD_NAMESPACE_NAME, labels, argocdPolicy)
-	if err := r.Create(context.TODO(), argoCDCustomResource); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s  Custom Resource", argoCDCustomResource.Name)
-	} else if errors.IsAlreadyExists(err) {
-		customResourceFound := &argocdoperatorv1.ArgoCD{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: argoCDCustomResource.Name, Namespace: namespace.Name}, customResourceFound); err != nil {
+	if r.isOpenShift() {
+		labels["app.kubernetes.io/name"] = "argocd-cr"
+		argoCDCustomResource := argocd.NewArgoCDCustomResource(workshop, r.Scheme, ARGOCD_CUSTOMRESOURCE_NAME, argocdNamespaceName, labels, argocdPolicy)
+		if err := r.apply(ctx, argoCDCustomResource); err != nil {
+			return reconcile.Result{}, err
+		}
+	} else {
+		labels["app.kubernetes.io/name"] = "argocd-server"
+		argocdRoute := r.newSecuredRoute(workshop, ARGOCD_DEPLOYMENT_NAME, argocdNamespaceName, labels, ARGOCD_DEPLOYMENT_NAME,
+			ARGOCD_SERVICE_PORT, appsHostnameSuffix)
+		if err := r.apply(ctx, argocdRoute); err != nil {
 			return reconcile.Result{}, err
-		} else if err == nil {
-			if !reflect.DeepEqual(&argocdPolicy, customResourceFound.Spec.RBAC.Policy) {
-				customResourceFound.Spec.RBAC.Policy = &argocdPolicy
-				if err := r.Update(context.TODO(), customResourceFound); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s  Custom Resource", customResourceFound.Name)
-			}
 		}
 	}
 
 	// Wait for ArgoCD Dex Server to be running
-	// if !kubernetes.GetK8Client().GetDeploymentStatus("argocd-dex-server", namespace.Name) {
-	// 	return reconcile.Result{Requeue: true}, nil
+	// if result, err := kubernetes.CheckDeploymentReady(ctx, r, "argocd-dex-server", namespace.Name); util.IsRequeued(result, err) {
+	// 	return result, err
 	// }
 
 	// Wait for ArgoCD Server to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(ARGOCD_DEPLOYMENT_NAME, namespace.Name) {
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := kubernetes.CheckDeploymentReady(ctx, r, ARGOCD_DEPLOYMENT_NAME, namespace.Name); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	labels["app.kubernetes.io/name"] = "argocd-default-cluster-config"
 
-	if result, err := r.manageArgocdDefaultClusterConfigSecret(workshop, namespace.Name, labels, namespaceList); util.IsRequeued(result, err) {
+	if result, err := r.manageArgocdDefaultClusterConfigSecret(ctx, workshop, namespace.Name, labels, namespaceList); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -269,7 +259,7 @@ g, ` + username + `, ` + userRole + `
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(workshop *workshopv1.Workshop, namespaceName string,
+func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(ctx context.Context, workshop *workshopv1.Workshop, namespaceName string,
 	labels map[string]string, namespaceList string) (reconcile.Result, error) {
 
 	clusterConfigSecretData := map[string]string{}
@@ -277,25 +267,12 @@ func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(workshop *wo
 	clusterConfigSecretData["name"] = "in-cluster"
 	clusterConfigSecretData["namespaces"] = namespaceList
 	clusterConfigSecretData["server"] = "https://kubernetes.default.svc"
+	// Argo CD only reads the clusters from the labelled Secrets
+	labels["argocd.argoproj.io/secret-type"] = "cluster"
 
 	clusterConfigSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, ARGOCD_CONFIG_SECRET_NAME, namespaceName, labels, clusterConfigSecretData)
-	if err := r.Create(context.TODO(), clusterConfigSecret); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, clusterConfigSecret); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Argocd Secret", clusterConfigSecret.Name)
-	} else if errors.IsAlreadyExists(err) {
-		clusterConfigSecretFound := &corev1.Secret{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: clusterConfigSecret.Name, Namespace: namespaceName}, clusterConfigSecretFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			if !util.IsIntersectMap(clusterConfigSecretData, clusterConfigSecretFound.StringData) {
-				clusterConfigSecretFound.StringData = clusterConfigSecretData
-				if err := r.Update(context.TODO(), clusterConfigSecretFound); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s Argocd Secret", clusterConfigSecretFound.Name)
-			}
-		}
 	}
 
 	//Success
@@ -303,59 +280,73 @@ func (r *WorkshopReconciler) manageArgocdDefaultClusterConfigSecret(workshop *wo
 }
 
 // delete GitOps
-func (r *WorkshopReconciler) deleteGitOps(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) deleteGitOps(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
-	log.Infoln("Deleting GitOps ")
-	channel := workshop.Spec.Infrastructure.GitOps.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.GitOps.OperatorHub.ClusterServiceVersion
+	log := util.Logger(ctx)
+	argocdNamespaceName := util.ScopedName(workshop, ARGOCD_NAMESPACE_NAME)
+	log.Info("Deleting GitOps")
+	operatorHub := workshop.Spec.Infrastructure.GitOps.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, GITOPS_OPERATOR_NAMESPACE_NAME)
 	labels := map[string]string{
 		"app.kubernetes.io/part-of": "argocd",
 	}
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(workshop.Spec.UserDetails.DefaultPassword), bcrypt.DefaultCost)
-	if err != nil {
-		log.Errorf("Error when Bcrypt encrypt password for Argo CD: %v", err)
-		return reconcile.Result{}, err
-	}
-	bcryptPassword := string(hashedPassword)
-
 	argocdPolicy := ""
 	namespaceList := ""
 	secretData := map[string]string{}
 	configMapData := map[string]string{}
 
-	if result, err := r.deleteArgocdDefaultClusterConfigSecret(workshop, ARGOCD_NAMESPACE_NAME, labels, namespaceList); util.IsRequeued(result, err) {
+	if result, err := r.deleteArgocdDefaultClusterConfigSecret(ctx, workshop, argocdNamespaceName, labels, namespaceList); util.IsRequeued(result, err) {
 		return result, err
 	}
 
-	labels["app.kubernetes.io/name"] = "argocd-cr"
-	argoCDCustomResource := argocd.NewArgoCDCustomResource(workshop, r.Scheme, ARGOCD_CUSTOMRESOURCE_NAME, ARGOCD_NAMESPACE_NAME, labels, argocdPolicy)
-	// Delete argoCD Custom Resource
-	if err := r.Delete(context.TODO(), argoCDCustomResource); err != nil {
-		return reconcile.Result{}, err
+	if r.isOpenShift() {
+		labels["app.kubernetes.io/name"] = "argocd-cr"
+		argoCDCustomResource := argocd.NewArgoCDCustomResource(workshop, r.Scheme, ARGOCD_CUSTOMRESOURCE_NAME, argocdNamespaceName, labels, argocdPolicy)
+		// Delete argoCD Custom Resource
+		if err := r.Delete(ctx, argoCDCustomResource); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "ArgoCD", util.LogName, argoCDCustomResource.Name)
+	} else {
+		labels["app.kubernetes.io/name"] = "argocd-server"
+		argocdRoute := r.newSecuredRoute(workshop, ARGOCD_DEPLOYMENT_NAME, argocdNamespaceName, labels, ARGOCD_DEPLOYMENT_NAME,
+			ARGOCD_SERVICE_PORT, appsHostnameSuffix)
+		// Delete Ingress
+		if err := r.Delete(ctx, argocdRoute); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Ingress", util.LogName, ARGOCD_DEPLOYMENT_NAME)
+
+		labels["app.kubernetes.io/name"] = "argocd-rbac-cm"
+		rbacConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_RBAC_CONFIGMAP_NAME, argocdNamespaceName, labels, nil)
+		// Delete RBAC Configmap
+		if err := r.Delete(ctx, rbacConfigMap); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "ConfigMap", util.LogName, rbacConfigMap.Name)
 	}
-	log.Infof("Deleted %s  Custom Resource", argoCDCustomResource.Name)
 
 	labels["app.kubernetes.io/name"] = "argocd-cm"
-	configmap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_CONFIGMAP_NAME, ARGOCD_NAMESPACE_NAME, labels, configMapData)
+	configmap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_CONFIGMAP_NAME, argocdNamespaceName, labels, configMapData)
 	// Delete Configmap
-	if err := r.Delete(context.TODO(), configmap); err != nil {
+	if err := r.Delete(ctx, configmap); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  Configmap", configmap.Name)
+	log.Info("Deleted", util.LogKind, "ConfigMap", util.LogName, configmap.Name)
 
 	labels["app.kubernetes.io/name"] = "argocd-secret"
-	secret := kubernetes.NewStringDataSecret(workshop, r.Scheme, ARGOCD_SECRET_NAME, ARGOCD_NAMESPACE_NAME, labels, secretData)
+	secret := kubernetes.NewDataSecret(workshop, r.Scheme, ARGOCD_SECRET_NAME, argocdNamespaceName, labels, secretData)
 	// Delete Secret
-	if err := r.Delete(context.TODO(), secret); err != nil {
+	if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  Secret", secret.Name)
+	log.Info("Deleted", util.LogKind, "Secret", util.LogName, secret.Name)
 
-	for id := 1; id <= users; id++ {
-		username := fmt.Sprintf("user%d", id)
+	for _, user := range users {
+		username := user.Username
 		userRole := fmt.Sprintf("role:%s", username)
-		projectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
-		if id == 1 {
+		projectName := util.ProjectName(workshop, username)
+		if namespaceList == "" {
 			namespaceList = projectName
 		} else {
 			namespaceList = fmt.Sprintf("%s,%s", namespaceList, projectName)
@@ -364,19 +355,17 @@ func (r *WorkshopReconciler) deleteGitOps(workshop *workshopv1.Workshop, users i
 		userPolicy := `p, ` + userRole + `, applications, *, ` + projectName + `/*, allow
 p, ` + userRole + `, clusters, get, https://kubernetes.default.svc, allow
 p, ` + userRole + `, projects, *,` + projectName + `, allow
-p, ` + userRole + `, repositories, *, http://gitea-server.gitea.svc:3000/` + username + `/*, allow
+p, ` + userRole + `, repositories, *, ` + giteaServiceURL(workshop) + `/` + username + `/*, allow
 g, ` + username + `, ` + userRole + `
 `
 		argocdPolicy = fmt.Sprintf("%s%s", argocdPolicy, userPolicy)
 
-		secretData[fmt.Sprintf("accounts.%s.password", username)] = bcryptPassword
-
 		configMapData[fmt.Sprintf("accounts.%s", username)] = "login"
 
 		subjects := []rbac.Subject{}
 		argocdSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
-			Name:     "system:serviceaccount:argocd:argocd-argocd-application-controller",
+			Name:     argocdApplicationControllerUser(workshop),
 			APIGroup: "rbac.authorization.k8s.io",
 		}
 
@@ -386,72 +375,173 @@ g, ` + username + `, ` + userRole + `
 
 		roleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, ARGOCD_ROLE_BINDING_NAME, projectName, labels, subjects, role.Name, ARGOCD_ROLE_KIND_NAME)
 		// Delete roleBinding
-		if err := r.Delete(context.TODO(), roleBinding); err != nil {
+		if err := r.Delete(ctx, roleBinding); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s  Role Binding  in %s namespace", roleBinding.Name, projectName)
+		log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, roleBinding.Name, util.LogNamespace, projectName)
 
 		// Delete role
-		if err := r.Delete(context.TODO(), role); err != nil {
+		if err := r.Delete(ctx, role); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s  role in %s namespace ", role.Name, projectName)
+		log.Info("Deleted", util.LogKind, "Role", util.LogName, role.Name, util.LogNamespace, projectName)
 
 		labels["app.kubernetes.io/name"] = "appproject-cr"
-		appProjectCustomResource := argocd.NewAppProjectCustomResource(workshop, r.Scheme, projectName, ARGOCD_NAMESPACE_NAME, labels, argocdPolicy)
-		// Delete appProject Custom Resource
-		if err := r.Delete(context.TODO(), appProjectCustomResource); err != nil {
+		appProjectCustomResource := argocd.NewAppProjectCustomResource(workshop, r.Scheme, projectName, argocdNamespaceName, labels, argocdPolicy)
+		// Delete appProject Custom Resource, whose CRD is gone if Argo CD was never installed
+		if err := r.Delete(ctx, appProjectCustomResource); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s  appProject Custom Resource ", appProjectCustomResource.Name)
+		log.Info("Deleted", util.LogKind, "AppProject", util.LogName, appProjectCustomResource.Name)
 	}
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, GITOPS_SUBSCRIPTION_NAME, GITOPS_OPERATOR_NAMESPACE_NAME,
-		GITOPS_SUBSCRIPTION_PACKAGE_NAME, channel, clusterServiceVersion)
-	gitopsCSV := subscription.Spec.StartingCSV
-	// Delete subscription
-	if err := r.Delete(context.TODO(), subscription); err != nil {
-		return reconcile.Result{}, err
+	// The release of Argo CD belongs to the Workshop on Kubernetes
+	if !r.isOpenShift() {
+		if result, err := r.uninstallHelmRelease(ctx, workshop, gitOpsHelmRelease(workshop, false)); util.IsRequeued(result, err) {
+			return result, err
+		}
 	}
-	log.Infof("Deleted %s  Subscription", subscription.Name)
 
-	operatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, gitopsCSV, GITOPS_OPERATOR_NAMESPACE_NAME)
-	if err := r.Delete(context.TODO(), operatorCSV); err != nil {
+	// The operator is shared by the Workshops of the cluster
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "GitOps")
+	})
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  ClusterServiceVersion", operatorCSV.Name)
+	if !isUsed && r.isOpenShift() {
+		subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, GITOPS_SUBSCRIPTION_NAME, subscriptionNamespace,
+			GITOPS_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+		gitopsCSV := subscription.Spec.StartingCSV
+		// Delete subscription
+		if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
 
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, ARGOCD_NAMESPACE_NAME)
+		operatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, gitopsCSV, subscriptionNamespace)
+		if err := r.Delete(ctx, operatorCSV); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "ClusterServiceVersion", util.LogName, operatorCSV.Name)
+	}
+
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, argocdNamespaceName)
 	// Delete a Project
-	if err := r.Delete(context.TODO(), namespace); err != nil {
+	if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleting %s  Project", namespace.Name)
-	namespaceFound := kubernetes.NewNamespace(workshop, r.Scheme, ARGOCD_NAMESPACE_NAME)
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: ARGOCD_NAMESPACE_NAME}, namespaceFound); err != nil {
+	log.Info("Deleting", util.LogKind, "Project", util.LogName, namespace.Name)
+	namespaceFound := kubernetes.NewNamespace(workshop, r.Scheme, argocdNamespaceName)
+	if err := r.Get(ctx, types.NamespacedName{Name: argocdNamespaceName}, namespaceFound); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
 		return reconcile.Result{}, err
 	}
 
-	if namespaceFound.Spec.Finalizers[0] == "kubernetes" {
+	if r.isOpenShift() && len(namespaceFound.Spec.Finalizers) > 0 && namespaceFound.Spec.Finalizers[0] == "kubernetes" {
 		argoCD := &argocdoperatorv1.ArgoCD{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: ARGOCD_CUSTOMRESOURCE_NAME, Namespace: ARGOCD_NAMESPACE_NAME}, argoCD); err != nil {
+		if err := r.Get(ctx, types.NamespacedName{Name: ARGOCD_CUSTOMRESOURCE_NAME, Namespace: argocdNamespaceName}, argoCD); err != nil {
+			if errors.IsNotFound(err) {
+				return reconcile.Result{}, nil
+			}
 			return reconcile.Result{}, err
 		}
 
 		patch := client.MergeFrom(argoCD.DeepCopy())
 		argoCD.Finalizers = nil
-		if err := r.Patch(context.TODO(), argoCD, patch); err != nil {
+		if err := r.Patch(ctx, argoCD, patch); err != nil {
 			return reconcile.Result{}, err
 		}
 	}
-	log.Infof("Deleted %s  Project", namespace.Name)
+	log.Info("Deleted", util.LogKind, "Project", util.LogName, namespace.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) deleteArgocdDefaultClusterConfigSecret(workshop *workshopv1.Workshop, namespaceName string,
+// addKubernetesArgoCD configures Argo CD as the ArgoCD custom resource does on OpenShift, then installs it with its chart
+func (r *WorkshopReconciler) addKubernetesArgoCD(ctx context.Context, workshop *workshopv1.Workshop, namespaceName string,
+	labels map[string]string, configMapData map[string]string, argocdPolicy string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	// Argo CD needs its Secret to start, the passwords of the users are added to it later
+	labels["app.kubernetes.io/name"] = "argocd-secret"
+	secret := kubernetes.NewDataSecret(workshop, r.Scheme, ARGOCD_SECRET_NAME, namespaceName, labels, nil)
+	if err := r.Create(ctx, secret); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Info("Created", util.LogKind, "Secret", util.LogName, secret.Name)
+	}
+
+	labels["app.kubernetes.io/name"] = "argocd-cm"
+	configmap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_CONFIGMAP_NAME, namespaceName, labels, configMapData)
+	if err := r.apply(ctx, configmap); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	labels["app.kubernetes.io/name"] = "argocd-rbac-cm"
+	rbacConfigMap := kubernetes.NewConfigMap(workshop, r.Scheme, ARGOCD_RBAC_CONFIGMAP_NAME, namespaceName, labels, map[string]string{
+		"policy.csv":     argocdPolicy,
+		"policy.default": "",
+		"scopes":         "[preferred_username]",
+	})
+	if err := r.apply(ctx, rbacConfigMap); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The CRDs of Argo CD are shared by the Workshops, the first release installs them
+	installCRDs, err := r.isArgoCDCRDsOwner(ctx, namespaceName)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	return r.installHelmRelease(ctx, workshop, gitOpsHelmRelease(workshop, installCRDs))
+}
+
+// isArgoCDCRDsOwner returns true if the CRDs of Argo CD are missing or installed by the release of the namespace
+func (r *WorkshopReconciler) isArgoCDCRDsOwner(ctx context.Context, namespaceName string) (bool, error) {
+	crd := &apiextensionsv1beta1.CustomResourceDefinition{}
+	if err := r.Get(ctx, types.NamespacedName{Name: ARGOCD_APPLICATIONS_CRD_NAME}, crd); err != nil {
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}
+	return crd.Annotations["meta.helm.sh/release-name"] == GITOPS_HELM_RELEASE_NAME &&
+		crd.Annotations["meta.helm.sh/release-namespace"] == namespaceName, nil
+}
+
+// gitOpsHelmRelease returns the release of Argo CD standing in for the ArgoCD custom resource on Kubernetes.
+// The Workshop manages the configuration of Argo CD, and the application controller only has the permissions
+// of the argocd-manager Roles in the projects of the users.
+func gitOpsHelmRelease(workshop *workshopv1.Workshop, installCRDs bool) helmRelease {
+	return newHelmRelease(GITOPS_HELM_RELEASE_NAME, util.ScopedName(workshop, ARGOCD_NAMESPACE_NAME), workshop.Spec.Infrastructure.GitOps.Helm,
+		GITOPS_HELM_REPOSITORY, GITOPS_HELM_CHART, map[string]interface{}{
+			"fullnameOverride":   GITOPS_HELM_RELEASE_NAME,
+			"createClusterRoles": false,
+			"crds": map[string]interface{}{
+				"install": installCRDs,
+			},
+			"controller": map[string]interface{}{
+				"serviceAccount": map[string]interface{}{
+					"name": "argocd-argocd-application-controller",
+				},
+			},
+			"dex":           map[string]interface{}{"enabled": false},
+			"notifications": map[string]interface{}{"enabled": false},
+			"configs": map[string]interface{}{
+				"cm":     map[string]interface{}{"create": false},
+				"rbac":   map[string]interface{}{"create": false},
+				"secret": map[string]interface{}{"createSecret": false},
+				"params": map[string]interface{}{"server.insecure": true},
+			},
+		})
+}
+
+func (r *WorkshopReconciler) deleteArgocdDefaultClusterConfigSecret(ctx context.Context, workshop *workshopv1.Workshop, namespaceName string,
 	labels map[string]string, namespaceList string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
 	clusterConfigSecretData := map[string]string{}
 	clusterConfigSecretData["config"] = "{\"tlsClientConfig\":{\"insecure\":false}}"
@@ -462,10 +552,10 @@ func (r *WorkshopReconciler) deleteArgocdDefaultClusterConfigSecret(workshop *wo
 
 	clusterConfigSecret := kubernetes.NewStringDataSecret(workshop, r.Scheme, ARGOCD_CONFIG_SECRET_NAME, namespaceName, labels, clusterConfigSecretData)
 	// delete cluster Config Secret
-	if err := r.Delete(context.TODO(), clusterConfigSecret); err != nil {
+	if err := r.Delete(ctx, clusterConfigSecret); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  Secret", clusterConfigSecret.Name)
+	log.Info("Deleted", util.LogKind, "Secret", util.LogName, clusterConfigSecret.Name)
 	//Success
 	return reconcile.Result{}, nil
 }
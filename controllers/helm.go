@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/helm"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+const (
+	HELM_SERVICE_ACCOUNT_NAME = "helm"
+	HELM_CLUSTER_ROLE_NAME    = "cluster-admin"
+)
+
+// helmRelease is a Helm chart standing in on Kubernetes for an operator installed from OperatorHub on OpenShift
+type helmRelease struct {
+	name      string
+	namespace string
+	chart     workshopv1.HelmChartSpec
+	// defaults are the values set by the operator, the values of the chart spec are applied on top of them
+	defaults map[string]interface{}
+}
+
+// newHelmRelease returns a release of the chart of the spec, from the default repository and chart when the spec sets none
+func newHelmRelease(name string, namespace string, chart workshopv1.HelmChartSpec, repository string, chartName string,
+	defaults map[string]interface{}) helmRelease {
+	if chart.Chart == "" {
+		chart.Chart = chartName
+		if chart.Repository == "" {
+			chart.Repository = repository
+		}
+	}
+	return helmRelease{name: name, namespace: namespace, chart: chart, defaults: defaults}
+}
+
+// installHelmRelease installs or upgrades a release with a Job running Helm, and waits for the Job to complete.
+// The values ConfigMap marks the releases of the Workshops: a release installed by other means is used as it is,
+// and the release of another Workshop is only upgraded by that Workshop, or by the Workshop adopting it once it is gone.
+func (r *WorkshopReconciler) installHelmRelease(ctx context.Context, workshop *workshopv1.Workshop, release helmRelease) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues("release", release.name, util.LogNamespace, release.namespace)
+
+	if chartIsMissing(release.chart) {
+		return reconcile.Result{}, fmt.Errorf("the chart of the %s release is not set, it has no default", release.name)
+	}
+
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, release.namespace)
+	if err := r.applyShared(ctx, workshop, namespace); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	valuesConfigMap := &corev1.ConfigMap{}
+	err := r.Get(ctx, types.NamespacedName{Name: helm.ValuesConfigMapName(release.name), Namespace: release.namespace}, valuesConfigMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	if errors.IsNotFound(err) {
+		if installed, err := r.isHelmReleaseInstalled(ctx, release); err != nil {
+			return reconcile.Result{}, err
+		} else if installed {
+			log.V(util.LogDebug).Info("Not installed by a Workshop, left alone")
+			return reconcile.Result{}, nil
+		}
+	}
+
+	defaults, err := yaml.Marshal(release.defaults)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	labels := map[string]string{
+		helm.ReleaseLabel: release.name,
+	}
+	values := kubernetes.NewConfigMap(workshop, r.Scheme, helm.ValuesConfigMapName(release.name), release.namespace, labels,
+		map[string]string{
+			helm.DefaultsKey: string(defaults),
+			helm.ValuesKey:   release.chart.Values,
+		})
+	if err := r.applyShared(ctx, workshop, values); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.Get(ctx, types.NamespacedName{Name: values.Name, Namespace: values.Namespace}, valuesConfigMap); err != nil {
+		return reconcile.Result{}, err
+	}
+	if !util.IsOwnedBy(workshop, valuesConfigMap.Labels) {
+		// Another Workshop manages the release
+		return reconcile.Result{}, nil
+	}
+
+	if err := r.applyHelmServiceAccount(ctx, workshop, release); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	jobName := helm.InstallJobName(release.name, release.chart, string(defaults))
+	if err := r.deleteHelmJobs(ctx, release, jobName); err != nil {
+		return reconcile.Result{}, err
+	}
+	job := helm.NewInstallJob(workshop, r.Scheme, jobName, release.namespace, release.name, release.chart,
+		util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME))
+	return r.runHelmJob(ctx, job)
+}
+
+// uninstallHelmRelease uninstalls a release installed by the Workshop with a Job running Helm, then deletes the Job
+// and the resources running it. The callers check first that no other Workshop uses a release shared between Workshops.
+func (r *WorkshopReconciler) uninstallHelmRelease(ctx context.Context, workshop *workshopv1.Workshop, release helmRelease) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	valuesConfigMap := &corev1.ConfigMap{}
+	if err := r.Get(ctx, types.NamespacedName{Name: helm.ValuesConfigMapName(release.name), Namespace: release.namespace}, valuesConfigMap); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if !util.IsOwnedBy(workshop, valuesConfigMap.Labels) {
+		return reconcile.Result{}, nil
+	}
+
+	if installed, err := r.isHelmReleaseInstalled(ctx, release); err != nil {
+		return reconcile.Result{}, err
+	} else if installed {
+		// An installation still running would leave the release pending
+		if err := r.deleteHelmJobs(ctx, release, helm.UninstallJobName(release.name)); err != nil {
+			return reconcile.Result{}, err
+		}
+		if err := r.applyHelmServiceAccount(ctx, workshop, release); err != nil {
+			return reconcile.Result{}, err
+		}
+		job := helm.NewUninstallJob(workshop, r.Scheme, release.namespace, release.name, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME))
+		if result, err := r.runHelmJob(ctx, job); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	if err := r.deleteHelmJobs(ctx, release, ""); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.Delete(ctx, valuesConfigMap); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "ConfigMap", util.LogName, valuesConfigMap.Name, util.LogNamespace, valuesConfigMap.Namespace)
+
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME+"-"+release.name),
+		release.namespace, nil, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME), HELM_CLUSTER_ROLE_NAME, "ClusterRole")
+	if err := r.Delete(ctx, clusterRoleBinding); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "ClusterRoleBinding", util.LogName, clusterRoleBinding.Name)
+
+	// The Service Account and the namespace are shared with the other releases of the namespace
+	remaining := &corev1.ConfigMapList{}
+	if err := r.List(ctx, remaining, client.InNamespace(release.namespace), client.HasLabels{helm.ReleaseLabel}); err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, configMap := range remaining.Items {
+		// The cache may still hold the deleted ConfigMap
+		if configMap.Name != valuesConfigMap.Name {
+			return reconcile.Result{}, nil
+		}
+	}
+
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME), release.namespace, nil)
+	if err := r.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, serviceAccount.Name, util.LogNamespace, serviceAccount.Namespace)
+
+	// The namespace is only deleted if the Workshop created it
+	namespace := &corev1.Namespace{}
+	if err := r.Get(ctx, types.NamespacedName{Name: release.namespace}, namespace); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+	if util.IsOwnedBy(workshop, namespace.Labels) {
+		if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Namespace", util.LogName, namespace.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// handOverHelmReleases hands the releases of a component shared between Workshops over to another Workshop using the component,
+// when the Workshop is deleted: the values ConfigMaps and the namespaces it created take the ownership labels of the other Workshop,
+// which upgrades the releases from now on, and the resources running Helm for the Workshop are deleted.
+// The releases only exist on Kubernetes.
+func (r *WorkshopReconciler) handOverHelmReleases(ctx context.Context, workshop *workshopv1.Workshop, component string,
+	releases ...helmRelease) (reconcile.Result, error) {
+	return r.handOverHelmReleasesUsing(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, component)
+	}, releases...)
+}
+
+// handOverHelmReleasesUsing hands the releases over to another Workshop for which uses returns true
+func (r *WorkshopReconciler) handOverHelmReleasesUsing(ctx context.Context, workshop *workshopv1.Workshop, uses func(other *workshopv1.Workshop) bool,
+	releases ...helmRelease) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	if r.isOpenShift() {
+		return reconcile.Result{}, nil
+	}
+
+	other, err := r.otherWorkshopUsing(ctx, workshop, uses)
+	if err != nil || other == nil {
+		return reconcile.Result{}, err
+	}
+
+	for _, release := range releases {
+		valuesConfigMap := &corev1.ConfigMap{}
+		if err := r.Get(ctx, types.NamespacedName{Name: helm.ValuesConfigMapName(release.name), Namespace: release.namespace}, valuesConfigMap); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return reconcile.Result{}, err
+		}
+		if !util.IsOwnedBy(workshop, valuesConfigMap.Labels) {
+			continue
+		}
+
+		if err := r.deleteHelmJobs(ctx, release, ""); err != nil {
+			return reconcile.Result{}, err
+		}
+		clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME+"-"+release.name),
+			release.namespace, nil, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME), HELM_CLUSTER_ROLE_NAME, "ClusterRole")
+		if err := r.Delete(ctx, clusterRoleBinding); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME), release.namespace, nil)
+		if err := r.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+
+		patch := client.MergeFrom(valuesConfigMap.DeepCopy())
+		valuesConfigMap.Labels = util.WithOwnerLabels(other, valuesConfigMap.Labels)
+		if err := r.Patch(ctx, valuesConfigMap, patch); err != nil {
+			return reconcile.Result{}, err
+		}
+
+		namespace := &corev1.Namespace{}
+		if err := r.Get(ctx, types.NamespacedName{Name: release.namespace}, namespace); err != nil {
+			return reconcile.Result{}, err
+		}
+		if util.IsOwnedBy(workshop, namespace.Labels) {
+			patch := client.MergeFrom(namespace.DeepCopy())
+			namespace.Labels = util.WithOwnerLabels(other, namespace.Labels)
+			if err := r.Patch(ctx, namespace, patch); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		log.Info("Handed over", "release", release.name, util.LogNamespace, release.namespace, "workshop", other.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// applyHelmServiceAccount creates the Service Account running Helm in the namespace of the release, bound to cluster-admin
+// since the charts create cluster resources such as CRDs and webhooks
+func (r *WorkshopReconciler) applyHelmServiceAccount(ctx context.Context, workshop *workshopv1.Workshop, release helmRelease) error {
+	serviceAccountName := util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME)
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, serviceAccountName, release.namespace, nil)
+	if err := r.apply(ctx, serviceAccount); err != nil {
+		return err
+	}
+
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, HELM_SERVICE_ACCOUNT_NAME+"-"+release.name),
+		release.namespace, nil, serviceAccountName, HELM_CLUSTER_ROLE_NAME, "ClusterRole")
+	return r.apply(ctx, clusterRoleBinding)
+}
+
+// runHelmJob creates the Job if needed and returns a RequeueAfter result until it completes.
+// A failed Job is deleted so that the next reconciliation runs Helm again.
+func (r *WorkshopReconciler) runHelmJob(ctx context.Context, job *batchv1.Job) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogKind, "Job", util.LogName, job.Name, util.LogNamespace, job.Namespace)
+
+	found := &batchv1.Job{}
+	if err := r.Get(ctx, types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, found); errors.IsNotFound(err) {
+		if err := r.apply(ctx, job); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Running Helm")
+		return reconcile.Result{RequeueAfter: kubernetes.ReadinessRequeueAfter}, nil
+	} else if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	finished, failed := helm.IsJobFinished(found)
+	if !finished {
+		log.Info("Waiting for Helm")
+		return reconcile.Result{RequeueAfter: kubernetes.ReadinessRequeueAfter}, nil
+	}
+	if failed {
+		if err := r.Delete(ctx, found, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		return reconcile.Result{}, fmt.Errorf("helm failed in the %s Job of %s, it is run again", job.Name, job.Namespace)
+	}
+	return reconcile.Result{}, nil
+}
+
+// deleteHelmJobs deletes the Jobs of a release but the one named
+func (r *WorkshopReconciler) deleteHelmJobs(ctx context.Context, release helmRelease, keep string) error {
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(release.namespace), client.MatchingLabels{helm.ReleaseLabel: release.name}); err != nil {
+		return err
+	}
+	for i := range jobs.Items {
+		if jobs.Items[i].Name == keep {
+			continue
+		}
+		if err := r.Delete(ctx, &jobs.Items[i], client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// isHelmReleaseInstalled returns true if Helm stores a revision of the release
+func (r *WorkshopReconciler) isHelmReleaseInstalled(ctx context.Context, release helmRelease) (bool, error) {
+	secrets := &corev1.SecretList{}
+	if err := r.List(ctx, secrets, client.InNamespace(release.namespace), client.MatchingLabels(helm.ReleaseSecretLabels(release.name))); err != nil {
+		return false, err
+	}
+	return len(secrets.Items) > 0, nil
+}
+
+// chartIsMissing returns true for the charts without default which the spec doesn't set
+func chartIsMissing(chart workshopv1.HelmChartSpec) bool {
+	return chart.Chart == ""
+}
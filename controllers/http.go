@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// httpRequestTimeout bounds each call to the tools installed by the Workshops, response body included
+const httpRequestTimeout = 30 * time.Second
+
+// maxErrorBodySize bounds the part of the body of an error response kept in the error
+const maxErrorBodySize = 512
+
+// sharedTransport pools the connections to the tools installed by the Workshops.
+// The tools are served with the self-signed certificates of the cluster.
+var sharedTransport = &http.Transport{
+	Proxy:                 http.ProxyFromEnvironment,
+	TLSClientConfig:       &tls.Config{InsecureSkipVerify: true},
+	TLSHandshakeTimeout:   10 * time.Second,
+	ResponseHeaderTimeout: httpRequestTimeout,
+	MaxIdleConnsPerHost:   10,
+	IdleConnTimeout:       90 * time.Second,
+}
+
+// newHTTPClient returns a client of the shared transport for the calls of the Workshop to the target.
+// The client does not follow the redirects. The requests are made with the reconcile context,
+// so that they stop with the manager.
+func newHTTPClient(workshop *workshopv1.Workshop, target string) *http.Client {
+	return &http.Client{
+		Transport: instrumentTransport(workshop, target, sharedTransport),
+		Timeout:   httpRequestTimeout,
+		// Do not follow Redirect
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// unexpectedStatusError returns the error of a call to a tool which returned an unexpected status, with the start of the body
+func unexpectedStatusError(call string, httpResponse *http.Response) error {
+	body, _ := ioutil.ReadAll(io.LimitReader(httpResponse.Body, maxErrorBodySize))
+	return fmt.Errorf("%s returned %s: %s", call, httpResponse.Status, strings.TrimSpace(string(body)))
+}
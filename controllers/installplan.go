@@ -2,40 +2,201 @@ package controllers
 
 import (
 	"context"
-	"errors"
+	"fmt"
+	"strings"
+	"time"
 
+	"github.com/blang/semver"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	"github.com/prometheus/common/log"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/util"
 )
 
-// ApproveInstallPlan approves manually the install of a specific CSV
-func (r *WorkshopReconciler) ApproveInstallPlan(clusterServiceVersion string, subscriptionName string, namespace string) error {
+// Reasons of the operator conditions
+const (
+	ReasonSubscriptionPending = "SubscriptionPending"
+	ReasonInstallPlanPending  = "InstallPlanPending"
+	ReasonInstallPlanFailed   = "InstallPlanFailed"
+	ReasonUpgradeHeld         = "UpgradeHeld"
+	ReasonCSVInstalling       = "ClusterServiceVersionInstalling"
+	ReasonCSVReplacing        = "ClusterServiceVersionReplacing"
+	ReasonCSVFailed           = "ClusterServiceVersionFailed"
+	ReasonCSVSucceeded        = "ClusterServiceVersionSucceeded"
+)
+
+// OLMRequeueAfter is the delay before checking again an operator being installed by OLM
+const OLMRequeueAfter = 15 * time.Second
+
+// ApproveInstallPlan approves the InstallPlans of the Subscription leading to the ClusterServiceVersion,
+// the head of the channel when it is empty, and waits for the ClusterServiceVersion to succeed.
+// Bumping the pinned ClusterServiceVersion approves the upgrades up to it, never past it.
+// The <component>Operator condition reports the progress, a failed InstallPlan or ClusterServiceVersion is an error.
+func (r *WorkshopReconciler) ApproveInstallPlan(ctx context.Context, workshop *workshopv1.Workshop, component string, clusterServiceVersion string,
+	subscriptionName string, namespace string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogKind, "Subscription", util.LogName, subscriptionName, util.LogNamespace, namespace)
+	waiting := func(reason string, messageFmt string, args ...interface{}) (reconcile.Result, error) {
+		message := fmt.Sprintf(messageFmt, args...)
+		log.Info("Waiting for the operator", "reason", reason, "message", message)
+		setOperatorCondition(workshop, component, metav1.ConditionFalse, reason, message)
+		return reconcile.Result{RequeueAfter: OLMRequeueAfter}, nil
+	}
+	failed := func(reason string, messageFmt string, args ...interface{}) (reconcile.Result, error) {
+		message := fmt.Sprintf(messageFmt, args...)
+		setOperatorCondition(workshop, component, metav1.ConditionFalse, reason, message)
+		return reconcile.Result{}, fmt.Errorf("%s", message)
+	}
 
 	subscription := &olmv1alpha1.Subscription{}
-	if err := kubernetes.GetObject(r, subscriptionName, namespace, subscription); err != nil {
-		return err
+	if err := kubernetes.GetObject(ctx, r, subscriptionName, namespace, subscription); err != nil {
+		if errors.IsNotFound(err) {
+			return waiting(ReasonSubscriptionPending, "Subscription %s not found in %s", subscriptionName, namespace)
+		}
+		return reconcile.Result{}, err
+	}
+
+	// The latest version is the head of the channel resolved by OLM
+	target := clusterServiceVersion
+	if target == "" {
+		target = subscription.Status.CurrentCSV
+	}
+	if target == "" {
+		return waiting(ReasonSubscriptionPending, "Subscription %s has not resolved the channel %s yet", subscriptionName, subscription.Spec.Channel)
+	}
+
+	if subscription.Status.InstalledCSV == target {
+		return r.checkClusterServiceVersion(ctx, workshop, component, target, namespace, waiting, failed)
+	}
+
+	if subscription.Status.InstallPlanRef == nil {
+		r.warningEvent(workshop, EventInstallPlanPending, component, "",
+			"No InstallPlan yet for the %s Subscription in %s project", subscriptionName, namespace)
+		return waiting(ReasonInstallPlanPending, "No InstallPlan yet for the Subscription %s to %s", subscriptionName, target)
+	}
+
+	installPlan := &olmv1alpha1.InstallPlan{}
+	if err := kubernetes.GetObject(ctx, r, subscription.Status.InstallPlanRef.Name, namespace, installPlan); err != nil {
+		if errors.IsNotFound(err) {
+			return waiting(ReasonInstallPlanPending, "InstallPlan %s not found", subscription.Status.InstallPlanRef.Name)
+		}
+		return reconcile.Result{}, err
+	}
+
+	if installPlan.Status.Phase == olmv1alpha1.InstallPlanPhaseFailed {
+		return failed(ReasonInstallPlanFailed, "InstallPlan %s of %s failed: %s", installPlan.Name,
+			strings.Join(installPlan.Spec.ClusterServiceVersionNames, ", "), installPlanMessage(installPlan))
+	}
+
+	if !approvable(installPlan, target, subscription.Status.InstalledCSV) {
+		return waiting(ReasonUpgradeHeld, "InstallPlan %s to %s goes past %s, waiting for another InstallPlan", installPlan.Name,
+			strings.Join(installPlan.Spec.ClusterServiceVersionNames, ", "), target)
+	}
+
+	if !installPlan.Spec.Approved {
+		installPlan.Spec.Approved = true
+		if err := r.Update(ctx, installPlan); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Approved", util.LogKind, "InstallPlan", util.LogName, installPlan.Name,
+			"clusterServiceVersions", installPlan.Spec.ClusterServiceVersionNames)
+		installPlanApprovals.WithLabelValues(workshop.Namespace, workshop.Name, subscriptionName).Inc()
+		r.normalEvent(workshop, EventInstallPlanApproved, component, "",
+			"Approved the %s InstallPlan of the %s Subscription", installPlan.Name, subscriptionName)
 	}
 
-	if (clusterServiceVersion == "" && subscription.Status.InstalledCSV == "") ||
-		(clusterServiceVersion != "" && (subscription.Status.InstalledCSV != clusterServiceVersion)) {
-		if subscription.Status.InstallPlanRef == nil {
-			return errors.New("InstallPlan Approval: Subscription is not ready yet")
+	return waiting(ReasonCSVInstalling, "Installing %s with InstallPlan %s", strings.Join(installPlan.Spec.ClusterServiceVersionNames, ", "),
+		installPlan.Name)
+}
+
+// checkClusterServiceVersion waits for the installed ClusterServiceVersion to succeed
+func (r *WorkshopReconciler) checkClusterServiceVersion(ctx context.Context, workshop *workshopv1.Workshop, component string, name string,
+	namespace string, waiting func(string, string, ...interface{}) (reconcile.Result, error),
+	failed func(string, string, ...interface{}) (reconcile.Result, error)) (reconcile.Result, error) {
+
+	csv := &olmv1alpha1.ClusterServiceVersion{}
+	if err := kubernetes.GetObject(ctx, r, name, namespace, csv); err != nil {
+		if errors.IsNotFound(err) {
+			return waiting(ReasonCSVInstalling, "ClusterServiceVersion %s not found yet", name)
 		}
+		return reconcile.Result{}, err
+	}
+
+	switch csv.Status.Phase {
+	case olmv1alpha1.CSVPhaseSucceeded:
+		setOperatorCondition(workshop, component, metav1.ConditionTrue, ReasonCSVSucceeded,
+			fmt.Sprintf("ClusterServiceVersion %s succeeded", name))
+		return reconcile.Result{}, nil
+	case olmv1alpha1.CSVPhaseFailed:
+		r.warningEvent(workshop, EventComponentFailed, component, "", "ClusterServiceVersion %s failed: %s", name, csv.Status.Message)
+		return failed(ReasonCSVFailed, "ClusterServiceVersion %s failed (%s): %s", name, csv.Status.Reason, csv.Status.Message)
+	case olmv1alpha1.CSVPhaseReplacing:
+		return waiting(ReasonCSVReplacing, "ClusterServiceVersion %s is being replaced: %s", name, csv.Status.Message)
+	default:
+		return waiting(ReasonCSVInstalling, "ClusterServiceVersion %s is %s", name, csv.Status.Phase)
+	}
+}
+
+// approvable returns true if the InstallPlan installs the target ClusterServiceVersion, or if it upgrades the installed
+// ClusterServiceVersion to a version of the same package not past the target
+func approvable(installPlan *olmv1alpha1.InstallPlan, target string, installed string) bool {
+	if util.StringInSlice(target, installPlan.Spec.ClusterServiceVersionNames) {
+		return true
+	}
+	if installed == "" {
+		return false
+	}
 
-		installPlan := &olmv1alpha1.InstallPlan{}
-		if err := kubernetes.GetObject(r, subscription.Status.InstallPlanRef.Name, namespace, installPlan); err != nil {
-			return err
+	targetPackage, targetVersion, ok := csvVersion(target)
+	if !ok {
+		return false
+	}
+	for _, name := range installPlan.Spec.ClusterServiceVersionNames {
+		if csvPackage, version, ok := csvVersion(name); ok && csvPackage == targetPackage {
+			return version.LTE(targetVersion)
 		}
+	}
+	return false
+}
 
-		if util.StringInSlice(clusterServiceVersion, installPlan.Spec.ClusterServiceVersionNames) && !installPlan.Spec.Approved {
-			installPlan.Spec.Approved = true
-			if err := r.Update(context.TODO(), installPlan); err != nil {
-				return err
-			}
-			log.Infof("%s InstallPlan in %s project Approved", installPlan.Name, namespace)
+// csvVersion splits the name of a ClusterServiceVersion, <package>.v<version> by convention, into the package and the version
+func csvVersion(name string) (string, semver.Version, bool) {
+	i := strings.Index(name, ".v")
+	if i < 0 {
+		return "", semver.Version{}, false
+	}
+	version, err := semver.ParseTolerant(name[i+2:])
+	if err != nil {
+		return "", semver.Version{}, false
+	}
+	return name[:i], version, true
+}
+
+// installPlanMessage returns the message of the failed conditions of the InstallPlan
+func installPlanMessage(installPlan *olmv1alpha1.InstallPlan) string {
+	messages := []string{}
+	for _, condition := range installPlan.Status.Conditions {
+		if condition.Status == corev1.ConditionFalse && condition.Message != "" {
+			messages = append(messages, condition.Message)
 		}
 	}
-	return nil
+	if len(messages) == 0 {
+		return "no reason given"
+	}
+	return strings.Join(messages, "; ")
+}
+
+// setOperatorCondition sets the condition reporting the installation of the operator of the component
+func setOperatorCondition(workshop *workshopv1.Workshop, component string, status metav1.ConditionStatus, reason string, message string) {
+	util.SetCondition(&workshop.Status.Conditions, workshopv1.Condition{
+		Type:               component + workshopv1.ConditionOperatorSuffix,
+		Status:             status,
+		ObservedGeneration: workshop.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
 }
@@ -0,0 +1,283 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// EXTRA_MANIFEST_LABEL names the extra manifests a resource was applied from
+const EXTRA_MANIFEST_LABEL = "workshop.stakater.com/manifest"
+
+// manifestValues are the variables of the templates of the extra manifests
+type manifestValues struct {
+	Workshop           string
+	Namespace          string
+	Users              []string
+	AppsHostnameSuffix string
+	GiteaURL           string
+	// Username and ProjectName are only set in the User scope
+	Username    string
+	ProjectName string
+}
+
+// extraManifest is a parsed template of the extraManifests of the spec
+type extraManifest struct {
+	name     string
+	scope    workshopv1.ManifestScope
+	template *template.Template
+}
+
+// newManifestValues returns the variables of the templates in the Workshop scope
+func newManifestValues(workshop *workshopv1.Workshop, users []util.Attendee, appsHostnameSuffix string) manifestValues {
+	values := manifestValues{
+		Workshop:           workshop.Name,
+		Namespace:          workshop.Namespace,
+		AppsHostnameSuffix: appsHostnameSuffix,
+	}
+	for _, user := range users {
+		values.Users = append(values.Users, user.Username)
+	}
+	if workshop.Spec.Infrastructure.Gitea.Enabled {
+		values.GiteaURL = giteaRouteURL(workshop, appsHostnameSuffix)
+	}
+	return values
+}
+
+// forUser returns the variables of the templates in the User scope
+func (v manifestValues) forUser(workshop *workshopv1.Workshop, username string) manifestValues {
+	v.Username = username
+	v.ProjectName = util.ProjectName(workshop, username)
+	return v
+}
+
+// extraManifests reads and parses the templates of the extraManifests of the spec
+func (r *WorkshopReconciler) extraManifests(ctx context.Context, workshop *workshopv1.Workshop) ([]extraManifest, error) {
+	manifests := []extraManifest{}
+	for _, spec := range workshop.Spec.ExtraManifests {
+		text := spec.Template
+		if ref := spec.ConfigMapRef; ref != nil {
+			configMap := &corev1.ConfigMap{}
+			if err := kubernetes.GetObject(ctx, r, ref.Name, workshop.Namespace, configMap); err != nil {
+				return nil, fmt.Errorf("ConfigMap %s of the %s extra manifests: %w", ref.Name, spec.Name, err)
+			}
+			if ref.Key != "" {
+				var ok bool
+				if text, ok = configMap.Data[ref.Key]; !ok {
+					return nil, fmt.Errorf("ConfigMap %s of the %s extra manifests has no key %s", ref.Name, spec.Name, ref.Key)
+				}
+			} else {
+				keys := make([]string, 0, len(configMap.Data))
+				for key := range configMap.Data {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				documents := make([]string, 0, len(keys))
+				for _, key := range keys {
+					documents = append(documents, configMap.Data[key])
+				}
+				text = strings.Join(documents, "\n---\n")
+			}
+		}
+
+		parsed, err := template.New(spec.Name).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template of the %s extra manifests: %w", spec.Name, err)
+		}
+		scope := spec.Scope
+		if scope == "" {
+			scope = workshopv1.ManifestScopeUser
+		}
+		manifests = append(manifests, extraManifest{name: spec.Name, scope: scope, template: parsed})
+	}
+	return manifests, nil
+}
+
+// applyExtraManifests renders the extra manifests of the scope and applies them.
+// The references of the resources are added to applied, and to the status as soon as they are applied.
+func (r *WorkshopReconciler) applyExtraManifests(ctx context.Context, workshop *workshopv1.Workshop, manifests []extraManifest,
+	scope workshopv1.ManifestScope, values manifestValues, applied map[string][]workshopv1.ManifestReference) error {
+	var c client.Client
+	for _, manifest := range manifests {
+		if manifest.scope != scope {
+			continue
+		}
+		var rendered bytes.Buffer
+		if err := manifest.template.Execute(&rendered, values); err != nil {
+			return fmt.Errorf("failed to render the %s extra manifests: %w", manifest.name, err)
+		}
+		objects, err := kubernetes.DecodeManifests(workshop, rendered.String(), map[string]string{EXTRA_MANIFEST_LABEL: manifest.name})
+		if err != nil {
+			return fmt.Errorf("invalid %s extra manifests: %w", manifest.name, err)
+		}
+		if c == nil {
+			if c, err = r.manifestClient(workshop); err != nil {
+				return err
+			}
+		}
+
+		status := extraManifestStatus(workshop, manifest.name)
+		for _, object := range objects {
+			if err := r.applyManifest(ctx, c, workshop, object); err != nil {
+				return err
+			}
+			reference := kubernetes.ManifestReference(object)
+			applied[manifest.name] = append(applied[manifest.name], reference)
+			if !containsManifest(status.Resources, reference) {
+				status.Resources = append(status.Resources, reference)
+			}
+		}
+	}
+	return nil
+}
+
+// pruneExtraManifests deletes the resources which are no longer rendered from the extra manifests,
+// all of them for the extra manifests removed from the spec
+func (r *WorkshopReconciler) pruneExtraManifests(ctx context.Context, workshop *workshopv1.Workshop,
+	applied map[string][]workshopv1.ManifestReference) error {
+	var statuses []workshopv1.ExtraManifestStatus
+	for _, status := range workshop.Status.ExtraManifests {
+		if err := r.deleteManifests(ctx, workshop, removedManifests(status.Resources, applied[status.Name])); err != nil {
+			return err
+		}
+		if hasExtraManifest(workshop, status.Name) {
+			statuses = append(statuses, workshopv1.ExtraManifestStatus{Name: status.Name, Resources: applied[status.Name]})
+		}
+	}
+	workshop.Status.ExtraManifests = statuses
+	return nil
+}
+
+// deleteExtraManifests deletes all the resources applied from the extra manifests
+func (r *WorkshopReconciler) deleteExtraManifests(ctx context.Context, workshop *workshopv1.Workshop) error {
+	for _, status := range workshop.Status.ExtraManifests {
+		if err := r.deleteManifests(ctx, workshop, status.Resources); err != nil {
+			return err
+		}
+	}
+	workshop.Status.ExtraManifests = nil
+	return nil
+}
+
+// extraManifestStatus returns the status of the named extra manifests, added to the Workshop status if missing
+func extraManifestStatus(workshop *workshopv1.Workshop, name string) *workshopv1.ExtraManifestStatus {
+	statuses := workshop.Status.ExtraManifests
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+	workshop.Status.ExtraManifests = append(statuses, workshopv1.ExtraManifestStatus{Name: name})
+	return &workshop.Status.ExtraManifests[len(workshop.Status.ExtraManifests)-1]
+}
+
+// hasExtraManifest returns true if the spec lists the named extra manifests
+func hasExtraManifest(workshop *workshopv1.Workshop, name string) bool {
+	for _, spec := range workshop.Spec.ExtraManifests {
+		if spec.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// manifestClient returns the client of the manifests of the spec. It impersonates spec.serviceAccountName,
+// so that the manifests can only do what the roles of the Service Account allow, not what the operator can.
+func (r *WorkshopReconciler) manifestClient(workshop *workshopv1.Workshop) (client.Client, error) {
+	if workshop.Spec.ServiceAccountName == "" {
+		return nil, fmt.Errorf("spec.serviceAccountName is required to apply the manifests of the spec")
+	}
+	config := rest.CopyConfig(r.Config)
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: fmt.Sprintf("system:serviceaccount:%s:%s", workshop.Namespace, workshop.Spec.ServiceAccountName),
+	}
+	return client.New(config, client.Options{Scheme: r.Scheme, Mapper: r.mapper})
+}
+
+// applyManifest applies a resource of the manifests of the spec with the client of the manifests.
+// A resource which exists without being owned by the Workshop is left alone.
+func (r *WorkshopReconciler) applyManifest(ctx context.Context, c client.Client, workshop *workshopv1.Workshop,
+	object *unstructured.Unstructured) error {
+	found := &unstructured.Unstructured{}
+	found.SetGroupVersionKind(object.GroupVersionKind())
+	if err := kubernetes.GetObject(ctx, c, object.GetName(), object.GetNamespace(), found); err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && !util.IsOwnedBy(workshop, found.GetLabels()) {
+		return fmt.Errorf("%s %s already exists and is not owned by the Workshop", object.GetKind(), object.GetName())
+	}
+	if err := kubernetes.Apply(ctx, c, r.Scheme, object); err != nil {
+		return err
+	}
+	util.Logger(ctx).V(util.LogDebug).Info("Applied", util.LogKind, object.GetKind(), util.LogName, object.GetName(), util.LogNamespace, object.GetNamespace())
+	return nil
+}
+
+// deleteManifests deletes the resources applied from manifests, the ones already gone or whose API is gone are skipped
+func (r *WorkshopReconciler) deleteManifests(ctx context.Context, workshop *workshopv1.Workshop, references []workshopv1.ManifestReference) error {
+	log := util.Logger(ctx)
+	if len(references) == 0 {
+		return nil
+	}
+	c, err := r.manifestClient(workshop)
+	if err != nil {
+		return err
+	}
+	for _, reference := range references {
+		// Only the resources still owned by the Workshop are deleted
+		object := kubernetes.NewManifestObject(reference)
+		if err := kubernetes.GetObject(ctx, c, reference.Name, reference.Namespace, object); errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if !util.IsOwnedBy(workshop, object.GetLabels()) {
+			log.Info("Skipping the resource not owned by the Workshop", util.LogKind, reference.Kind, util.LogName, reference.Name,
+				util.LogNamespace, reference.Namespace)
+			continue
+		}
+		if err := c.Delete(ctx, object); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Deleted", util.LogKind, reference.Kind, util.LogName, reference.Name, util.LogNamespace, reference.Namespace)
+	}
+	return nil
+}
+
+// containsManifest returns true if the references include the reference
+func containsManifest(references []workshopv1.ManifestReference, reference workshopv1.ManifestReference) bool {
+	for _, r := range references {
+		if r == reference {
+			return true
+		}
+	}
+	return false
+}
+
+// removedManifests returns the references of previous missing from current
+func removedManifests(previous []workshopv1.ManifestReference, current []workshopv1.ManifestReference) []workshopv1.ManifestReference {
+	kept := map[workshopv1.ManifestReference]bool{}
+	for _, reference := range current {
+		kept[reference] = true
+	}
+	removed := []workshopv1.ManifestReference{}
+	for _, reference := range previous {
+		if !kept[reference] {
+			removed = append(removed, reference)
+		}
+	}
+	return removed
+}
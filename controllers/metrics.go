@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// Targets of the HTTP calls to the tools installed by the Workshops
+const (
+	httpTargetGitea     = "gitea"
+	httpTargetKeycloak  = "keycloak"
+	httpTargetCodeReady = "codeready"
+	httpTargetGitHub    = "github"
+	httpTargetOAuth     = "oauth"
+)
+
+var (
+	componentInstallDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "workshop_component_install_duration_seconds",
+		Help:    "Time from the start of the installation of a component until it is installed",
+		Buckets: []float64{5, 15, 30, 60, 120, 300, 600, 1200, 1800, 3600},
+	}, []string{"namespace", "workshop", "component"})
+
+	componentPhases = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workshop_component_phase",
+		Help: "1 for the current phase of a component, 0 for the other phases",
+	}, []string{"namespace", "workshop", "component", "phase"})
+
+	usersDesired = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workshop_users_desired",
+		Help: "Number of users in the roster of a Workshop",
+	}, []string{"namespace", "workshop"})
+
+	usersProvisioned = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "workshop_users_provisioned",
+		Help: "Number of users of the roster of a Workshop which have been provisioned",
+	}, []string{"namespace", "workshop"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "workshop_http_request_duration_seconds",
+		Help:    "Latency of the HTTP calls to the tools installed by a Workshop",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"namespace", "workshop", "target", "method"})
+
+	httpRequestErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "workshop_http_request_errors_total",
+		Help: "HTTP calls to the tools installed by a Workshop which failed, by status code or \"error\" without response",
+	}, []string{"namespace", "workshop", "target", "reason"})
+
+	installPlanApprovals = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "workshop_installplan_approvals_total",
+		Help: "InstallPlans approved by the operator",
+	}, []string{"namespace", "workshop", "subscription"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		componentInstallDuration,
+		componentPhases,
+		usersDesired,
+		usersProvisioned,
+		httpRequestDuration,
+		httpRequestErrors,
+		installPlanApprovals,
+	)
+}
+
+// allPhases are the phases a component goes through
+var allPhases = []string{
+	util.OperatorStatus.NotScheduled,
+	util.OperatorStatus.Scheduled,
+	util.OperatorStatus.InProgress,
+	util.OperatorStatus.Installed,
+	util.OperatorStatus.Failed,
+	util.OperatorStatus.Uninstalling,
+}
+
+// installTimer remembers when the installation of the components started, across reconciliations.
+// The installations in progress when the operator restarts are timed from the restart.
+type installTimer struct {
+	sync.Mutex
+	started map[string]time.Time
+}
+
+var componentInstallTimer = &installTimer{started: map[string]time.Time{}}
+
+// record observes the install duration of a component once it reaches the Installed phase
+func (t *installTimer) record(workshop *workshopv1.Workshop, component string, before string, after string, now time.Time) {
+	t.Lock()
+	defer t.Unlock()
+
+	key := string(workshop.UID) + "/" + component
+	switch after {
+	case util.OperatorStatus.InProgress, util.OperatorStatus.Failed:
+		if _, ok := t.started[key]; !ok {
+			t.started[key] = now
+		}
+	case util.OperatorStatus.Installed:
+		if before == util.OperatorStatus.Installed {
+			return
+		}
+		started, ok := t.started[key]
+		if !ok {
+			started = now
+		}
+		componentInstallDuration.WithLabelValues(workshop.Namespace, workshop.Name, component).Observe(now.Sub(started).Seconds())
+		delete(t.started, key)
+	default:
+		delete(t.started, key)
+	}
+}
+
+// forget drops the install timer of a component
+func (t *installTimer) forget(workshop *workshopv1.Workshop, component string) {
+	t.Lock()
+	defer t.Unlock()
+	delete(t.started, string(workshop.UID)+"/"+component)
+}
+
+// recordComponentPhases sets the phase gauges of the components of the Workshop
+func recordComponentPhases(workshop *workshopv1.Workshop, components []componentPhase) {
+	for _, c := range components {
+		for _, phase := range allPhases {
+			value := 0.0
+			if *c.phase == phase {
+				value = 1
+			}
+			componentPhases.WithLabelValues(workshop.Namespace, workshop.Name, c.name, phase).Set(value)
+		}
+	}
+}
+
+// recordUsers sets the number of users desired and provisioned for the Workshop
+func recordUsers(workshop *workshopv1.Workshop, desired int, provisioned int) {
+	usersDesired.WithLabelValues(workshop.Namespace, workshop.Name).Set(float64(desired))
+	usersProvisioned.WithLabelValues(workshop.Namespace, workshop.Name).Set(float64(provisioned))
+}
+
+// forgetWorkshopMetrics removes the gauges and the install timers of a deleted Workshop
+func forgetWorkshopMetrics(workshop *workshopv1.Workshop, components []componentPhase) {
+	for _, c := range components {
+		for _, phase := range allPhases {
+			componentPhases.DeleteLabelValues(workshop.Namespace, workshop.Name, c.name, phase)
+		}
+		componentInstallTimer.forget(workshop, c.name)
+	}
+	usersDesired.DeleteLabelValues(workshop.Namespace, workshop.Name)
+	usersProvisioned.DeleteLabelValues(workshop.Namespace, workshop.Name)
+}
+
+// instrumentedTransport records the latency and the errors of the HTTP calls of a Workshop to a tool
+type instrumentedTransport struct {
+	namespace string
+	workshop  string
+	target    string
+	transport http.RoundTripper
+}
+
+// instrumentTransport returns a transport recording the HTTP calls of the Workshop to the target
+func instrumentTransport(workshop *workshopv1.Workshop, target string, transport http.RoundTripper) http.RoundTripper {
+	return &instrumentedTransport{namespace: workshop.Namespace, workshop: workshop.Name, target: target, transport: transport}
+}
+
+func (t *instrumentedTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.transport.RoundTrip(request)
+	httpRequestDuration.WithLabelValues(t.namespace, t.workshop, t.target, request.Method).Observe(time.Since(start).Seconds())
+	if err != nil {
+		httpRequestErrors.WithLabelValues(t.namespace, t.workshop, t.target, "error").Inc()
+	} else if response.StatusCode >= http.StatusBadRequest {
+		httpRequestErrors.WithLabelValues(t.namespace, t.workshop, t.target, strconv.Itoa(response.StatusCode)).Inc()
+	}
+	return response, err
+}
@@ -2,9 +2,7 @@ package controllers
 
 import (
 	"context"
-	"time"
 
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	nexus "github.com/stakater/workshop-operator/common/nexus"
@@ -34,86 +32,80 @@ const (
 	NEXUSCLUSTERROLEKINDNAME   = "ClusterRole"
 	NEXUSANSIBLEDEPLOYMENTNAME = "nexus-operator"
 	NEXUSDEPLOYMENTNAME        = "nexus"
+	NEXUSHELMRELEASENAME       = "nexus"
+	NEXUSHELMREPOSITORY        = "https://sonatype.github.io/helm3-charts/"
+	NEXUSHELMCHART             = "nexus-repository-manager"
+	NEXUSSERVICEPORT           = 8081
 )
 
 // Reconciling Nexus
-func (r *WorkshopReconciler) reconcileNexus(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	enabledNexus := workshop.Spec.Infrastructure.Nexus.Enabled
+func (r *WorkshopReconciler) reconcileNexus(ctx context.Context, workshop *workshopv1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		return r.addKubernetesNexus(ctx, workshop, appsHostnameSuffix)
+	}
 
-	if enabledNexus {
-		if result, err := r.addNexus(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addNexus(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	return reconcile.Result{}, nil
 }
 
 // Add Nexus
-func (r *WorkshopReconciler) addNexus(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addNexus(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
+	nexusNamespaceName := util.ScopedName(workshop, NEXUSNAMESPACENAME)
 	imageName := workshop.Spec.Infrastructure.Nexus.Image.Name
 	imageTag := workshop.Spec.Infrastructure.Nexus.Image.Tag
 
 	// Create Project
-	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, NEXUSNAMESPACENAME)
-	if err := r.Create(context.TODO(), nexusNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, nexusNamespaceName)
+	if err := r.apply(ctx, nexusNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s nexus Project", NEXUSNAMESPACENAME)
 	}
 
 	// Create CRD
 	nexusCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, NEXUSCRDNAME, NEXUSCRDGROUPNAME, NEXUSCRDKINDNAME, NEXUSCRDLISTKINDNAME, NEXUSCRDPLURALNAME, NEXUSCRDSINGULARNAME, NEXUSCRDVERSIONAME, nil, nil)
-	if err := r.Create(context.TODO(), nexusCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, nexusCustomResourceDefinition); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s nexus Custom Resource Definition", nexusCustomResourceDefinition.Name)
+		log.Info("Created", util.LogKind, "CustomResourceDefinition", util.LogName, nexusCustomResourceDefinition.Name)
 	}
 
 	// Create Service Account
-	nexusServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, NEXUSSERVICEACCOUNTNAME, NEXUSNAMESPACENAME, nexuslabels)
-	if err := r.Create(context.TODO(), nexusServiceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	nexusServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, NEXUSSERVICEACCOUNTNAME, nexusNamespaceName, nexuslabels)
+	if err := r.apply(ctx, nexusServiceAccount); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s nexus Service Account", nexusServiceAccount.Name)
 	}
 
 	// Create Cluster Role
-	nexusClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, NEXUSCLUSTERROLENAME, NEXUSNAMESPACENAME, nexuslabels, nexus.NewRules())
-	if err := r.Create(context.TODO(), nexusClusterRole); err != nil && !errors.IsAlreadyExists(err) {
+	nexusClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, util.ScopedName(workshop, NEXUSCLUSTERROLENAME), nexusNamespaceName, nexuslabels, nexus.NewRules())
+	if err := r.apply(ctx, nexusClusterRole); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s nexus Cluster Role", nexusClusterRole.Name)
 	}
 
 	// Create Cluster Role Binding
-	nexusClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, NEXUSROLEBINDINGSANAME, NEXUSNAMESPACENAME, nexuslabels, NEXUSSERVICEACCOUNTNAME, NEXUSROLEBINDINGSANAME, NEXUSCLUSTERROLEKINDNAME)
-	if err := r.Create(context.TODO(), nexusClusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	nexusClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, NEXUSROLEBINDINGSANAME), nexusNamespaceName, nexuslabels, NEXUSSERVICEACCOUNTNAME, util.ScopedName(workshop, NEXUSCLUSTERROLENAME), NEXUSCLUSTERROLEKINDNAME)
+	if err := r.apply(ctx, nexusClusterRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s nexus Cluster Role Binding", nexusClusterRoleBinding.Name)
 	}
 
 	// Create Operator
-	nexusOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, NEXUSANSIBLEDEPLOYMENTNAME, NEXUSNAMESPACENAME, nexuslabels, imageName+":"+imageTag, NEXUSSERVICEACCOUNTNAME)
-	if err := r.Create(context.TODO(), nexusOperator); err != nil && !errors.IsAlreadyExists(err) {
+	nexusOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, NEXUSANSIBLEDEPLOYMENTNAME, nexusNamespaceName, nexuslabels, imageName+":"+imageTag, NEXUSSERVICEACCOUNTNAME)
+	if err := r.apply(ctx, nexusOperator); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s nexus Operator", nexusOperator.Name)
 	}
 
 	// Create Custom Resource
-	nexusCustomResource := nexus.NewCustomResource(workshop, r.Scheme, NEXUSCRNAME, NEXUSNAMESPACENAME, nexuslabels)
-	if err := r.Create(context.TODO(), nexusCustomResource); err != nil && !errors.IsAlreadyExists(err) {
+	nexusCustomResource := nexus.NewCustomResource(workshop, r.Scheme, NEXUSCRNAME, nexusNamespaceName, nexuslabels)
+	if err := r.apply(ctx, nexusCustomResource); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s nexus Custom Resource", nexusCustomResource.Name)
 	}
 
 	// Wait for server to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(NEXUSDEPLOYMENTNAME, NEXUSNAMESPACENAME) {
-		return reconcile.Result{Requeue: true, RequeueAfter: time.Second * 1}, nil
+	if result, err := kubernetes.CheckDeploymentReady(ctx, r, NEXUSDEPLOYMENTNAME, nexusNamespaceName); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -121,62 +113,124 @@ func (r *WorkshopReconciler) addNexus(workshop *workshopv1.Workshop) (reconcile.
 }
 
 // Delete Nexus
-func (r *WorkshopReconciler) deleteNexus(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteNexus(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	if !r.isOpenShift() {
+		return r.deleteKubernetesNexus(ctx, workshop)
+	}
 
 	log.Info("Deleting nexus")
 
+	nexusNamespaceName := util.ScopedName(workshop, NEXUSNAMESPACENAME)
 	imageName := workshop.Spec.Infrastructure.Nexus.Image.Name
 	imageTag := workshop.Spec.Infrastructure.Nexus.Image.Tag
 
-	nexusCustomResource := nexus.NewCustomResource(workshop, r.Scheme, NEXUSCRNAME, NEXUSNAMESPACENAME, nexuslabels)
+	nexusCustomResource := nexus.NewCustomResource(workshop, r.Scheme, NEXUSCRNAME, nexusNamespaceName, nexuslabels)
 	// Delete Custom Resource
-	if err := r.Delete(context.TODO(), nexusCustomResource); err != nil {
+	if err := r.Delete(ctx, nexusCustomResource); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s nexus Custom Resource", nexusCustomResource.Name)
+	log.Info("Deleted", util.LogKind, "Nexus", util.LogName, nexusCustomResource.Name)
 
-	nexusOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, NEXUSANSIBLEDEPLOYMENTNAME, NEXUSNAMESPACENAME, nexuslabels, imageName+":"+imageTag, NEXUSSERVICEACCOUNTNAME)
+	nexusOperator := kubernetes.NewAnsibleOperatorDeployment(workshop, r.Scheme, NEXUSANSIBLEDEPLOYMENTNAME, nexusNamespaceName, nexuslabels, imageName+":"+imageTag, NEXUSSERVICEACCOUNTNAME)
 	// Delete Operator
-	if err := r.Delete(context.TODO(), nexusOperator); err != nil {
+	if err := r.Delete(ctx, nexusOperator); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s nexus Operator", nexusOperator.Name)
+	log.Info("Deleted", util.LogKind, "Deployment", util.LogName, nexusOperator.Name)
 
-	nexusClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, NEXUSROLEBINDINGSANAME, NEXUSNAMESPACENAME, nexuslabels, NEXUSSERVICEACCOUNTNAME, NEXUSROLEBINDINGSANAME, NEXUSCLUSTERROLEKINDNAME)
+	nexusClusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, NEXUSROLEBINDINGSANAME), nexusNamespaceName, nexuslabels, NEXUSSERVICEACCOUNTNAME, util.ScopedName(workshop, NEXUSCLUSTERROLENAME), NEXUSCLUSTERROLEKINDNAME)
 	// Delete Cluster Role Binding
-	if err := r.Delete(context.TODO(), nexusClusterRoleBinding); err != nil {
+	if err := r.Delete(ctx, nexusClusterRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s nexus Cluster Role Binding", nexusClusterRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRoleBinding", util.LogName, nexusClusterRoleBinding.Name)
 
-	nexusClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, NEXUSCLUSTERROLENAME, NEXUSNAMESPACENAME, nexuslabels, nexus.NewRules())
+	nexusClusterRole := kubernetes.NewClusterRole(workshop, r.Scheme, util.ScopedName(workshop, NEXUSCLUSTERROLENAME), nexusNamespaceName, nexuslabels, nexus.NewRules())
 	// Delete Cluster Role
-	if err := r.Delete(context.TODO(), nexusClusterRole); err != nil {
+	if err := r.Delete(ctx, nexusClusterRole); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s nexus Cluster Role", nexusClusterRole.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRole", util.LogName, nexusClusterRole.Name)
 
-	nexusServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, NEXUSSERVICEACCOUNTNAME, NEXUSNAMESPACENAME, nexuslabels)
+	nexusServiceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, NEXUSSERVICEACCOUNTNAME, nexusNamespaceName, nexuslabels)
 	// Delete Service Account
-	if err := r.Delete(context.TODO(), nexusServiceAccount); err != nil {
+	if err := r.Delete(ctx, nexusServiceAccount); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s nexus Service Account", nexusServiceAccount.Name)
+	log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, nexusServiceAccount.Name)
 
-	nexusCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, NEXUSCRDNAME, NEXUSCRDGROUPNAME, NEXUSCRDKINDNAME, NEXUSCRDLISTKINDNAME, NEXUSCRDPLURALNAME, NEXUSCRDSINGULARNAME, NEXUSCRDVERSIONAME, nil, nil)
-	// Delete CRD
-	if err := r.Delete(context.TODO(), nexusCustomResourceDefinition); err != nil {
+	// The CRD is shared by the Nexus operators of all Workshops
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "Nexus")
+	})
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s nexus Custom Resource Definition", nexusCustomResourceDefinition.Name)
+	if !isUsed {
+		nexusCustomResourceDefinition := kubernetes.NewCustomResourceDefinition(workshop, r.Scheme, NEXUSCRDNAME, NEXUSCRDGROUPNAME, NEXUSCRDKINDNAME, NEXUSCRDLISTKINDNAME, NEXUSCRDPLURALNAME, NEXUSCRDSINGULARNAME, NEXUSCRDVERSIONAME, nil, nil)
+		// Delete CRD
+		if err := r.Delete(ctx, nexusCustomResourceDefinition); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "CustomResourceDefinition", util.LogName, nexusCustomResourceDefinition.Name)
+	}
 
-	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, NEXUSNAMESPACENAME)
+	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, nexusNamespaceName)
 	// Delete Project
-	if err := r.Delete(context.TODO(), nexusNamespace); err != nil {
+	if err := r.Delete(ctx, nexusNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  nexus Project", NEXUSNAMESPACENAME)
+	log.Info("Deleted", util.LogKind, "Project", util.LogName, nexusNamespaceName)
 	log.Info("Nexus deleted successfully")
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// addKubernetesNexus installs Nexus with its chart on Kubernetes and exposes it as the Nexus operator does on OpenShift
+func (r *WorkshopReconciler) addKubernetesNexus(ctx context.Context, workshop *workshopv1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, util.ScopedName(workshop, NEXUSNAMESPACENAME))
+	if err := r.apply(ctx, nexusNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if result, err := r.installHelmRelease(ctx, workshop, nexusHelmRelease(workshop)); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	nexusRoute := r.newSecuredRoute(workshop, NEXUSCRNAME, nexusNamespace.Name, nexuslabels, NEXUSDEPLOYMENTNAME, NEXUSSERVICEPORT, appsHostnameSuffix)
+	if err := r.apply(ctx, nexusRoute); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteKubernetesNexus uninstalls the release of Nexus and deletes its namespace
+func (r *WorkshopReconciler) deleteKubernetesNexus(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	if result, err := r.uninstallHelmRelease(ctx, workshop, nexusHelmRelease(workshop)); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	nexusNamespace := kubernetes.NewNamespace(workshop, r.Scheme, util.ScopedName(workshop, NEXUSNAMESPACENAME))
+	if err := r.Delete(ctx, nexusNamespace); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, nexusNamespace.Name)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// nexusHelmRelease returns the release of Nexus standing in for the Nexus operator on Kubernetes
+func nexusHelmRelease(workshop *workshopv1.Workshop) helmRelease {
+	return newHelmRelease(NEXUSHELMRELEASENAME, util.ScopedName(workshop, NEXUSNAMESPACENAME), workshop.Spec.Infrastructure.Nexus.Helm,
+		NEXUSHELMREPOSITORY, NEXUSHELMCHART, map[string]interface{}{
+			"fullnameOverride": NEXUSDEPLOYMENTNAME,
+			"ingress":          map[string]interface{}{"enabled": false},
+		})
+}
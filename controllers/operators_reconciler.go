@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+const (
+	OPERATORS_SUBSCRIPTION_NAMESPACE_NAME = "openshift-operators"
+	// OPERATORS_OPERATOR_LABEL names the operator of infrastructure.operators a resource of the manifests belongs to
+	OPERATORS_OPERATOR_LABEL = "workshop.stakater.com/operator"
+)
+
+// Reconciling the operators of infrastructure.operators
+func (r *WorkshopReconciler) reconcileOperators(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	// Uninstall the operators removed from the spec
+	for _, installed := range append([]workshopv1.InstalledOperatorStatus{}, workshop.Status.InstalledOperators...) {
+		if findOperator(workshop, installed.Name) != nil {
+			continue
+		}
+		if result, err := r.deleteOperator(ctx, workshop, installed); util.IsRequeued(result, err) {
+			return result, err
+		}
+		removeInstalledOperator(workshop, installed.Name)
+	}
+
+	for _, operator := range workshop.Spec.Infrastructure.Operators {
+		if result, err := r.addOperator(util.WithLogValues(ctx, "operator", operator.Name), workshop, operator); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) addOperator(ctx context.Context, workshop *workshopv1.Workshop, operator workshopv1.OperatorSpec) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	namespace := r.operatorNamespace(operator)
+
+	// The operator moved to another namespace
+	if installed := findInstalledOperator(workshop, operator.Name); installed != nil && installed.Namespace != namespace {
+		if result, err := r.deleteOperator(ctx, workshop, *installed); util.IsRequeued(result, err) {
+			return result, err
+		}
+		removeInstalledOperator(workshop, operator.Name)
+	}
+
+	if !r.isOpenShift() {
+		// Recorded from now on, so that the operator is uninstalled even if it never gets ready
+		installedOperator(workshop, operator.Name).Namespace = namespace
+
+		if result, err := r.installHelmRelease(ctx, workshop, operatorHelmRelease(operator.Name, namespace, operator.Helm)); util.IsRequeued(result, err) {
+			return result, err
+		}
+		return r.applyOperatorManifests(ctx, workshop, operator)
+	}
+
+	// openshift-operators has the global OperatorGroup, any other namespace needs its own
+	if namespace != OPERATORS_SUBSCRIPTION_NAMESPACE_NAME {
+		operatorNamespace := kubernetes.NewNamespace(workshop, r.Scheme, namespace)
+		if err := r.Create(ctx, operatorNamespace); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Info("Created", util.LogKind, "Namespace", util.LogName, operatorNamespace.Name)
+		}
+
+		operatorGroup := kubernetes.NewAllNamespacesOperatorGroup(workshop, r.Scheme, operator.Name, namespace)
+		if operator.OperatorGroupMode == workshopv1.OperatorGroupOwnNamespace {
+			operatorGroup = kubernetes.NewOperatorGroup(workshop, r.Scheme, operator.Name, namespace)
+		}
+		if err := r.Create(ctx, operatorGroup); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Info("Created", util.LogKind, "OperatorGroup", util.LogName, operatorGroup.Name)
+		}
+	}
+
+	// The package defaults to the name of the operator
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, operator.Name, namespace, operator.Name, operator.OperatorHubSpec)
+	if err := r.applyShared(ctx, workshop, subscription); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Recorded from now on, so that the operator is uninstalled even if it never gets ready
+	installed := installedOperator(workshop, operator.Name)
+	installed.Namespace = namespace
+
+	// Approve the installation
+	if result, err := r.ApproveInstallPlan(ctx, workshop, operator.Name, operator.ClusterServiceVersion, operator.Name, namespace); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	subscriptionFound := &olmv1alpha1.Subscription{}
+	if err := kubernetes.GetObject(ctx, r, operator.Name, namespace, subscriptionFound); err != nil {
+		return reconcile.Result{}, err
+	}
+	installed.ClusterServiceVersion = subscriptionFound.Status.InstalledCSV
+
+	return r.applyOperatorManifests(ctx, workshop, operator)
+}
+
+// applyOperatorManifests applies the manifests of the installed operator and deletes the resources removed from them
+func (r *WorkshopReconciler) applyOperatorManifests(ctx context.Context, workshop *workshopv1.Workshop, operator workshopv1.OperatorSpec) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	installed := installedOperator(workshop, operator.Name)
+
+	// Apply the manifests
+	objects, err := kubernetes.DecodeManifests(workshop, operator.Manifests, map[string]string{OPERATORS_OPERATOR_LABEL: operator.Name})
+	if err != nil {
+		return reconcile.Result{}, fmt.Errorf("invalid manifests of the %s operator: %w", operator.Name, err)
+	}
+	if len(objects) > 0 {
+		c, err := r.manifestClient(workshop)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		for _, object := range objects {
+			if err := r.applyManifest(ctx, c, workshop, object); err != nil {
+				if meta.IsNoMatchError(err) {
+					// The API of the operator is not discovered yet
+					log.Info("Waiting for the API", util.LogKind, object.GetKind(), "apiVersion", object.GetAPIVersion())
+					return reconcile.Result{RequeueAfter: OLMRequeueAfter}, nil
+				}
+				return reconcile.Result{}, err
+			}
+		}
+	}
+
+	// Delete the resources removed from the manifests
+	applied := kubernetes.ManifestReferences(objects)
+	if err := r.deleteManifests(ctx, workshop, removedManifests(installed.Manifests, applied)); err != nil {
+		return reconcile.Result{}, err
+	}
+	installed.Manifests = applied
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+func (r *WorkshopReconciler) deleteOperators(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	for _, installed := range append([]workshopv1.InstalledOperatorStatus{}, workshop.Status.InstalledOperators...) {
+		if result, err := r.deleteOperator(ctx, workshop, installed); util.IsRequeued(result, err) {
+			return result, err
+		}
+		removeInstalledOperator(workshop, installed.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteOperator deletes the resources of the manifests of the operator, then the operator unless another Workshop uses it
+func (r *WorkshopReconciler) deleteOperator(ctx context.Context, workshop *workshopv1.Workshop, installed workshopv1.InstalledOperatorStatus) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues("operator", installed.Name)
+
+	if err := r.deleteManifests(ctx, workshop, installed.Manifests); err != nil {
+		return reconcile.Result{}, err
+	}
+	util.RemoveCondition(&workshop.Status.Conditions, installed.Name+workshopv1.ConditionOperatorSuffix)
+
+	// The operator is shared by the Workshops installing it in the same namespace
+	uses := func(other *workshopv1.Workshop) bool {
+		for _, operator := range other.Spec.Infrastructure.Operators {
+			if operator.Name == installed.Name && r.operatorNamespace(operator) == installed.Namespace {
+				return true
+			}
+		}
+		return false
+	}
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, uses)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// The release of the chart stands in for the Subscription on Kubernetes
+	if !r.isOpenShift() {
+		release := operatorHelmRelease(installed.Name, installed.Namespace, workshopv1.HelmChartSpec{})
+		if isUsed {
+			// Another Workshop takes over the release
+			return r.handOverHelmReleasesUsing(ctx, workshop, uses, release)
+		}
+		return r.uninstallHelmRelease(ctx, workshop, release)
+	}
+	if isUsed {
+		return reconcile.Result{}, nil
+	}
+
+	// Delete the Subscription
+	subscription := &olmv1alpha1.Subscription{}
+	subscription.Name, subscription.Namespace = installed.Name, installed.Namespace
+	if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
+
+	// Delete the ClusterServiceVersion, OLM keeps it when the Subscription goes
+	if installed.ClusterServiceVersion != "" {
+		csv := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, installed.ClusterServiceVersion, installed.Namespace)
+		if err := r.Delete(ctx, csv); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "ClusterServiceVersion", util.LogName, csv.Name)
+	}
+
+	if installed.Namespace == OPERATORS_SUBSCRIPTION_NAMESPACE_NAME {
+		return reconcile.Result{}, nil
+	}
+
+	// Delete the Namespace if the Workshop created it, the OperatorGroup otherwise
+	namespace := &corev1.Namespace{}
+	if err := kubernetes.GetObject(ctx, r, installed.Namespace, "", namespace); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if err == nil && util.IsOwnedBy(workshop, namespace.Labels) {
+		if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Namespace", util.LogName, namespace.Name)
+		return reconcile.Result{}, nil
+	}
+
+	operatorGroup := &olmv1.OperatorGroup{}
+	if err := kubernetes.GetObject(ctx, r, installed.Name, installed.Namespace, operatorGroup); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	} else if err == nil && util.IsOwnedBy(workshop, operatorGroup.Labels) {
+		if err := r.Delete(ctx, operatorGroup); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "OperatorGroup", util.LogName, operatorGroup.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// findOperator returns the spec of the named operator of infrastructure.operators, nil if it is not listed
+func findOperator(workshop *workshopv1.Workshop, name string) *workshopv1.OperatorSpec {
+	operators := workshop.Spec.Infrastructure.Operators
+	for i := range operators {
+		if operators[i].Name == name {
+			return &operators[i]
+		}
+	}
+	return nil
+}
+
+// findInstalledOperator returns the status of the named operator, nil if it is not installed
+func findInstalledOperator(workshop *workshopv1.Workshop, name string) *workshopv1.InstalledOperatorStatus {
+	installed := workshop.Status.InstalledOperators
+	for i := range installed {
+		if installed[i].Name == name {
+			return &installed[i]
+		}
+	}
+	return nil
+}
+
+// installedOperator returns the status of the named operator, added to the Workshop status if missing
+func installedOperator(workshop *workshopv1.Workshop, name string) *workshopv1.InstalledOperatorStatus {
+	if installed := findInstalledOperator(workshop, name); installed != nil {
+		return installed
+	}
+	workshop.Status.InstalledOperators = append(workshop.Status.InstalledOperators, workshopv1.InstalledOperatorStatus{Name: name})
+	return &workshop.Status.InstalledOperators[len(workshop.Status.InstalledOperators)-1]
+}
+
+// removeInstalledOperator drops the status of the named operator
+func removeInstalledOperator(workshop *workshopv1.Workshop, name string) {
+	var installed []workshopv1.InstalledOperatorStatus
+	for _, operator := range workshop.Status.InstalledOperators {
+		if operator.Name != name {
+			installed = append(installed, operator)
+		}
+	}
+	workshop.Status.InstalledOperators = installed
+}
+
+// operatorNamespace returns the namespace of the operator: the targetNamespace, or else openshift-operators on OpenShift
+// and the namespace of the name of the operator on Kubernetes
+func (r *WorkshopReconciler) operatorNamespace(operator workshopv1.OperatorSpec) string {
+	if !r.isOpenShift() {
+		return kubernetes.SubscriptionNamespace(operator.OperatorHubSpec, operator.Name)
+	}
+	return kubernetes.SubscriptionNamespace(operator.OperatorHubSpec, OPERATORS_SUBSCRIPTION_NAMESPACE_NAME)
+}
+
+// operatorHelmRelease returns the release of the chart standing in for the operator on Kubernetes, named after the operator.
+// The chart has no default, the spec sets it.
+func operatorHelmRelease(name string, namespace string, chart workshopv1.HelmChartSpec) helmRelease {
+	return newHelmRelease(name, namespace, chart, "", "", nil)
+}
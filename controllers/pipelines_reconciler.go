@@ -2,8 +2,6 @@ package controllers
 
 import (
 	"context"
-	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 
@@ -13,13 +11,13 @@ import (
 )
 
 // Reconciling Pipeline
-func (r *WorkshopReconciler) reconcilePipelines(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	enabledPipeline := workshop.Spec.Infrastructure.Pipeline.Enabled
+func (r *WorkshopReconciler) reconcilePipelines(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		return r.installHelmRelease(ctx, workshop, pipelinesHelmRelease(workshop))
+	}
 
-	if enabledPipeline {
-		if result, err := r.addPipelines(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addPipelines(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -30,31 +28,27 @@ const (
 	PIPELINES_SUBSCRIPTION_NAME           = "openshift-pipelines-operator-rh"
 	PIPELINES_SUBSCRIPTION_NAMESPACE_NAME = "openshift-operators"
 	PIPELINES_SUBSCRIPTION_PACKAGE_NAME   = "openshift-pipelines-operator-rh"
+	PIPELINES_HELM_RELEASE_NAME           = "tekton-pipeline"
+	PIPELINES_HELM_NAMESPACE_NAME         = "tekton-pipelines"
+	PIPELINES_HELM_REPOSITORY             = "https://cdfoundation.github.io/tekton-helm-chart/"
+	PIPELINES_HELM_CHART                  = "tekton-pipeline"
 )
 
 // Add Pipelines
-func (r *WorkshopReconciler) addPipelines(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-
-	channel := workshop.Spec.Infrastructure.Pipeline.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.Pipeline.OperatorHub.ClusterServiceVersion
+func (r *WorkshopReconciler) addPipelines(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	operatorHub := workshop.Spec.Infrastructure.Pipeline.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, PIPELINES_SUBSCRIPTION_NAMESPACE_NAME)
 
 	// Create Subscription
-	pipelineSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, PIPELINES_SUBSCRIPTION_NAME, PIPELINES_SUBSCRIPTION_NAMESPACE_NAME,
-		PIPELINES_SUBSCRIPTION_PACKAGE_NAME, channel, clusterServiceVersion)
-	if err := r.Create(context.TODO(), pipelineSubscription); err != nil && !errors.IsAlreadyExists(err) {
+	pipelineSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, PIPELINES_SUBSCRIPTION_NAME, subscriptionNamespace,
+		PIPELINES_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+	if err := r.applyShared(ctx, workshop, pipelineSubscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", pipelineSubscription.Name)
 	}
 
-	subscription := &olmv1alpha1.Subscription{}
-	if err := kubernetes.GetObject(r, PIPELINES_SUBSCRIPTION_NAME, PIPELINES_SUBSCRIPTION_NAMESPACE_NAME, subscription); err != nil {
-		// Approve the installation
-		if err := r.ApproveInstallPlan(clusterServiceVersion, PIPELINES_SUBSCRIPTION_NAME, PIPELINES_SUBSCRIPTION_NAMESPACE_NAME); err != nil {
-			log.Infof("Waiting for Subscription to create InstallPlan for %s", PIPELINES_SUBSCRIPTION_NAME)
-			return reconcile.Result{Requeue: true}, nil
-		}
-		return reconcile.Result{}, err
+	// Approve the installation
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "Pipeline", operatorHub.ClusterServiceVersion, PIPELINES_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -62,17 +56,40 @@ func (r *WorkshopReconciler) addPipelines(workshop *workshopv1.Workshop) (reconc
 }
 
 // delete Pipelines
-func (r *WorkshopReconciler) deletePipelines(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	channel := workshop.Spec.Infrastructure.Pipeline.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.Pipeline.OperatorHub.ClusterServiceVersion
+func (r *WorkshopReconciler) deletePipelines(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	// The Pipeline installation is shared by the Workshops of the cluster
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "Pipeline")
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if isUsed {
+		// Another Workshop takes over the release on Kubernetes
+		return r.handOverHelmReleases(ctx, workshop, "Pipeline", pipelinesHelmRelease(workshop))
+	}
 
-	pipelineSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, PIPELINES_SUBSCRIPTION_NAME, PIPELINES_SUBSCRIPTION_NAMESPACE_NAME,
-		PIPELINES_SUBSCRIPTION_PACKAGE_NAME, channel, clusterServiceVersion)
+	if !r.isOpenShift() {
+		return r.uninstallHelmRelease(ctx, workshop, pipelinesHelmRelease(workshop))
+	}
+
+	operatorHub := workshop.Spec.Infrastructure.Pipeline.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, PIPELINES_SUBSCRIPTION_NAMESPACE_NAME)
+
+	pipelineSubscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, PIPELINES_SUBSCRIPTION_NAME, subscriptionNamespace,
+		PIPELINES_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
 	// Delete Subscription
-	if err := r.Delete(context.TODO(), pipelineSubscription); err != nil {
+	if err := r.Delete(ctx, pipelineSubscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", pipelineSubscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, pipelineSubscription.Name)
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// pipelinesHelmRelease returns the release of Tekton Pipelines standing in for OpenShift Pipelines on Kubernetes
+func pipelinesHelmRelease(workshop *workshopv1.Workshop) helmRelease {
+	return newHelmRelease(PIPELINES_HELM_RELEASE_NAME, PIPELINES_HELM_NAMESPACE_NAME, workshop.Spec.Infrastructure.Pipeline.Helm,
+		PIPELINES_HELM_REPOSITORY, PIPELINES_HELM_CHART, nil)
+}
@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// isOpenShift returns true if the Workshops are installed on OpenShift
+func (r *WorkshopReconciler) isOpenShift() bool {
+	return r.Platform != kubernetes.PlatformKubernetes
+}
+
+// newRoute exposes the service with a Route on OpenShift, with an Ingress on Kubernetes
+func (r *WorkshopReconciler) newRoute(workshop *workshopv1.Workshop, name string, namespace string, labels map[string]string,
+	serviceName string, port int32, appsHostnameSuffix string) runtime.Object {
+	if r.isOpenShift() {
+		return kubernetes.NewRoute(workshop, r.Scheme, name, namespace, labels, serviceName, port)
+	}
+	return kubernetes.NewIngress(workshop, r.Scheme, name, namespace, labels, serviceName, port,
+		kubernetes.RouteHost(name, namespace, appsHostnameSuffix))
+}
+
+// newSecuredRoute exposes the service over TLS with a Route on OpenShift, with an Ingress on Kubernetes
+func (r *WorkshopReconciler) newSecuredRoute(workshop *workshopv1.Workshop, name string, namespace string, labels map[string]string,
+	serviceName string, port int32, appsHostnameSuffix string) runtime.Object {
+	if r.isOpenShift() {
+		return kubernetes.NewSecuredRoute(workshop, r.Scheme, name, namespace, labels, serviceName, port)
+	}
+	return kubernetes.NewSecuredIngress(workshop, r.Scheme, name, namespace, labels, serviceName, port,
+		kubernetes.RouteHost(name, namespace, appsHostnameSuffix))
+}
+
+// userSubject returns the RBAC subject of a user of the Workshop: the OpenShift user, the user of Dex
+// authenticated by the API server with the name claim, or the Service Account standing in for it on Kubernetes
+func (r *WorkshopReconciler) userSubject(workshop *workshopv1.Workshop, username string) rbac.Subject {
+	if r.isOpenShift() || isDexUser(workshop) {
+		return rbac.Subject{
+			Kind: rbac.UserKind,
+			Name: username,
+		}
+	}
+	return rbac.Subject{
+		Kind:      rbac.ServiceAccountKind,
+		Name:      username,
+		Namespace: util.ScopedName(workshop, USERS_NAMESPACE_NAME),
+	}
+}
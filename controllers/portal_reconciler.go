@@ -2,16 +2,21 @@ package controllers
 
 import (
 	"context"
-	"github.com/prometheus/common/log"
+	"fmt"
+
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/redis"
 	"github.com/stakater/workshop-operator/common/usernamedistribution"
 	"github.com/stakater/workshop-operator/common/util"
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -25,6 +30,9 @@ const (
 	PORTAL_DEPLOYMENT_NAME = "portal"
 	PORTAL_ROUTE_NAME      = "portal"
 	PORTAL_ROUTE_PORT      = 8080
+	// PORTAL_SERVICE_ACCOUNT_NAME and PORTAL_ASSIGNMENTS_NAME are scoped by Workshop, see util.ScopedName
+	PORTAL_SERVICE_ACCOUNT_NAME = "portal"
+	PORTAL_ASSIGNMENTS_NAME     = "portal-assignments"
 )
 
 var RedisLabels = map[string]string{
@@ -37,14 +45,33 @@ var RedisCredentials = map[string]string{
 }
 
 // reconcilePortal reconciles Portal
-func (r *WorkshopReconciler) reconcilePortal(workshop *workshopv1.Workshop, users int,
+func (r *WorkshopReconciler) reconcilePortal(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
 
-	if result, err := r.addRedis(workshop); util.IsRequeued(result, err) {
+	// The username-distribution image shares one password between all the users
+	if workshop.Spec.UserDetails.PasswordPolicy == workshopv1.PasswordPolicyGenerated {
+		// Redis is left by the Shared password policy
+		redisFound := &appsv1.Deployment{}
+		if err := r.Get(ctx, types.NamespacedName{Name: REDIS_DEPLOYMENT_NAME, Namespace: workshop.Namespace}, redisFound); err == nil {
+			if result, err := r.deleteRedis(ctx, workshop); util.IsRequeued(result, err) {
+				return result, err
+			}
+		} else if !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		return r.addCredentialsPortal(ctx, workshop, users, appsHostnameSuffix, openshiftConsoleURL)
+	}
+
+	// The access of the portal of the Generated password policy
+	if result, err := r.deleteCredentialsPortal(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if result, err := r.addRedis(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
 
-	if result, err := r.addUpdateUsernameDistribution(workshop, users, appsHostnameSuffix, openshiftConsoleURL); err != nil {
+	if result, err := r.addUpdateUsernameDistribution(ctx, workshop, appsHostnameSuffix, openshiftConsoleURL); err != nil {
 		return result, err
 	}
 
@@ -52,94 +79,128 @@ func (r *WorkshopReconciler) reconcilePortal(workshop *workshopv1.Workshop, user
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addRedis(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addRedis(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 	log.Info("Creating Redis")
 	secret := kubernetes.NewStringDataSecret(workshop, r.Scheme, REDIS_SECRET_NAME, workshop.Namespace, RedisLabels, RedisCredentials)
-	if err := r.Create(context.TODO(), secret); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, secret); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Secret", secret.Name)
 	}
 
 	persistentVolumeClaim := kubernetes.NewPersistentVolumeClaim(workshop, r.Scheme, REDIS_PVC_NAME, workshop.Namespace, RedisLabels, REDIS_VOLUME_SIZE)
-	if err := r.Create(context.TODO(), persistentVolumeClaim); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, persistentVolumeClaim); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Persistent Volume Claim", persistentVolumeClaim.Name)
 	}
 
 	// Deploy/Update UsernameDistribution
 	dep := redis.NewDeployment(workshop, r.Scheme, REDIS_DEPLOYMENT_NAME, workshop.Namespace, RedisLabels)
-	if err := r.Create(context.TODO(), dep); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, dep); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Deployment", dep.Name)
-	} else if errors.IsAlreadyExists(err) {
-		deploymentFound := &appsv1.Deployment{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: workshop.Namespace}, deploymentFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			if !reflect.DeepEqual(dep.Spec.Template.Spec.Containers[0].Env, deploymentFound.Spec.Template.Spec.Containers[0].Env) {
-				// Update Guide
-				if err := r.Update(context.TODO(), dep); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s Deployment", dep.Name)
-			}
-		}
 	}
 
 	// Create Service
 	service := kubernetes.NewService(workshop, r.Scheme, REDIS_SERVICE_NAME, workshop.Namespace, RedisLabels, []string{"http"}, []int32{6379})
-	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, service); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service", service.Name)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) addUpdateUsernameDistribution(workshop *workshopv1.Workshop,
-	users int, appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addUpdateUsernameDistribution(ctx context.Context, workshop *workshopv1.Workshop,
+	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 	log.Info("Creating portal")
 	// Deploy/Update UsernameDistribution
-	dep := usernamedistribution.NewDeployment(workshop, r.Scheme, PORTAL_DEPLOYMENT_NAME, RedisLabels, REDIS_SERVICE_NAME, users, appsHostnameSuffix, openshiftConsoleURL)
-	if err := r.Create(context.TODO(), dep); err != nil && !errors.IsAlreadyExists(err) {
+	dep := usernamedistribution.NewDeployment(workshop, r.Scheme, PORTAL_DEPLOYMENT_NAME, RedisLabels, REDIS_SERVICE_NAME, appsHostnameSuffix, openshiftConsoleURL)
+	if err := r.apply(ctx, dep); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Deployment", dep.Name)
-	} else if errors.IsAlreadyExists(err) {
-		deploymentFound := &appsv1.Deployment{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: workshop.Namespace}, deploymentFound); err != nil {
+	}
+
+	// Create Service
+	service := kubernetes.NewService(workshop, r.Scheme, PORTAL_SERVICE_NAME, workshop.Namespace, RedisLabels, []string{"http"}, []int32{8080})
+	if err := r.apply(ctx, service); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Create Route
+	route := r.newSecuredRoute(workshop, PORTAL_ROUTE_NAME, workshop.Namespace, RedisLabels, PORTAL_SERVICE_NAME, int32(PORTAL_ROUTE_PORT), appsHostnameSuffix)
+	if err := r.apply(ctx, route); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// addCredentialsPortal deploys the portal of a Workshop with generated passwords, which serves each attendee
+// the password of their user from its credentials Secret
+func (r *WorkshopReconciler) addCredentialsPortal(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	if r.PortalImage == "" {
+		return reconcile.Result{}, fmt.Errorf("the image of the portal is unknown, set OPERATOR_IMAGE on the operator")
+	}
+
+	// The portal reads the credentials Secrets, they must exist before
+	portalUsers := []usernamedistribution.User{}
+	secrets := []string{}
+	for _, user := range users {
+		if _, err := r.userPassword(ctx, workshop, user.Username); err != nil {
 			return reconcile.Result{}, err
-		} else if err == nil {
-			if !reflect.DeepEqual(dep.Spec.Template.Spec.Containers[0].Env, deploymentFound.Spec.Template.Spec.Containers[0].Env) ||
-				!reflect.DeepEqual(dep.Spec.Template.Spec.Containers[0].Image, deploymentFound.Spec.Template.Spec.Containers[0].Image) {
-				// Update Guide
-				if err := r.Update(context.TODO(), dep); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s Deployment", dep.Name)
-			}
 		}
+		secretName := userCredentialsSecretName(workshop, user.Username)
+		portalUsers = append(portalUsers, usernamedistribution.User{ID: user.ID, Username: user.Username, Secret: secretName})
+		secrets = append(secrets, secretName)
+	}
+
+	// The assignments are written by the portal, the reconcile only creates the ConfigMap
+	assignmentsName := util.ScopedName(workshop, PORTAL_ASSIGNMENTS_NAME)
+	assignments := kubernetes.NewConfigMap(workshop, r.Scheme, assignmentsName, workshop.Namespace, RedisLabels, nil)
+	if err := r.Create(ctx, assignments); err != nil && !errors.IsAlreadyExists(err) {
+		return reconcile.Result{}, err
+	} else if err == nil {
+		log.Info("Created", util.LogKind, "ConfigMap", util.LogName, assignments.Name)
+	}
+
+	serviceAccountName := util.ScopedName(workshop, PORTAL_SERVICE_ACCOUNT_NAME)
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, serviceAccountName, workshop.Namespace, RedisLabels)
+	if err := r.apply(ctx, serviceAccount); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	role := kubernetes.NewRole(workshop, r.Scheme, serviceAccountName, workshop.Namespace, RedisLabels,
+		usernamedistribution.NewRules(assignmentsName, secrets))
+	if err := r.apply(ctx, role); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	roleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, serviceAccountName, workshop.Namespace, RedisLabels,
+		serviceAccountName, role.Name, "Role")
+	if err := r.apply(ctx, roleBinding); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	dep, err := usernamedistribution.NewCredentialsDeployment(workshop, r.Scheme, PORTAL_DEPLOYMENT_NAME, RedisLabels,
+		r.PortalImage, serviceAccountName, assignmentsName, portalUsers, appsHostnameSuffix, openshiftConsoleURL)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.apply(ctx, dep); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	// Create Service
 	service := kubernetes.NewService(workshop, r.Scheme, PORTAL_SERVICE_NAME, workshop.Namespace, RedisLabels, []string{"http"}, []int32{8080})
-	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, service); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service", service.Name)
 	}
 
 	// Create Route
-	route := kubernetes.NewSecuredRoute(workshop, r.Scheme, PORTAL_ROUTE_NAME, workshop.Namespace, RedisLabels, PORTAL_SERVICE_NAME, int32(PORTAL_ROUTE_PORT))
-	if err := r.Create(context.TODO(), route); err != nil && !errors.IsAlreadyExists(err) {
+	route := r.newSecuredRoute(workshop, PORTAL_ROUTE_NAME, workshop.Namespace, RedisLabels, PORTAL_SERVICE_NAME, int32(PORTAL_ROUTE_PORT), appsHostnameSuffix)
+	if err := r.apply(ctx, route); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Route", route.Name)
 	}
 
 	//Success
@@ -147,49 +208,80 @@ func (r *WorkshopReconciler) addUpdateUsernameDistribution(workshop *workshopv1.
 }
 
 // delete Redis
-func (r *WorkshopReconciler) deletePortal(workshop *workshopv1.Workshop, userID int,
+func (r *WorkshopReconciler) deletePortal(ctx context.Context, workshop *workshopv1.Workshop,
 	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
 
-	if result, err := r.deleteUsernameDistribution(workshop, userID, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
+	if result, err := r.deleteUsernameDistribution(ctx, workshop, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
 		return result, err
 	}
-	if result, err := r.deleteRedis(workshop); util.IsRequeued(result, err) {
+	if result, err := r.deleteRedis(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
+	if result, err := r.deleteCredentialsPortal(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	return reconcile.Result{}, nil
+}
+
+// deleteCredentialsPortal deletes the access of the portal of a Workshop with generated passwords, and its assignments
+func (r *WorkshopReconciler) deleteCredentialsPortal(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	serviceAccountName := util.ScopedName(workshop, PORTAL_SERVICE_ACCOUNT_NAME)
+	assignmentsName := util.ScopedName(workshop, PORTAL_ASSIGNMENTS_NAME)
+	objects := []struct {
+		kind   string
+		object runtime.Object
+	}{
+		{"RoleBinding", &rbac.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: workshop.Namespace}}},
+		{"Role", &rbac.Role{ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: workshop.Namespace}}},
+		{"ServiceAccount", &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: serviceAccountName, Namespace: workshop.Namespace}}},
+		{"ConfigMap", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: assignmentsName, Namespace: workshop.Namespace}}},
+	}
+	for _, o := range objects {
+		if err := r.Delete(ctx, o.object); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			name, _ := meta.NewAccessor().Name(o.object)
+			log.Info("Deleted", util.LogKind, o.kind, util.LogName, name)
+		}
+	}
 
+	//Success
 	return reconcile.Result{}, nil
 }
 
 // delete Redis
-func (r *WorkshopReconciler) deleteRedis(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteRedis(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 	log.Info("Deleting Redis")
 	service := kubernetes.NewService(workshop, r.Scheme, REDIS_SERVICE_NAME, workshop.Namespace, RedisLabels, []string{"http"}, []int32{6379})
 	// Delete Service
-	if err := r.Delete(context.TODO(), service); err != nil {
+	if err := r.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Service", service.Name)
+	log.Info("Deleted", util.LogKind, "Service", util.LogName, service.Name)
 
 	dep := redis.NewDeployment(workshop, r.Scheme, REDIS_DEPLOYMENT_NAME, workshop.Namespace, RedisLabels)
 	// Delete Deployment
-	if err := r.Delete(context.TODO(), dep); err != nil {
+	if err := r.Delete(ctx, dep); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Deployment ", dep.Name)
+	log.Info("Deleted", util.LogKind, "Deployment", util.LogName, dep.Name)
 
 	persistentVolumeClaim := kubernetes.NewPersistentVolumeClaim(workshop, r.Scheme, REDIS_PVC_NAME, workshop.Namespace, RedisLabels, REDIS_VOLUME_SIZE)
 	// Delete persistentVolume Claim
-	if err := r.Delete(context.TODO(), persistentVolumeClaim); err != nil {
+	if err := r.Delete(ctx, persistentVolumeClaim); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Persistent Volume Claim", persistentVolumeClaim.Name)
+	log.Info("Deleted", util.LogKind, "PersistentVolumeClaim", util.LogName, persistentVolumeClaim.Name)
 
 	secret := kubernetes.NewStringDataSecret(workshop, r.Scheme, REDIS_SECRET_NAME, workshop.Namespace, RedisLabels, RedisCredentials)
 	// Delete secret
-	if err := r.Delete(context.TODO(), secret); err != nil {
+	if err := r.Delete(ctx, secret); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Secret", secret.Name)
+	log.Info("Deleted", util.LogKind, "Secret", util.LogName, secret.Name)
 	log.Info("Deleted Redis successfully")
 
 	//Success
@@ -197,35 +289,36 @@ func (r *WorkshopReconciler) deleteRedis(workshop *workshopv1.Workshop) (reconci
 }
 
 // delete UsernameDistribution
-func (r *WorkshopReconciler) deleteUsernameDistribution(workshop *workshopv1.Workshop,
-	users int, appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteUsernameDistribution(ctx context.Context, workshop *workshopv1.Workshop,
+	appsHostnameSuffix string, openshiftConsoleURL string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	log.Info("Deleting  portal ")
-	route := kubernetes.NewSecuredRoute(workshop, r.Scheme, PORTAL_ROUTE_NAME, workshop.Namespace, RedisLabels, PORTAL_SERVICE_NAME, int32(PORTAL_ROUTE_PORT))
+	log.Info("Deleting portal")
+	route := r.newSecuredRoute(workshop, PORTAL_ROUTE_NAME, workshop.Namespace, RedisLabels, PORTAL_SERVICE_NAME, int32(PORTAL_ROUTE_PORT), appsHostnameSuffix)
 	// Delete Route
-	if err := r.Delete(context.TODO(), route); err != nil {
+	if err := r.Delete(ctx, route); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Route", route.Name)
+	log.Info("Deleted", util.LogKind, "Route", util.LogName, PORTAL_ROUTE_NAME)
 
 	service := kubernetes.NewService(workshop, r.Scheme, PORTAL_SERVICE_NAME, workshop.Namespace, RedisLabels, []string{"http"}, []int32{8080})
 	// Delete Service
-	if err := r.Delete(context.TODO(), service); err != nil {
+	if err := r.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Service", service.Name)
+	log.Info("Deleted", util.LogKind, "Service", util.LogName, service.Name)
 
-	dep := usernamedistribution.NewDeployment(workshop, r.Scheme, PORTAL_DEPLOYMENT_NAME, RedisLabels, REDIS_SERVICE_NAME, users, appsHostnameSuffix, openshiftConsoleURL)
+	dep := usernamedistribution.NewDeployment(workshop, r.Scheme, PORTAL_DEPLOYMENT_NAME, RedisLabels, REDIS_SERVICE_NAME, appsHostnameSuffix, openshiftConsoleURL)
 	deploymentFound := &appsv1.Deployment{}
-	deploymentErr := r.Get(context.TODO(), types.NamespacedName{Name: dep.Name, Namespace: workshop.Namespace}, deploymentFound)
+	deploymentErr := r.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: workshop.Namespace}, deploymentFound)
 	if deploymentErr == nil {
 		// Delete Deployment
-		if err := r.Delete(context.TODO(), dep); err != nil {
+		if err := r.Delete(ctx, dep); err != nil && !errors.IsNotFound(err) {
 			return reconcile.Result{}, err
 		}
-		log.Infof("Deleted %s Deployment", dep.Name)
+		log.Info("Deleted", util.LogKind, "Deployment", util.LogName, dep.Name)
 	}
-	log.Info(" Deleted portal  successfully")
+	log.Info("Deleted portal successfully")
 	//Success
 	return reconcile.Result{}, nil
 }
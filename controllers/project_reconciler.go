@@ -3,15 +3,14 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/util"
 	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
-	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -20,38 +19,79 @@ var projectLabels = map[string]string{
 }
 
 const (
+	PROJECT_QUOTA_NAME            = "project-quota"
+	PROJECT_LIMIT_RANGE_NAME      = "project-limits"
 	USER_ROLE_BINDING_NAME        = "edit"
 	PROJECT_SERVICEACCOUNT_NAME   = "default"
 	DEFAULT_ROLE_BINDING_NAME     = "view"
 	ARGOCD_EDIT_ROLE_BINDING_NAME = "edit"
 )
 
-// Reconciling Project
-func (r *WorkshopReconciler) reconcileProject(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-	enabledProject := workshop.Spec.Infrastructure.Project.Enabled
-
-	id := 1
-	for {
-		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
-
-		if id <= users && enabledProject {
-			if workshop.Spec.Infrastructure.Project.StagingName != "" {
-				if result, err := r.addProject(workshop, stagingProjectName, username); util.IsRequeued(result, err) {
-					return result, err
-				}
-			}
+// PROJECT_QUOTA_REFRESH is how often the usage of the quotas of the projects is read again,
+// since the watches drop the status-only updates of the ResourceQuotas
+const PROJECT_QUOTA_REFRESH = time.Minute
 
-		} else {
-			stagingProjectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, stagingProjectName)
-			stagingProjectNamespaceFound := &corev1.Namespace{}
-			stagingProjectNamespaceErr := r.Get(context.TODO(), types.NamespacedName{Name: stagingProjectNamespace.Name}, stagingProjectNamespaceFound)
+// hasProjectQuotas returns true if the user projects get a ResourceQuota, whose usage the status reports
+func hasProjectQuotas(workshop *workshopv1.Workshop) bool {
+	project := workshop.Spec.Infrastructure.Project
+	if !project.Enabled || project.StagingName == "" {
+		return false
+	}
+	if project.Quota != nil {
+		return true
+	}
+	for _, size := range project.Sizes {
+		if size.Quota != nil {
+			return true
+		}
+	}
+	return false
+}
 
-			if stagingProjectNamespaceErr != nil && errors.IsNotFound(stagingProjectNamespaceErr) {
-				break
+// Reconciling Project
+func (r *WorkshopReconciler) reconcileProject(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	manifests, err := r.extraManifests(ctx, workshop)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	values := newManifestValues(workshop, users, appsHostnameSuffix)
+	applied := map[string][]workshopv1.ManifestReference{}
+
+	// Without user projects, the manifests of the users are neither applied nor pruned
+	if workshop.Spec.Infrastructure.Project.StagingName == "" {
+		for _, manifest := range manifests {
+			if manifest.scope == workshopv1.ManifestScopeUser {
+				return reconcile.Result{}, fmt.Errorf("the %s extra manifests of the users need the user projects, set project.stagingName",
+					manifest.name)
+			}
+		}
+	} else {
+		for _, user := range users {
+			stagingProjectName := util.ProjectName(workshop, user.Username)
+			result, err := r.addProject(ctx, workshop, stagingProjectName, user, attendeeStatus(workshop, user.Username))
+			if err == nil {
+				// The extra manifests of the user usually go in the project
+				err = r.applyExtraManifests(util.WithLogValues(ctx, util.LogUser, user.Username), workshop, manifests,
+					workshopv1.ManifestScopeUser, values.forUser(workshop, user.Username), applied)
+			}
+			setProvisioned(workshop, "Project", user.Username, err)
+			if err == nil {
+				attendeeStatus(workshop, user.Username).Project = stagingProjectName
+			}
+			if util.IsRequeued(result, err) {
+				return result, err
 			}
 		}
-		id++
+	}
+
+	if err := r.applyExtraManifests(ctx, workshop, manifests, workshopv1.ManifestScopeWorkshop, values, applied); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Delete the resources no longer rendered from the extra manifests
+	if err := r.pruneExtraManifests(ctx, workshop, applied); err != nil {
+		return reconcile.Result{}, err
 	}
 
 	//Success
@@ -59,66 +99,124 @@ func (r *WorkshopReconciler) reconcileProject(workshop *workshopv1.Workshop, use
 }
 
 // Add Project
-func (r *WorkshopReconciler) addProject(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
-	log.Infoln("Creating Project ")
+func (r *WorkshopReconciler) addProject(ctx context.Context, workshop *workshopv1.Workshop, projectName string, user util.Attendee,
+	attendee *workshopv1.AttendeeStatus) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, user.Username)
+	log.Info("Creating Project")
 	projectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, projectName)
-	if err := r.Create(context.TODO(), projectNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, projectNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Namespace", projectNamespace.Name)
 	}
 
-	if result, err := r.manageRoles(workshop, projectNamespace.Name, username); err != nil {
+	if result, err := r.manageRoles(ctx, workshop, projectNamespace.Name, user.Username); err != nil {
 		return result, err
 	}
 
+	if err := r.manageQuota(ctx, workshop, projectNamespace.Name, user, attendee); err != nil {
+		return reconcile.Result{}, err
+	}
+
 	//Success
 	return reconcile.Result{}, nil
 }
 
+// manageQuota applies the quota and the limit range of the size of the project, deletes the ones the spec
+// no longer sets, and reports the quota and its usage in the status of the user
+func (r *WorkshopReconciler) manageQuota(ctx context.Context, workshop *workshopv1.Workshop, projectName string, user util.Attendee,
+	attendee *workshopv1.AttendeeStatus) error {
+	log := util.Logger(ctx).WithValues(util.LogUser, user.Username)
+	quotaSpec, limitRangeSpec := projectLimits(workshop.Spec.Infrastructure.Project, user.ProjectSize)
+	attendee.ProjectSize = user.ProjectSize
+
+	// Create or delete the Resource Quota
+	if quotaSpec != nil {
+		quota := kubernetes.NewResourceQuota(workshop, r.Scheme, PROJECT_QUOTA_NAME, projectName, projectLabels, *quotaSpec)
+		if err := r.apply(ctx, quota); err != nil {
+			return err
+		}
+		// The quota controller counts the usage asynchronously, the status holds the last count
+		attendee.QuotaHard, attendee.QuotaUsed = quota.Status.Hard, quota.Status.Used
+	} else {
+		attendee.QuotaHard, attendee.QuotaUsed = nil, nil
+		quotaFound := &corev1.ResourceQuota{}
+		if err := kubernetes.GetObject(ctx, r, PROJECT_QUOTA_NAME, projectName, quotaFound); err != nil && !errors.IsNotFound(err) {
+			return err
+		} else if err == nil && util.IsOwnedBy(workshop, quotaFound.Labels) {
+			if err := r.Delete(ctx, quotaFound); err != nil && !errors.IsNotFound(err) {
+				return err
+			}
+			log.Info("Deleted", util.LogKind, "ResourceQuota", util.LogName, quotaFound.Name)
+		}
+	}
+
+	// Create or delete the Limit Range
+	if limitRangeSpec != nil {
+		limitRange := kubernetes.NewLimitRange(workshop, r.Scheme, PROJECT_LIMIT_RANGE_NAME, projectName, projectLabels, *limitRangeSpec)
+		return r.apply(ctx, limitRange)
+	}
+	limitRangeFound := &corev1.LimitRange{}
+	if err := kubernetes.GetObject(ctx, r, PROJECT_LIMIT_RANGE_NAME, projectName, limitRangeFound); err != nil && !errors.IsNotFound(err) {
+		return err
+	} else if err == nil && util.IsOwnedBy(workshop, limitRangeFound.Labels) {
+		if err := r.Delete(ctx, limitRangeFound); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		log.Info("Deleted", util.LogKind, "LimitRange", util.LogName, limitRangeFound.Name)
+	}
+	return nil
+}
+
+// projectLimits returns the quota and the limit range of the projects of the size, the default ones where the size sets none
+func projectLimits(project workshopv1.ProjectSpec, size string) (*corev1.ResourceQuotaSpec, *corev1.LimitRangeSpec) {
+	quota, limitRange := project.Quota, project.LimitRange
+	for _, projectSize := range project.Sizes {
+		if projectSize.Name != size {
+			continue
+		}
+		if projectSize.Quota != nil {
+			quota = projectSize.Quota
+		}
+		if projectSize.LimitRange != nil {
+			limitRange = projectSize.LimitRange
+		}
+	}
+	return quota, limitRange
+}
+
 // create Manage Roles
-func (r *WorkshopReconciler) manageRoles(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) manageRoles(ctx context.Context, workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
 
 	users := []rbac.Subject{}
-	userSubject := rbac.Subject{
-		Kind: rbac.UserKind,
-		Name: username,
-	}
+	userSubject := r.userSubject(workshop, username)
 
 	users = append(users, userSubject)
 
 	// Create User Role Binding
 	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username+"-project", projectName, projectLabels,
 		users, USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
-	if err := r.Create(context.TODO(), userRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, userRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", userRoleBinding.Name)
 	}
 
 	// Create Default Role Binding
 	defaultRoleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, username+"-default", projectName, projectLabels,
 		PROJECT_SERVICEACCOUNT_NAME, DEFAULT_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
-	if err := r.Create(context.TODO(), defaultRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, defaultRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", defaultRoleBinding.Name)
 	}
 
 	argocdUsers := []rbac.Subject{}
 	userSubject = rbac.Subject{
 		Kind: rbac.UserKind,
-		Name: "system:serviceaccount:argocd:argocd-argocd-application-controller",
+		Name: argocdApplicationControllerUser(workshop),
 	}
 	argocdUsers = append(argocdUsers, userSubject)
 
 	//Create Argo CD Role Binding
 	argocdEditRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
 		username+"-argocd", projectName, projectLabels, argocdUsers, ARGOCD_EDIT_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
-	if err := r.Create(context.TODO(), argocdEditRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, argocdEditRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", argocdEditRoleBinding.Name)
 	}
 
 	//Success
@@ -126,32 +224,21 @@ func (r *WorkshopReconciler) manageRoles(workshop *workshopv1.Workshop, projectN
 }
 
 // Delete Project
-func (r *WorkshopReconciler) deleteProject(workshop *workshopv1.Workshop, userId int) (reconcile.Result, error) {
-	enabledProject := workshop.Spec.Infrastructure.Project.Enabled
-	log.Infoln("Deleting Project ")
-	id := 1
-	for {
-		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
-
-		if id >= userId && enabledProject {
-			if workshop.Spec.Infrastructure.Project.StagingName != "" {
-				if result, err := r.deleteProjectNamespace(workshop, stagingProjectName, username); util.IsRequeued(result, err) {
-					return result, err
-				}
-			}
+func (r *WorkshopReconciler) deleteProject(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	log.Info("Deleting Project")
 
-		} else {
-			stagingProjectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, stagingProjectName)
-			stagingProjectNamespaceFound := &corev1.Namespace{}
-			stagingProjectNamespaceErr := r.Get(context.TODO(), types.NamespacedName{Name: stagingProjectNamespace.Name}, stagingProjectNamespaceFound)
+	if err := r.deleteExtraManifests(ctx, workshop); err != nil {
+		return reconcile.Result{}, err
+	}
 
-			if stagingProjectNamespaceErr != nil && errors.IsNotFound(stagingProjectNamespaceErr) {
-				break
+	if workshop.Spec.Infrastructure.Project.StagingName != "" {
+		for _, user := range users {
+			stagingProjectName := util.ProjectName(workshop, user.Username)
+			if result, err := r.deleteProjectNamespace(ctx, workshop, stagingProjectName, user.Username); util.IsRequeued(result, err) {
+				return result, err
 			}
 		}
-
-		id++
 	}
 
 	//Success
@@ -159,67 +246,66 @@ func (r *WorkshopReconciler) deleteProject(workshop *workshopv1.Workshop, userId
 }
 
 // delete Project
-func (r *WorkshopReconciler) deleteProjectNamespace(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteProjectNamespace(ctx context.Context, workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	projectNamespace := kubernetes.NewNamespace(workshop, r.Scheme, projectName)
 
-	if result, err := r.deleteManageRoles(workshop, projectNamespace.Name, username); err != nil {
+	if result, err := r.deleteManageRoles(ctx, workshop, projectNamespace.Name, username); err != nil {
 		return result, err
 	}
 
 	// Delete a Project
-	if err := r.Delete(context.TODO(), projectNamespace); err != nil {
+	if err := r.Delete(ctx, projectNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Namespace ", projectNamespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, projectNamespace.Name)
 
-	log.Infoln("Deleted Namespace successfully")
+	log.Info("Deleted Namespace successfully")
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // Delete Manage Roles
-func (r *WorkshopReconciler) deleteManageRoles(workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteManageRoles(ctx context.Context, workshop *workshopv1.Workshop, projectName string, username string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	users := []rbac.Subject{}
-	userSubject := rbac.Subject{
-		Kind: rbac.UserKind,
-		Name: username,
-	}
+	userSubject := r.userSubject(workshop, username)
 
 	users = append(users, userSubject)
 
 	argocdUsers := []rbac.Subject{}
 	userSubject = rbac.Subject{
 		Kind: rbac.UserKind,
-		Name: "system:serviceaccount:argocd:argocd-argocd-application-controller",
+		Name: argocdApplicationControllerUser(workshop),
 	}
 	argocdUsers = append(argocdUsers, userSubject)
 
 	argocdEditRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
 		username+"-argocd", projectName, projectLabels, argocdUsers, ARGOCD_EDIT_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
 	// Delete Argo CD Role Binding
-	if err := r.Delete(context.TODO(), argocdEditRoleBinding); err != nil {
+	if err := r.Delete(ctx, argocdEditRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role Binding", argocdEditRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, argocdEditRoleBinding.Name)
 
 	defaultRoleBinding := kubernetes.NewRoleBindingSA(workshop, r.Scheme, username+"-default", projectName, projectLabels,
 		PROJECT_SERVICEACCOUNT_NAME, DEFAULT_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
 	// Delete default Role Binding
-	if err := r.Delete(context.TODO(), defaultRoleBinding); err != nil {
+	if err := r.Delete(ctx, defaultRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role Binding", defaultRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, defaultRoleBinding.Name)
 
 	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username+"-project", projectName, projectLabels,
 		users, USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
 	// Delete user Role Binding
-	if err := r.Delete(context.TODO(), userRoleBinding); err != nil {
+	if err := r.Delete(ctx, userRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role Binding", userRoleBinding.Name)
-	log.Infoln("Deleted Manage Roles successfully")
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, userRoleBinding.Name)
+	log.Info("Deleted Manage Roles successfully")
 	//Success
 	return reconcile.Result{}, nil
 }
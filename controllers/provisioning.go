@@ -0,0 +1,198 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// provisionFunc provisions a user in the tool of a component and fills the status of the user.
+// The provisionFuncs run concurrently, each one only writes the status of its user.
+type provisionFunc func(ctx context.Context, user util.Attendee, attendee *workshopv1.AttendeeStatus) error
+
+// provisionUsers runs provision for the users with at most spec.provisioning.concurrency users at the same time,
+// and records the outcome for each user in the status. With once, the users already provisioned by the component
+// for the current generation of the Workshop are skipped, so that the retries only cover the failed users.
+// It returns the errors of the failed users, after all the users have been tried.
+func provisionUsers(ctx context.Context, workshop *workshopv1.Workshop, component string, users []util.Attendee,
+	once bool, provision provisionFunc) error {
+	log := util.Logger(ctx)
+
+	pending := []util.Attendee{}
+	for _, user := range users {
+		if once && isProvisioned(workshop, component, user.Username) {
+			continue
+		}
+		pending = append(pending, user)
+	}
+	if len(pending) < len(users) {
+		log.V(util.LogDebug).Info("Skipping the provisioned users", "provisioned", len(users)-len(pending))
+	}
+
+	concurrency := workshop.Spec.Provisioning.Concurrency
+	if concurrency <= 0 {
+		concurrency = workshopv1.DefaultProvisioningConcurrency
+	}
+
+	// The statuses are added before starting the workers, so that they don't move while the workers write them
+	attendees := make([]*workshopv1.AttendeeStatus, len(pending))
+	for _, user := range pending {
+		attendeeStatus(workshop, user.Username)
+	}
+	for i, user := range pending {
+		attendees[i] = attendeeStatus(workshop, user.Username)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		workers = make(chan struct{}, concurrency)
+		results = make([]error, len(pending))
+	)
+	for i, user := range pending {
+		// Wait for a free worker, or stop starting users once the reconciliation is cancelled
+		select {
+		case workers <- struct{}{}:
+		case <-ctx.Done():
+			results[i] = ctx.Err()
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, user util.Attendee) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			// A panic fails the user rather than the operator
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					results[i] = fmt.Errorf("provisioning panicked: %v", recovered)
+				}
+			}()
+			results[i] = provision(util.WithLogValues(ctx, util.LogUser, user.Username), user, attendees[i])
+		}(i, user)
+	}
+	wg.Wait()
+
+	errs := []error{}
+	for i, user := range pending {
+		setProvisioned(workshop, component, user.Username, results[i])
+		if results[i] != nil {
+			log.Error(results[i], "Failed to provision", util.LogUser, user.Username)
+			errs = append(errs, results[i])
+		}
+	}
+
+	return utilerrors.NewAggregate(errs)
+}
+
+// attendeeStatus returns the status of the user, added to the Workshop status if missing
+func attendeeStatus(workshop *workshopv1.Workshop, username string) *workshopv1.AttendeeStatus {
+	attendees := workshop.Status.Attendees
+	for i := range attendees {
+		if attendees[i].Username == username {
+			return &attendees[i]
+		}
+	}
+	workshop.Status.Attendees = append(attendees, workshopv1.AttendeeStatus{Username: username})
+	return &workshop.Status.Attendees[len(workshop.Status.Attendees)-1]
+}
+
+// isProvisioned returns true if the component provisioned the user for the current generation of the Workshop
+func isProvisioned(workshop *workshopv1.Workshop, component string, username string) bool {
+	for _, attendee := range workshop.Status.Attendees {
+		if attendee.Username == username {
+			generation, ok := attendee.Provisioned[component]
+			return ok && generation == workshop.Generation
+		}
+	}
+	return false
+}
+
+// setProvisioned records the outcome of the provisioning of the user by the component
+func setProvisioned(workshop *workshopv1.Workshop, component string, username string, err error) {
+	attendee := attendeeStatus(workshop, username)
+	if err != nil {
+		if attendee.Errors == nil {
+			attendee.Errors = map[string]string{}
+		}
+		attendee.Errors[component] = err.Error()
+		attendee.LastError = fmt.Sprintf("%s: %s", component, err)
+		delete(attendee.Provisioned, component)
+		if len(attendee.Provisioned) == 0 {
+			attendee.Provisioned = nil
+		}
+		return
+	}
+	if attendee.Provisioned == nil {
+		attendee.Provisioned = map[string]int64{}
+	}
+	attendee.Provisioned[component] = workshop.Generation
+	clearError(attendee, component)
+}
+
+// clearError drops the error of the component, the last error becomes the one of another failing component if any
+func clearError(attendee *workshopv1.AttendeeStatus, component string) {
+	delete(attendee.Errors, component)
+	if len(attendee.Errors) == 0 {
+		attendee.Errors = nil
+		attendee.LastError = ""
+		return
+	}
+	if strings.HasPrefix(attendee.LastError, component+": ") {
+		failing := make([]string, 0, len(attendee.Errors))
+		for name := range attendee.Errors {
+			failing = append(failing, name)
+		}
+		sort.Strings(failing)
+		attendee.LastError = fmt.Sprintf("%s: %s", failing[0], attendee.Errors[failing[0]])
+	}
+}
+
+// forgetProvisioned drops what a component which has been uninstalled reported about the users
+func forgetProvisioned(workshop *workshopv1.Workshop, component string) {
+	for i := range workshop.Status.Attendees {
+		attendee := &workshop.Status.Attendees[i]
+		switch component {
+		case "Users":
+			attendee.UserReady = false
+		case "Project":
+			attendee.Project, attendee.ProjectSize = "", ""
+			attendee.QuotaHard, attendee.QuotaUsed = nil, nil
+		case "Gitea":
+			attendee.GiteaAccount = false
+		case "GitOps":
+			attendee.ArgoCDAccount = false
+		case "CodeReadyWorkspace":
+			attendee.WorkspaceID, attendee.WorkspaceState = "", ""
+		case "Bookbag":
+			attendee.BookbagURL = ""
+		}
+		delete(attendee.Provisioned, component)
+		if len(attendee.Provisioned) == 0 {
+			attendee.Provisioned = nil
+		}
+		clearError(attendee, component)
+	}
+}
+
+// pruneAttendees drops the status of the users removed from the roster, and keeps the others in the order of the roster
+func pruneAttendees(workshop *workshopv1.Workshop, users []util.Attendee) {
+	statuses := map[string]workshopv1.AttendeeStatus{}
+	for _, attendee := range workshop.Status.Attendees {
+		statuses[attendee.Username] = attendee
+	}
+
+	var attendees []workshopv1.AttendeeStatus
+	for _, user := range users {
+		if attendee, ok := statuses[user.Username]; ok {
+			attendees = append(attendees, attendee)
+		}
+	}
+	workshop.Status.Attendees = attendees
+}
@@ -2,7 +2,6 @@ package controllers
 
 import (
 	"context"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/util"
@@ -11,14 +10,16 @@ import (
 )
 
 // Reconciling Serverless
-func (r *WorkshopReconciler) reconcileServerless(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	enabledServerless := workshop.Spec.Infrastructure.Serverless.Enabled
-
-	if enabledServerless {
-
-		if result, err := r.addServerless(workshop); util.IsRequeued(result, err) {
+func (r *WorkshopReconciler) reconcileServerless(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		if result, err := r.installHelmRelease(ctx, workshop, serverlessHelmRelease(workshop)); util.IsRequeued(result, err) {
 			return result, err
 		}
+		return r.addKnativeNamespaces(ctx, workshop)
+	}
+
+	if result, err := r.addServerless(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -32,42 +33,57 @@ const (
 	SERVERLESS_PACKAGE_NAME                = "serverless-operator"
 	KNATIVE_SERVING_NAMESPACE_NAME         = "knative-serving"
 	KNATIVE_EVENTING_NAMESPACE_NAME        = "knative-eventing"
+	SERVERLESS_HELM_RELEASE_NAME           = "knative-operator"
+	SERVERLESS_HELM_NAMESPACE_NAME         = "knative-operator"
+	SERVERLESS_HELM_REPOSITORY             = "https://knative.github.io/operator"
+	SERVERLESS_HELM_CHART                  = "knative-operator"
 )
 
 // Add Serverless
-func (r *WorkshopReconciler) addServerless(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addServerless(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	channel := workshop.Spec.Infrastructure.Serverless.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.Serverless.OperatorHub.ClusterServiceVersion
+	operatorHub := workshop.Spec.Infrastructure.Serverless.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, SERVERLESS_SUBSCRIPTION_NAMESPACE_NAME)
 
 	// Create Serverless Namespace
 	namespace := kubernetes.NewNamespace(workshop, r.Scheme, SERVERLESS_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), namespace); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, namespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Project", namespace.Name)
+		log.Info("Created", util.LogKind, "Project", util.LogName, namespace.Name)
 	}
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVERLESS_SUBSCRIPTION_NAME, SERVERLESS_SUBSCRIPTION_NAMESPACE_NAME, SERVERLESS_PACKAGE_NAME,
-		channel, clusterServiceVersion)
-	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVERLESS_SUBSCRIPTION_NAME, subscriptionNamespace, SERVERLESS_PACKAGE_NAME,
+		operatorHub)
+	if err := r.applyShared(ctx, workshop, subscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", subscription.Name)
 	}
 
+	// Approve the installation
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "Serverless", operatorHub.ClusterServiceVersion, SERVERLESS_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	return r.addKnativeNamespaces(ctx, workshop)
+}
+
+// addKnativeNamespaces creates the namespaces of Knative Serving and Knative Eventing
+func (r *WorkshopReconciler) addKnativeNamespaces(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
 	knativeServingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, KNATIVE_SERVING_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), knativeServingNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, knativeServingNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Namespace", knativeServingNamespace.Name)
+		log.Info("Created", util.LogKind, "Namespace", util.LogName, knativeServingNamespace.Name)
 	}
 
 	knativeEventingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, KNATIVE_EVENTING_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), knativeEventingNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, knativeEventingNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Namespace", knativeEventingNamespace.Name)
+		log.Info("Created", util.LogKind, "Namespace", util.LogName, knativeEventingNamespace.Name)
 	}
 
 	// TODO
@@ -79,41 +95,47 @@ func (r *WorkshopReconciler) addServerless(workshop *workshopv1.Workshop) (recon
 }
 
 // delete Serverless
-func (r *WorkshopReconciler) deleteServerless(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-
-	channel := workshop.Spec.Infrastructure.Serverless.OperatorHub.Channel
-	clusterServiceVersion := workshop.Spec.Infrastructure.Serverless.OperatorHub.ClusterServiceVersion
-
-	namespace := kubernetes.NewNamespace(workshop, r.Scheme, SERVERLESS_NAMESPACE_NAME)
-	knativeServingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, KNATIVE_SERVING_NAMESPACE_NAME)
-	knativeEventingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, KNATIVE_EVENTING_NAMESPACE_NAME)
-
-	//Delete knativeEventing Namespace
-	if err := r.Delete(context.TODO(), knativeEventingNamespace); err != nil {
+func (r *WorkshopReconciler) deleteServerless(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	// The Serverless installation is shared by the Workshops of the cluster
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "Serverless")
+	})
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Namespace", knativeEventingNamespace.Name)
+	if isUsed {
+		// Another Workshop takes over the release on Kubernetes
+		return r.handOverHelmReleases(ctx, workshop, "Serverless", serverlessHelmRelease(workshop))
+	}
 
-	//Delete knativeServing Namespace
-	if err := r.Delete(context.TODO(), knativeServingNamespace); err != nil {
-		return reconcile.Result{}, err
+	if result, err := r.deleteKnativeNamespaces(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if !r.isOpenShift() {
+		return r.uninstallHelmRelease(ctx, workshop, serverlessHelmRelease(workshop))
 	}
-	log.Infof("Deleted %s Namespace", knativeServingNamespace.Name)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVERLESS_SUBSCRIPTION_NAME, SERVERLESS_SUBSCRIPTION_NAMESPACE_NAME, SERVERLESS_PACKAGE_NAME,
-		channel, clusterServiceVersion)
+	operatorHub := workshop.Spec.Infrastructure.Serverless.OperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, SERVERLESS_SUBSCRIPTION_NAMESPACE_NAME)
+
+	namespace := kubernetes.NewNamespace(workshop, r.Scheme, SERVERLESS_NAMESPACE_NAME)
+
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVERLESS_SUBSCRIPTION_NAME, subscriptionNamespace, SERVERLESS_PACKAGE_NAME,
+		operatorHub)
 
 	//Delete subscription
-	if err := r.Delete(context.TODO(), subscription); err != nil {
+	if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", subscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
 
 	// Delete namespace
-	if err := r.Delete(context.TODO(), namespace); err != nil {
+	if err := r.Delete(ctx, namespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s namespace", namespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, namespace.Name)
 
 	//
 
@@ -124,3 +146,32 @@ func (r *WorkshopReconciler) deleteServerless(workshop *workshopv1.Workshop) (re
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// deleteKnativeNamespaces deletes the namespaces of Knative Serving and Knative Eventing
+func (r *WorkshopReconciler) deleteKnativeNamespaces(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	knativeServingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, KNATIVE_SERVING_NAMESPACE_NAME)
+	knativeEventingNamespace := kubernetes.NewNamespace(workshop, r.Scheme, KNATIVE_EVENTING_NAMESPACE_NAME)
+
+	//Delete knativeEventing Namespace
+	if err := r.Delete(ctx, knativeEventingNamespace); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, knativeEventingNamespace.Name)
+
+	//Delete knativeServing Namespace
+	if err := r.Delete(ctx, knativeServingNamespace); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, knativeServingNamespace.Name)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// serverlessHelmRelease returns the release of the Knative operator standing in for OpenShift Serverless on Kubernetes
+func serverlessHelmRelease(workshop *workshopv1.Workshop) helmRelease {
+	return newHelmRelease(SERVERLESS_HELM_RELEASE_NAME, SERVERLESS_HELM_NAMESPACE_NAME, workshop.Spec.Infrastructure.Serverless.Helm,
+		SERVERLESS_HELM_REPOSITORY, SERVERLESS_HELM_CHART, nil)
+}
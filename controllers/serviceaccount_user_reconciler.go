@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+const (
+	USERS_NAMESPACE_NAME = "users"
+)
+
+// reconcileServiceAccountUsers gives each user a Service Account and its token on Kubernetes,
+// which has no user API, and deletes the Service Accounts of the users no longer in the roster
+func (r *WorkshopReconciler) reconcileServiceAccountUsers(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	usersNamespaceName := util.ScopedName(workshop, USERS_NAMESPACE_NAME)
+
+	// The users had a password in Dex before
+	if result, err := r.deleteDexUsers(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	usersNamespace := kubernetes.NewNamespace(workshop, r.Scheme, usersNamespaceName)
+	if err := r.apply(ctx, usersNamespace); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	serviceAccounts, err := r.ownedServiceAccountUsers(ctx, workshop, usersNamespaceName)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	existing := make(map[string]bool, len(serviceAccounts.Items))
+	for _, serviceAccount := range serviceAccounts.Items {
+		existing[serviceAccount.Name] = true
+	}
+
+	roster := make(map[string]bool, len(users))
+	for provisioned, user := range users {
+		roster[user.Username] = true
+		result, err := r.addServiceAccountUser(ctx, workshop, usersNamespaceName, user.Username)
+		setProvisioned(workshop, "Users", user.Username, err)
+		attendeeStatus(workshop, user.Username).UserReady = err == nil
+		if util.IsRequeued(result, err) {
+			if err != nil {
+				r.warningEvent(workshop, EventUserFailed, "Users", user.Username, "Failed to create the Service Account: %s", err)
+			}
+			recordUsers(workshop, len(users), provisioned)
+			return result, err
+		}
+		if !existing[user.Username] {
+			r.normalEvent(workshop, EventUserCreated, "Users", user.Username, "Created the Service Account")
+		}
+	}
+	recordUsers(workshop, len(users), len(users))
+
+	for _, serviceAccount := range serviceAccounts.Items {
+		if roster[serviceAccount.Name] {
+			continue
+		}
+		if result, err := r.deleteServiceAccountUser(ctx, workshop, usersNamespaceName, serviceAccount.Name); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	if result, err := r.deleteUserCredentials(ctx, workshop, roster); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// addServiceAccountUser creates the Service Account of a user, its token and its role binding
+func (r *WorkshopReconciler) addServiceAccountUser(ctx context.Context, workshop *workshopv1.Workshop, usersNamespaceName string, username string) (reconcile.Result, error) {
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, username, usersNamespaceName, userLabels)
+	if err := r.apply(ctx, serviceAccount); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	tokenSecret := kubernetes.NewServiceAccountTokenSecret(workshop, r.Scheme, username+"-token", usersNamespaceName, userLabels, username)
+	if err := r.apply(ctx, tokenSecret); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username, USER_ROLE_BINDING_NAMESPACE_NAME, userLabels,
+		[]rbac.Subject{r.userSubject(workshop, username)}, USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
+	if err := r.apply(ctx, userRoleBinding); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteServiceAccountUsers deletes the Service Accounts of the users with their namespace
+func (r *WorkshopReconciler) deleteServiceAccountUsers(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	usersNamespaceName := util.ScopedName(workshop, USERS_NAMESPACE_NAME)
+
+	serviceAccounts, err := r.ownedServiceAccountUsers(ctx, workshop, usersNamespaceName)
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	for _, serviceAccount := range serviceAccounts.Items {
+		if result, err := r.deleteServiceAccountUser(ctx, workshop, usersNamespaceName, serviceAccount.Name); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	usersNamespace := kubernetes.NewNamespace(workshop, r.Scheme, usersNamespaceName)
+	if err := r.Delete(ctx, usersNamespace); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, usersNamespace.Name)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteServiceAccountUser deletes the Service Account of a user and its role binding,
+// Kubernetes deletes the token with the Service Account
+func (r *WorkshopReconciler) deleteServiceAccountUser(ctx context.Context, workshop *workshopv1.Workshop, usersNamespaceName string, username string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
+	userRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme, username, USER_ROLE_BINDING_NAMESPACE_NAME, userLabels,
+		nil, USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
+	if err := r.Delete(ctx, userRoleBinding); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, userRoleBinding.Name)
+
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, username, usersNamespaceName, userLabels)
+	if err := r.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, serviceAccount.Name)
+	r.passwords.Forget(workshop.UID, username)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// ownedServiceAccountUsers returns the Service Accounts created by the Workshop for its users
+func (r *WorkshopReconciler) ownedServiceAccountUsers(ctx context.Context, workshop *workshopv1.Workshop, usersNamespaceName string) (*corev1.ServiceAccountList, error) {
+	log := util.Logger(ctx)
+	serviceAccounts := &corev1.ServiceAccountList{}
+	listOps := &client.ListOptions{
+		Namespace:     usersNamespaceName,
+		LabelSelector: labels.SelectorFromSet(labels.Set{util.WorkshopUIDLabel: string(workshop.UID)}),
+	}
+	if err := r.List(ctx, serviceAccounts, listOps); err != nil {
+		log.Error(err, "Failed to list", util.LogKind, "ServiceAccount", "labelSelector", listOps.LabelSelector)
+		return serviceAccounts, err
+	}
+	return serviceAccounts, nil
+}
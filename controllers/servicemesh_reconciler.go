@@ -7,17 +7,17 @@ import (
 	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
 	maistrav2 "github.com/maistra/istio-operator/pkg/apis/maistra/v2"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/maistra"
 	"github.com/stakater/workshop-operator/common/util"
 	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
 	rbac "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
-	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
@@ -50,6 +50,19 @@ const (
 	ISTIO_OPERATOR_NAME                       = "istio-operator"
 	ISTIO_OPERATOR_NAMESPACE_NAME             = "openshift-operators"
 	ISTIO_NAMESPACE_NAME                      = "istio-system"
+	ISTIO_HELM_REPOSITORY                     = "https://istio-release.storage.googleapis.com/charts"
+	ISTIO_BASE_HELM_RELEASE_NAME              = "istio-base"
+	ISTIO_BASE_HELM_CHART                     = "base"
+	ISTIOD_HELM_RELEASE_NAME                  = "istiod"
+	ISTIOD_HELM_CHART                         = "istiod"
+	ISTIO_INJECTION_LABEL                     = "istio-injection"
+	JAEGER_HELM_RELEASE_NAME                  = "jaeger"
+	JAEGER_HELM_REPOSITORY                    = "https://jaegertracing.github.io/helm-charts"
+	JAEGER_HELM_CHART                         = "jaeger"
+	KIALI_HELM_RELEASE_NAME                   = "kiali-server"
+	KIALI_HELM_REPOSITORY                     = "https://kiali.org/helm-charts"
+	KIALI_HELM_CHART                          = "kiali-server"
+	KIALI_SERVICE_PORT                        = 20001
 )
 
 var istioLabels = map[string]string{
@@ -57,27 +70,26 @@ var istioLabels = map[string]string{
 }
 
 // Reconciling ServiceMesh
-func (r *WorkshopReconciler) reconcileServiceMesh(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-	enabledServiceMesh := workshop.Spec.Infrastructure.ServiceMesh.Enabled
-	enabledServerless := workshop.Spec.Infrastructure.Serverless.Enabled
-
-	if enabledServiceMesh || enabledServerless {
+func (r *WorkshopReconciler) reconcileServiceMesh(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		return r.addKubernetesServiceMesh(ctx, workshop, users, appsHostnameSuffix)
+	}
 
-		if result, err := r.addElasticSearchOperator(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addElasticSearchOperator(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
 
-		if result, err := r.addJaegerOperator(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addJaegerOperator(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
 
-		if result, err := r.addKialiOperator(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addKialiOperator(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
 
-		if result, err := r.addServiceMesh(workshop, users); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addServiceMesh(ctx, workshop, users); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -85,34 +97,30 @@ func (r *WorkshopReconciler) reconcileServiceMesh(workshop *workshopv1.Workshop,
 }
 
 // Add ServiceMesh
-func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addServiceMesh(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	istioNamespaceName := util.ScopedName(workshop, ISTIO_NAMESPACE_NAME)
 
-	channel := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.ClusterServiceVersion
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVICE_MESH_SUBSCRIPTION_NAME, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME,
-		SERVICE_MESH_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
-	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVICE_MESH_SUBSCRIPTION_NAME, subscriptionNamespace,
+		SERVICE_MESH_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+	if err := r.applyShared(ctx, workshop, subscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", subscription.Name)
 	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, SERVICE_MESH_SUBSCRIPTION_NAME, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME); err != nil {
-		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "ServiceMesh", operatorHub.ClusterServiceVersion, SERVICE_MESH_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	// Wait for Operator to be running
-	if !kubernetes.GetK8Client().GetDeploymentStatus(ISTIO_OPERATOR_NAME, ISTIO_OPERATOR_NAMESPACE_NAME) {
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := kubernetes.CheckDeploymentReady(ctx, r, ISTIO_OPERATOR_NAME, ISTIO_OPERATOR_NAMESPACE_NAME); util.IsRequeued(result, err) {
+		return result, err
 	}
 
-	istioSystemNamespace := kubernetes.NewNamespace(workshop, r.Scheme, ISTIO_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), istioSystemNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	istioSystemNamespace := kubernetes.NewNamespace(workshop, r.Scheme, istioNamespaceName)
+	if err := r.apply(ctx, istioSystemNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Namespace", istioSystemNamespace.Name)
 	}
 
 	istioMembers := []string{}
@@ -121,15 +129,15 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	if workshop.Spec.Infrastructure.GitOps.Enabled {
 		argocdSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
-			Name:     "system:serviceaccount:argocd:argocd-argocd-application-controller",
+			Name:     argocdApplicationControllerUser(workshop),
 			APIGroup: "rbac.authorization.k8s.io",
 		}
 		istioUsers = append(istioUsers, argocdSubject)
 	}
 
-	for id := 1; id <= users; id++ {
-		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
+	for _, user := range users {
+		username := user.Username
+		stagingProjectName := util.ProjectName(workshop, username)
 		userSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
 			Name:     username,
@@ -141,99 +149,61 @@ func (r *WorkshopReconciler) addServiceMesh(workshop *workshopv1.Workshop, users
 	}
 
 	jaegerRole := kubernetes.NewRole(workshop, r.Scheme,
-		JAEGER_ROLE_NAME, JAEGER_ROLE_NAMESPACE_NAME, istioLabels, kubernetes.JaegerUserRules())
-	if err := r.Create(context.TODO(), jaegerRole); err != nil && !errors.IsAlreadyExists(err) {
+		JAEGER_ROLE_NAME, istioNamespaceName, istioLabels, kubernetes.JaegerUserRules())
+	if err := r.apply(ctx, jaegerRole); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role", jaegerRole.Name)
 	}
 
 	jaegerRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-		JAEGER_ROLE_BINDING_NAME, JAEGER_ROLE_BINDING_NAMESPACE_NAME, istioLabels, istioUsers, jaegerRole.Name, JAEGER_ROLE_KIND_NAME)
-	if err := r.Create(context.TODO(), jaegerRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+		JAEGER_ROLE_BINDING_NAME, istioNamespaceName, istioLabels, istioUsers, jaegerRole.Name, JAEGER_ROLE_KIND_NAME)
+	if err := r.apply(ctx, jaegerRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", jaegerRoleBinding.Name)
-	} else if errors.IsAlreadyExists(err) {
-		found := &rbac.RoleBinding{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: jaegerRoleBinding.Name, Namespace: istioSystemNamespace.Name}, found); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			if !reflect.DeepEqual(istioUsers, found.Subjects) {
-				found.Subjects = istioUsers
-				if err := r.Update(context.TODO(), found); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s Role Binding", found.Name)
-			}
-		}
 	}
 
 	meshUserRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-		SERVICE_MESH_ROLE_BINDING_NAME, SERVICE_MESH_ROLE_BINDING_NAMESPACE_NAME, istioLabels, istioUsers, SERVICE_MESH_ROLE_NAME, SERVICE_MESH_ROLE_KIND_NAME)
+		SERVICE_MESH_ROLE_BINDING_NAME, istioNamespaceName, istioLabels, istioUsers, SERVICE_MESH_ROLE_NAME, SERVICE_MESH_ROLE_KIND_NAME)
 
-	if err := r.Create(context.TODO(), meshUserRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, meshUserRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", meshUserRoleBinding.Name)
 	}
 
 	serviceMeshControlPlaneCR := maistra.NewServiceMeshControlPlaneCR(workshop, r.Scheme, SERVICE_MESH_CONTROL_PLANE_NAME, istioSystemNamespace.Name)
-	if err := r.Create(context.TODO(), serviceMeshControlPlaneCR); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, serviceMeshControlPlaneCR); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Service Mesh Control Plane Custom Resource", serviceMeshControlPlaneCR.Name)
 	}
 
 	serviceMeshMemberRollCR := maistra.NewServiceMeshMemberRollCR(workshop, r.Scheme,
 		SERVICE_MESH_MEMBER_ROLL_NAME, istioSystemNamespace.Name, istioMembers)
-	if err := r.Create(context.TODO(), serviceMeshMemberRollCR); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, serviceMeshMemberRollCR); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Custom Resource", serviceMeshMemberRollCR.Name)
-	} else if errors.IsAlreadyExists(err) {
-		serviceMeshMemberRollCRFound := &maistrav1.ServiceMeshMemberRoll{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: serviceMeshMemberRollCR.Name, Namespace: istioSystemNamespace.Name}, serviceMeshMemberRollCRFound); err != nil {
-			return reconcile.Result{}, err
-		} else if err == nil {
-			if !reflect.DeepEqual(istioMembers, serviceMeshMemberRollCRFound.Spec.Members) {
-				serviceMeshMemberRollCRFound.Spec.Members = istioMembers
-				if err := r.Update(context.TODO(), serviceMeshMemberRollCRFound); err != nil {
-					return reconcile.Result{}, err
-				}
-				log.Infof("Updated %s Service Mesh Member Roll Custom Resource", serviceMeshMemberRollCRFound.Name)
-			}
-		}
 	}
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // Add ElasticSearchOperator
-func (r *WorkshopReconciler) addElasticSearchOperator(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addElasticSearchOperator(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	channel := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.ClusterServiceVersion
-	subcriptionName := fmt.Sprintf("elasticsearch-operator-%s", channel)
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, ELASTICSEARCH_SUBSCRIPTION_NAMESPACE_NAME)
+	subcriptionName := fmt.Sprintf("elasticsearch-operator-%s", operatorHub.Channel)
 
 	redhatOperatorsNamespace := kubernetes.NewNamespace(workshop, r.Scheme, OPERATOR_REDHAT_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), redhatOperatorsNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, redhatOperatorsNamespace); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Namespace", redhatOperatorsNamespace.Name)
+		log.Info("Created", util.LogKind, "Namespace", util.LogName, redhatOperatorsNamespace.Name)
 	}
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, subcriptionName, ELASTICSEARCH_SUBSCRIPTION_NAMESPACE_NAME,
-		ELASTICSEARCH_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
-	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, subcriptionName, subscriptionNamespace,
+		ELASTICSEARCH_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+	if err := r.applyShared(ctx, workshop, subscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", subscription.Name)
 	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, subcriptionName, ELASTICSEARCH_SUBSCRIPTION_NAMESPACE_NAME); err != nil {
-		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "ServiceMesh", operatorHub.ClusterServiceVersion, subcriptionName, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -241,22 +211,18 @@ func (r *WorkshopReconciler) addElasticSearchOperator(workshop *workshopv1.Works
 }
 
 // Add JaegerOperator
-func (r *WorkshopReconciler) addJaegerOperator(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-
-	channel := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.ClusterServiceVersion
+func (r *WorkshopReconciler) addJaegerOperator(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, JAEGER_SUBSCRIPTION_NAMESPACE_NAME)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, JAEGER_SUBSCRIPTION_NAME, JAEGER_SUBSCRIPTION_NAMESPACE_NAME,
-		JAEGER_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
-	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, JAEGER_SUBSCRIPTION_NAME, subscriptionNamespace,
+		JAEGER_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+	if err := r.applyShared(ctx, workshop, subscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", subscription.Name)
 	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, JAEGER_SUBSCRIPTION_NAME, JAEGER_SUBSCRIPTION_NAMESPACE_NAME); err != nil {
-		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "ServiceMesh", operatorHub.ClusterServiceVersion, JAEGER_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -264,58 +230,75 @@ func (r *WorkshopReconciler) addJaegerOperator(workshop *workshopv1.Workshop) (r
 }
 
 // Add KialiOperator
-func (r *WorkshopReconciler) addKialiOperator(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addKialiOperator(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, KIALI_SUBSCRIPTION_NAMESPACE_NAME)
 
-	channel := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.ClusterServiceVersion
-
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, KIALI_SUBSCRIPTION_NAME, KIALI_SUBSCRIPTION_NAMESPACE_NAME,
-		KIALI_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
-	if err := r.Create(context.TODO(), subscription); err != nil && !errors.IsAlreadyExists(err) {
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, KIALI_SUBSCRIPTION_NAME, subscriptionNamespace,
+		KIALI_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
+	if err := r.applyShared(ctx, workshop, subscription); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Subscription", subscription.Name)
 	}
 
-	if err := r.ApproveInstallPlan(clusterserviceversion, KIALI_SUBSCRIPTION_NAME, KIALI_SUBSCRIPTION_NAMESPACE_NAME); err != nil {
-		log.Infof("Waiting for Subscription to create InstallPlan for %s", subscription.Name)
-		return reconcile.Result{Requeue: true}, nil
+	if result, err := r.ApproveInstallPlan(ctx, workshop, "ServiceMesh", operatorHub.ClusterServiceVersion, KIALI_SUBSCRIPTION_NAME, subscriptionNamespace); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) deleteServiceMeshService(workshop *workshopv1.Workshop, userID int) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteServiceMeshService(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	servicemeshCSV, JaegerCSV, kialiCSV, err := r.getCSV(workshop)
-	if err != nil {
-		log.Error("Failed to get ClusterServiceVersion")
+	if !r.isOpenShift() {
+		return r.deleteKubernetesServiceMesh(ctx, workshop, users, appsHostnameSuffix)
 	}
 
-	if result, err := r.deleteServiceMesh(workshop, userID); util.IsRequeued(result, err) {
-		return result, err
+	servicemeshCSV, JaegerCSV, kialiCSV, err := r.getCSV(ctx, workshop)
+	if err != nil {
+		log.Error(err, "Failed to get the ClusterServiceVersions")
 	}
-	if result, err := r.deleteKialiSubscription(workshop); util.IsRequeued(result, err) {
+
+	if result, err := r.deleteServiceMesh(ctx, workshop, users); util.IsRequeued(result, err) {
 		return result, err
 	}
-	if result, err := r.deleteJaegerSubscription(workshop); util.IsRequeued(result, err) {
-		return result, err
+
+	// The operators are shared by the Workshops of the cluster
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "ServiceMesh")
+	})
+	if err != nil {
+		return reconcile.Result{}, err
 	}
+	if !isUsed {
+		if result, err := r.deleteServiceMeshOperator(ctx, workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
+		if result, err := r.deleteKialiSubscription(ctx, workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
+		if result, err := r.deleteJaegerSubscription(ctx, workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
 
-	if result, err := r.deleteCSV(workshop, servicemeshCSV, kialiCSV, JaegerCSV); util.IsRequeued(result, err) {
-		return result, err
+		if result, err := r.deleteCSV(ctx, workshop, servicemeshCSV, kialiCSV, JaegerCSV); util.IsRequeued(result, err) {
+			return result, err
+		}
 	}
 
-	if result, err := r.deleteIstioSystemNamespace(workshop); util.IsRequeued(result, err) {
+	if result, err := r.deleteIstioSystemNamespace(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
 
-	if result, err := r.deleteElasticSearchOperator(workshop); util.IsRequeued(result, err) {
-		return result, err
+	if !isUsed {
+		if result, err := r.deleteElasticSearchOperator(ctx, workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
 	}
 
-	if result, err := r.PatchIstioProject(workshop); util.IsRequeued(result, err) {
+	if result, err := r.PatchIstioProject(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -323,10 +306,9 @@ func (r *WorkshopReconciler) deleteServiceMeshService(workshop *workshopv1.Works
 }
 
 // Delete ServiceMesh
-func (r *WorkshopReconciler) deleteServiceMesh(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-
-	channel := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub.ClusterServiceVersion
+func (r *WorkshopReconciler) deleteServiceMesh(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	istioNamespaceName := util.ScopedName(workshop, ISTIO_NAMESPACE_NAME)
 
 	istioMembers := []string{}
 	istioUsers := []rbac.Subject{}
@@ -334,15 +316,15 @@ func (r *WorkshopReconciler) deleteServiceMesh(workshop *workshopv1.Workshop, us
 	if workshop.Spec.Infrastructure.GitOps.Enabled {
 		argocdSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
-			Name:     "system:serviceaccount:argocd:argocd-argocd-application-controller",
+			Name:     argocdApplicationControllerUser(workshop),
 			APIGroup: "rbac.authorization.k8s.io",
 		}
 		istioUsers = append(istioUsers, argocdSubject)
 	}
 
-	for id := 1; id <= users; id++ {
-		username := fmt.Sprintf("user%d", id)
-		stagingProjectName := fmt.Sprintf("%s%d", workshop.Spec.Infrastructure.Project.StagingName, id)
+	for _, user := range users {
+		username := user.Username
+		stagingProjectName := util.ProjectName(workshop, username)
 		userSubject := rbac.Subject{
 			Kind:     rbac.UserKind,
 			Name:     username,
@@ -354,52 +336,63 @@ func (r *WorkshopReconciler) deleteServiceMesh(workshop *workshopv1.Workshop, us
 	}
 
 	jaegerRole := kubernetes.NewRole(workshop, r.Scheme,
-		JAEGER_ROLE_NAME, JAEGER_ROLE_NAMESPACE_NAME, istioLabels, kubernetes.JaegerUserRules())
+		JAEGER_ROLE_NAME, istioNamespaceName, istioLabels, kubernetes.JaegerUserRules())
 
 	serviceMeshMemberRollCR := maistra.NewServiceMeshMemberRollCR(workshop, r.Scheme,
-		SERVICE_MESH_MEMBER_ROLL_NAME, ISTIO_NAMESPACE_NAME, istioMembers)
+		SERVICE_MESH_MEMBER_ROLL_NAME, istioNamespaceName, istioMembers)
 	// Delete Service MeshMember Roll Custom Resource
-	if err := r.Delete(context.TODO(), serviceMeshMemberRollCR); err != nil {
+	if err := r.Delete(ctx, serviceMeshMemberRollCR); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Service MeshMember Roll Custom Resource", serviceMeshMemberRollCR.Name)
+	log.Info("Deleted", util.LogKind, "ServiceMeshMemberRoll", util.LogName, serviceMeshMemberRollCR.Name)
 
-	serviceMeshControlPlaneCR := maistra.NewServiceMeshControlPlaneCR(workshop, r.Scheme, SERVICE_MESH_CONTROL_PLANE_NAME, ISTIO_NAMESPACE_NAME)
+	serviceMeshControlPlaneCR := maistra.NewServiceMeshControlPlaneCR(workshop, r.Scheme, SERVICE_MESH_CONTROL_PLANE_NAME, istioNamespaceName)
 	// Delete Service Mesh Control Plane Custom Resource
-	if err := r.Delete(context.TODO(), serviceMeshControlPlaneCR); err != nil {
+	if err := r.Delete(ctx, serviceMeshControlPlaneCR); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Service Mesh Control Plane Custom Resource", serviceMeshControlPlaneCR.Name)
+	log.Info("Deleted", util.LogKind, "ServiceMeshControlPlane", util.LogName, serviceMeshControlPlaneCR.Name)
 
 	meshUserRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-		SERVICE_MESH_ROLE_BINDING_NAME, SERVICE_MESH_ROLE_BINDING_NAMESPACE_NAME, istioLabels, istioUsers, SERVICE_MESH_ROLE_NAME, SERVICE_MESH_ROLE_KIND_NAME)
+		SERVICE_MESH_ROLE_BINDING_NAME, istioNamespaceName, istioLabels, istioUsers, SERVICE_MESH_ROLE_NAME, SERVICE_MESH_ROLE_KIND_NAME)
 	// Delete RoleBinding
-	if err := r.Delete(context.TODO(), meshUserRoleBinding); err != nil {
+	if err := r.Delete(ctx, meshUserRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role Binding", meshUserRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, meshUserRoleBinding.Name)
 
 	jaegerRoleBinding := kubernetes.NewRoleBindingUsers(workshop, r.Scheme,
-		JAEGER_ROLE_BINDING_NAME, JAEGER_ROLE_BINDING_NAMESPACE_NAME, istioLabels, istioUsers, jaegerRole.Name, JAEGER_ROLE_KIND_NAME)
+		JAEGER_ROLE_BINDING_NAME, istioNamespaceName, istioLabels, istioUsers, jaegerRole.Name, JAEGER_ROLE_KIND_NAME)
 	// Delete RoleBinding
-	if err := r.Delete(context.TODO(), jaegerRoleBinding); err != nil {
+	if err := r.Delete(ctx, jaegerRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role Binding", jaegerRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, jaegerRoleBinding.Name)
 
 	// Delete Role
-	if err := r.Delete(context.TODO(), jaegerRole); err != nil {
+	if err := r.Delete(ctx, jaegerRole); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role", jaegerRole.Name)
+	log.Info("Deleted", util.LogKind, "Role", util.LogName, jaegerRole.Name)
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// Delete ServiceMeshOperator
+func (r *WorkshopReconciler) deleteServiceMeshOperator(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVICE_MESH_SUBSCRIPTION_NAME, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME,
-		SERVICE_MESH_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.ServiceMeshOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME)
+
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, SERVICE_MESH_SUBSCRIPTION_NAME, subscriptionNamespace,
+		SERVICE_MESH_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
 	// Delete Subscription
-	if err := r.Delete(context.TODO(), subscription); err != nil {
+	if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", subscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
 
 	vwc := &admissionregistration.ValidatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -409,10 +402,10 @@ func (r *WorkshopReconciler) deleteServiceMesh(workshop *workshopv1.Workshop, us
 	}
 
 	// Delete ValidatingWebhookConfiguration
-	if err := r.Delete(context.TODO(), vwc); err != nil {
+	if err := r.Delete(ctx, vwc); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("deleted %s ValidatingWebhookConfiguration", vwc.Name)
+	log.Info("Deleted", util.LogKind, "ValidatingWebhookConfiguration", util.LogName, vwc.Name)
 
 	mwc := &admissionregistration.MutatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
@@ -421,189 +414,326 @@ func (r *WorkshopReconciler) deleteServiceMesh(workshop *workshopv1.Workshop, us
 		},
 	}
 	// Delete MutatingWebhookConfiguration
-	if err := r.Delete(context.TODO(), mwc); err != nil {
+	if err := r.Delete(ctx, mwc); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("deleted %s MutatingWebhookConfiguration", mwc.Name)
+	log.Info("Deleted", util.LogKind, "MutatingWebhookConfiguration", util.LogName, mwc.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // Delete KialiSubscription
-func (r *WorkshopReconciler) deleteKialiSubscription(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteKialiSubscription(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	channel := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub.ClusterServiceVersion
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.KialiOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, KIALI_SUBSCRIPTION_NAMESPACE_NAME)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, KIALI_SUBSCRIPTION_NAME, KIALI_SUBSCRIPTION_NAMESPACE_NAME,
-		KIALI_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, KIALI_SUBSCRIPTION_NAME, subscriptionNamespace,
+		KIALI_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
 	// Delete Subscription
-	if err := r.Delete(context.TODO(), subscription); err != nil {
+	if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", subscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // Delete JaegerSubscription
-func (r *WorkshopReconciler) deleteJaegerSubscription(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteJaegerSubscription(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	channel := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub.ClusterServiceVersion
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.JaegerOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, JAEGER_SUBSCRIPTION_NAMESPACE_NAME)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, JAEGER_SUBSCRIPTION_NAME, JAEGER_SUBSCRIPTION_NAMESPACE_NAME,
-		JAEGER_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, JAEGER_SUBSCRIPTION_NAME, subscriptionNamespace,
+		JAEGER_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
 	// Delete Subscription
-	if err := r.Delete(context.TODO(), subscription); err != nil {
+	if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", subscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // Delete ElasticSearchOperator
-func (r *WorkshopReconciler) deleteElasticSearchOperator(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteElasticSearchOperator(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	channel := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.Channel
-	clusterserviceversion := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub.ClusterServiceVersion
-	subcriptionName := fmt.Sprintf("elasticsearch-operator-%s", channel)
+	operatorHub := workshop.Spec.Infrastructure.ServiceMesh.ElasticSearchOperatorHub
+	subscriptionNamespace := kubernetes.SubscriptionNamespace(operatorHub, ELASTICSEARCH_SUBSCRIPTION_NAMESPACE_NAME)
+	subcriptionName := fmt.Sprintf("elasticsearch-operator-%s", operatorHub.Channel)
 
-	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, subcriptionName, ELASTICSEARCH_SUBSCRIPTION_NAMESPACE_NAME,
-		ELASTICSEARCH_SUBSCRIPTION_PACKAGE_NAME, channel, clusterserviceversion)
+	subscription := kubernetes.NewRedHatSubscription(workshop, r.Scheme, subcriptionName, subscriptionNamespace,
+		ELASTICSEARCH_SUBSCRIPTION_PACKAGE_NAME, operatorHub)
 	// Delete Subscription
-	if err := r.Delete(context.TODO(), subscription); err != nil {
+	if err := r.Delete(ctx, subscription); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Subscription", subscription.Name)
+	log.Info("Deleted", util.LogKind, "Subscription", util.LogName, subscription.Name)
 
 	redhatOperatorsNamespace := kubernetes.NewNamespace(workshop, r.Scheme, OPERATOR_REDHAT_NAMESPACE_NAME)
 	// Delete Namespace
-	if err := r.Delete(context.TODO(), redhatOperatorsNamespace); err != nil {
+	if err := r.Delete(ctx, redhatOperatorsNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Namespace", redhatOperatorsNamespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, redhatOperatorsNamespace.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // delete IstioSystem Namespace
-func (r *WorkshopReconciler) deleteIstioSystemNamespace(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteIstioSystemNamespace(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	istioNamespaceName := util.ScopedName(workshop, ISTIO_NAMESPACE_NAME)
 
-	istioSystemNamespace := kubernetes.NewNamespace(workshop, r.Scheme, ISTIO_NAMESPACE_NAME)
+	istioSystemNamespace := kubernetes.NewNamespace(workshop, r.Scheme, istioNamespaceName)
 	// Delete Namespace
-	if err := r.Delete(context.TODO(), istioSystemNamespace); err != nil {
+	if err := r.Delete(ctx, istioSystemNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Namespace", istioSystemNamespace.Name)
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, istioSystemNamespace.Name)
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // get CSV of servicemesh, jaeger, kiali
-func (r *WorkshopReconciler) getCSV(workshop *workshopv1.Workshop) (string, string, string, error) {
+func (r *WorkshopReconciler) getCSV(ctx context.Context, workshop *workshopv1.Workshop) (string, string, string, error) {
+	log := util.Logger(ctx)
+	serviceMesh := workshop.Spec.Infrastructure.ServiceMesh
 
 	servicemeshSubFound := &olmv1alpha1.Subscription{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: SERVICE_MESH_SUBSCRIPTION_NAME, Namespace: SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME}, servicemeshSubFound); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: SERVICE_MESH_SUBSCRIPTION_NAME, Namespace: kubernetes.SubscriptionNamespace(serviceMesh.ServiceMeshOperatorHub, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME)}, servicemeshSubFound); err != nil {
 		return servicemeshSubFound.Status.InstalledCSV, "", "", err
 	}
-	log.Info(servicemeshSubFound.Status.InstalledCSV)
+	log.V(util.LogDebug).Info("Installed", util.LogKind, "ClusterServiceVersion", util.LogName, servicemeshSubFound.Status.InstalledCSV)
 
 	jaegerSubFound := &olmv1alpha1.Subscription{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: JAEGER_SUBSCRIPTION_NAME, Namespace: JAEGER_SUBSCRIPTION_NAMESPACE_NAME}, jaegerSubFound); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: JAEGER_SUBSCRIPTION_NAME, Namespace: kubernetes.SubscriptionNamespace(serviceMesh.JaegerOperatorHub, JAEGER_SUBSCRIPTION_NAMESPACE_NAME)}, jaegerSubFound); err != nil {
 		return "", jaegerSubFound.Status.InstalledCSV, "", err
 	}
-	log.Info(jaegerSubFound.Status.InstalledCSV)
+	log.V(util.LogDebug).Info("Installed", util.LogKind, "ClusterServiceVersion", util.LogName, jaegerSubFound.Status.InstalledCSV)
 
 	kialiSubFound := &olmv1alpha1.Subscription{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: KIALI_SUBSCRIPTION_NAME, Namespace: KIALI_SUBSCRIPTION_NAMESPACE_NAME}, kialiSubFound); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: KIALI_SUBSCRIPTION_NAME, Namespace: kubernetes.SubscriptionNamespace(serviceMesh.KialiOperatorHub, KIALI_SUBSCRIPTION_NAMESPACE_NAME)}, kialiSubFound); err != nil {
 		return "", "", kialiSubFound.Status.InstalledCSV, err
 	}
-	log.Info(kialiSubFound.Status.InstalledCSV)
+	log.V(util.LogDebug).Info("Installed", util.LogKind, "ClusterServiceVersion", util.LogName, kialiSubFound.Status.InstalledCSV)
 
 	return servicemeshSubFound.Status.InstalledCSV, jaegerSubFound.Status.InstalledCSV, kialiSubFound.Status.InstalledCSV, nil
 }
 
 // delete CSV of servicemesh, jaeger, kiali
-func (r *WorkshopReconciler) deleteCSV(workshop *workshopv1.Workshop, servicemeshCSV string, kialiCSV string, JaegerCSV string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteCSV(ctx context.Context, workshop *workshopv1.Workshop, servicemeshCSV string, kialiCSV string, JaegerCSV string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	serviceMesh := workshop.Spec.Infrastructure.ServiceMesh
 
-	servicemeshOperatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, servicemeshCSV, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME)
-	if err := r.Delete(context.TODO(), servicemeshOperatorCSV); err != nil {
+	servicemeshOperatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, servicemeshCSV, kubernetes.SubscriptionNamespace(serviceMesh.ServiceMeshOperatorHub, SERVICE_MESH_SUBSCRIPTION_NAMESPACE_NAME))
+	if err := r.Delete(ctx, servicemeshOperatorCSV); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s ClusterServiceVersion", servicemeshOperatorCSV.Name)
+	log.Info("Deleted", util.LogKind, "ClusterServiceVersion", util.LogName, servicemeshOperatorCSV.Name)
 
-	kialiOperatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, kialiCSV, KIALI_SUBSCRIPTION_NAMESPACE_NAME)
-	if err := r.Delete(context.TODO(), kialiOperatorCSV); err != nil {
+	kialiOperatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, kialiCSV, kubernetes.SubscriptionNamespace(serviceMesh.KialiOperatorHub, KIALI_SUBSCRIPTION_NAMESPACE_NAME))
+	if err := r.Delete(ctx, kialiOperatorCSV); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s ClusterServiceVersion", kialiOperatorCSV.Name)
+	log.Info("Deleted", util.LogKind, "ClusterServiceVersion", util.LogName, kialiOperatorCSV.Name)
 
-	JaegerOperatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, JaegerCSV, JAEGER_SUBSCRIPTION_NAMESPACE_NAME)
-	if err := r.Delete(context.TODO(), JaegerOperatorCSV); err != nil {
+	JaegerOperatorCSV := kubernetes.NewRedHatClusterServiceVersion(workshop, r.Scheme, JaegerCSV, kubernetes.SubscriptionNamespace(serviceMesh.JaegerOperatorHub, JAEGER_SUBSCRIPTION_NAMESPACE_NAME))
+	if err := r.Delete(ctx, JaegerOperatorCSV); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s ClusterServiceVersion", JaegerOperatorCSV.Name)
+	log.Info("Deleted", util.LogKind, "ClusterServiceVersion", util.LogName, JaegerOperatorCSV.Name)
 
 	return reconcile.Result{}, nil
 }
 
 // Patch istio-system Project
-func (r *WorkshopReconciler) PatchIstioProject(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) PatchIstioProject(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	istioNamespaceName := util.ScopedName(workshop, ISTIO_NAMESPACE_NAME)
 
-	namespaceFound := kubernetes.NewNamespace(workshop, r.Scheme, ISTIO_NAMESPACE_NAME)
+	namespaceFound := kubernetes.NewNamespace(workshop, r.Scheme, istioNamespaceName)
 
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: ISTIO_NAMESPACE_NAME}, namespaceFound); err != nil {
+	if err := r.Get(ctx, types.NamespacedName{Name: istioNamespaceName}, namespaceFound); err != nil {
 		return reconcile.Result{}, err
 	}
 
 	if namespaceFound.Spec.Finalizers[0] == "kubernetes" {
 		servicemeshcontrolplanes := &maistrav2.ServiceMeshControlPlane{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: SERVICE_MESH_CONTROL_PLANE_NAME, Namespace: ISTIO_NAMESPACE_NAME}, servicemeshcontrolplanes); err != nil {
+		if err := r.Get(ctx, types.NamespacedName{Name: SERVICE_MESH_CONTROL_PLANE_NAME, Namespace: istioNamespaceName}, servicemeshcontrolplanes); err != nil {
 			return reconcile.Result{}, err
 		}
 
 		patch := client.MergeFrom(servicemeshcontrolplanes.DeepCopy())
 		servicemeshcontrolplanes.Finalizers = nil
-		if err := r.Patch(context.TODO(), servicemeshcontrolplanes, patch); err != nil {
+		if err := r.Patch(ctx, servicemeshcontrolplanes, patch); err != nil {
 			return reconcile.Result{}, err
 		}
-		log.Infof("patched %s ServiceMeshControlPlane", servicemeshcontrolplanes.Name)
+		log.Info("Patched", util.LogKind, "ServiceMeshControlPlane", util.LogName, servicemeshcontrolplanes.Name)
 	}
 
 	if namespaceFound.Spec.Finalizers[0] == "kubernetes" {
 		kialiFound := &kiali.Kiali{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: KIALI_NAME, Namespace: ISTIO_NAMESPACE_NAME}, kialiFound); err != nil {
+		if err := r.Get(ctx, types.NamespacedName{Name: KIALI_NAME, Namespace: istioNamespaceName}, kialiFound); err != nil {
 			return reconcile.Result{}, err
 		}
 
 		patch := client.MergeFrom(kialiFound.DeepCopy())
 		kialiFound.Finalizers = nil
-		if err := r.Patch(context.TODO(), kialiFound, patch); err != nil {
+		if err := r.Patch(ctx, kialiFound, patch); err != nil {
 			return reconcile.Result{}, err
 		}
-		log.Infof("patched %s kiali", kialiFound.Name)
+		log.Info("Patched", util.LogKind, "Kiali", util.LogName, kialiFound.Name)
 	}
 
 	if namespaceFound.Spec.Finalizers[0] == "kubernetes" {
 		serviceMeshMemberRoll := &maistrav1.ServiceMeshMemberRoll{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: SERVICE_MESH_MEMBER_ROLL_NAME, Namespace: ISTIO_NAMESPACE_NAME}, serviceMeshMemberRoll); err != nil {
+		if err := r.Get(ctx, types.NamespacedName{Name: SERVICE_MESH_MEMBER_ROLL_NAME, Namespace: istioNamespaceName}, serviceMeshMemberRoll); err != nil {
 			return reconcile.Result{}, err
 		}
 
 		patch := client.MergeFrom(serviceMeshMemberRoll.DeepCopy())
 		serviceMeshMemberRoll.Finalizers = nil
-		if err := r.Patch(context.TODO(), serviceMeshMemberRoll, patch); err != nil {
+		if err := r.Patch(ctx, serviceMeshMemberRoll, patch); err != nil {
 			return reconcile.Result{}, err
 		}
-		log.Infof("patched %s ServiceMeshMemberRoll", serviceMeshMemberRoll.Name)
+		log.Info("Patched", util.LogKind, "ServiceMeshMemberRoll", util.LogName, serviceMeshMemberRoll.Name)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
+
+// addKubernetesServiceMesh installs Istio, Jaeger and Kiali with their charts on Kubernetes, shared by the Workshops of the cluster,
+// and enables the injection of the sidecars in the user projects, the members of the mesh
+func (r *WorkshopReconciler) addKubernetesServiceMesh(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	for _, release := range serviceMeshHelmReleases(workshop) {
+		if result, err := r.installHelmRelease(ctx, workshop, release); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	kialiRoute := r.newRoute(workshop, KIALI_NAME, ISTIO_NAMESPACE_NAME, istioLabels, KIALI_NAME, KIALI_SERVICE_PORT, appsHostnameSuffix)
+	if err := r.applyShared(ctx, workshop, kialiRoute); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	if err := r.labelMeshMembers(ctx, workshop, users, true); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// deleteKubernetesServiceMesh disables the injection of the sidecars in the user projects,
+// and uninstalls the charts of the mesh once no other Workshop uses them
+func (r *WorkshopReconciler) deleteKubernetesServiceMesh(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee,
+	appsHostnameSuffix string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+
+	if err := r.labelMeshMembers(ctx, workshop, users, false); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	isUsed, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool {
+		return r.Components.IsEnabled(&other.Spec, "ServiceMesh")
+	})
+	if err != nil {
+		return reconcile.Result{}, err
+	}
+	if isUsed {
+		// Another Workshop takes over the releases
+		return r.handOverHelmReleases(ctx, workshop, "ServiceMesh", serviceMeshHelmReleases(workshop)...)
+	}
+
+	kialiRoute := r.newRoute(workshop, KIALI_NAME, ISTIO_NAMESPACE_NAME, istioLabels, KIALI_NAME, KIALI_SERVICE_PORT, appsHostnameSuffix)
+	if err := r.Delete(ctx, kialiRoute); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return reconcile.Result{}, err
+	}
+	log.Info("Deleted", util.LogKind, "Ingress", util.LogName, KIALI_NAME)
+
+	releases := serviceMeshHelmReleases(workshop)
+	for i := len(releases) - 1; i >= 0; i-- {
+		if result, err := r.uninstallHelmRelease(ctx, workshop, releases[i]); util.IsRequeued(result, err) {
+			return result, err
+		}
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// labelMeshMembers enables or disables the injection of the sidecars in the user projects
+func (r *WorkshopReconciler) labelMeshMembers(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee, enabled bool) error {
+	log := util.Logger(ctx)
+
+	for _, user := range users {
+		namespace := &corev1.Namespace{}
+		if err := r.Get(ctx, types.NamespacedName{Name: util.ProjectName(workshop, user.Username)}, namespace); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if _, labelled := namespace.Labels[ISTIO_INJECTION_LABEL]; labelled == enabled {
+			continue
+		}
+
+		patch := client.MergeFrom(namespace.DeepCopy())
+		if enabled {
+			if namespace.Labels == nil {
+				namespace.Labels = map[string]string{}
+			}
+			namespace.Labels[ISTIO_INJECTION_LABEL] = "enabled"
+		} else {
+			delete(namespace.Labels, ISTIO_INJECTION_LABEL)
+		}
+		if err := r.Patch(ctx, namespace, patch); err != nil {
+			return err
+		}
+		log.Info("Patched", util.LogKind, "Namespace", util.LogName, namespace.Name, ISTIO_INJECTION_LABEL, enabled)
+	}
+	return nil
+}
+
+// serviceMeshHelmReleases returns the releases standing in for OpenShift Service Mesh on Kubernetes, in the order of their installation
+func serviceMeshHelmReleases(workshop *workshopv1.Workshop) []helmRelease {
+	serviceMesh := workshop.Spec.Infrastructure.ServiceMesh
+	return []helmRelease{
+		newHelmRelease(ISTIO_BASE_HELM_RELEASE_NAME, ISTIO_NAMESPACE_NAME, serviceMesh.IstioBaseHelm,
+			ISTIO_HELM_REPOSITORY, ISTIO_BASE_HELM_CHART, nil),
+		newHelmRelease(ISTIOD_HELM_RELEASE_NAME, ISTIO_NAMESPACE_NAME, serviceMesh.IstiodHelm,
+			ISTIO_HELM_REPOSITORY, ISTIOD_HELM_CHART, nil),
+		// A single all-in-one Jaeger keeps the traces in memory, as the basic control plane does on OpenShift
+		newHelmRelease(JAEGER_HELM_RELEASE_NAME, ISTIO_NAMESPACE_NAME, serviceMesh.JaegerHelm,
+			JAEGER_HELM_REPOSITORY, JAEGER_HELM_CHART, map[string]interface{}{
+				"provisionDataStore": map[string]interface{}{"cassandra": false},
+				"allInOne":           map[string]interface{}{"enabled": true},
+				"storage":            map[string]interface{}{"type": "memory"},
+				"agent":              map[string]interface{}{"enabled": false},
+				"collector":          map[string]interface{}{"enabled": false},
+				"query":              map[string]interface{}{"enabled": false},
+			}),
+		// The attendees have no token of the cluster to log in to Kiali with
+		newHelmRelease(KIALI_HELM_RELEASE_NAME, ISTIO_NAMESPACE_NAME, serviceMesh.KialiHelm,
+			KIALI_HELM_REPOSITORY, KIALI_HELM_CHART, map[string]interface{}{
+				"auth": map[string]interface{}{"strategy": "anonymous"},
+				"external_services": map[string]interface{}{
+					"tracing": map[string]interface{}{
+						"in_cluster_url": "http://jaeger-query." + ISTIO_NAMESPACE_NAME + ":16685/jaeger",
+						"use_grpc":       true,
+					},
+				},
+			}),
+	}
+}
@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+)
+
+// isUsedByOtherWorkshop returns true if another Workshop, not being deleted, relies on a
+// cluster resource shared between Workshops, such as a CRD or an operator Subscription
+func (r *WorkshopReconciler) isUsedByOtherWorkshop(ctx context.Context, workshop *workshopv1.Workshop, uses func(*workshopv1.Workshop) bool) (bool, error) {
+	other, err := r.otherWorkshopUsing(ctx, workshop, uses)
+	return other != nil, err
+}
+
+// otherWorkshopUsing returns another Workshop, not being deleted, relying on a cluster resource shared between Workshops,
+// or nil if there is none
+func (r *WorkshopReconciler) otherWorkshopUsing(ctx context.Context, workshop *workshopv1.Workshop, uses func(*workshopv1.Workshop) bool) (*workshopv1.Workshop, error) {
+	workshops := &workshopv1.WorkshopList{}
+	if err := r.List(ctx, workshops); err != nil {
+		return nil, err
+	}
+	for i := range workshops.Items {
+		other := &workshops.Items[i]
+		if other.UID == workshop.UID || other.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if uses(other) {
+			return other, nil
+		}
+	}
+	return nil, nil
+}
+
+// workshopExists returns true if the Workshop of the UID still exists and is not being deleted
+func (r *WorkshopReconciler) workshopExists(ctx context.Context, uid types.UID) (bool, error) {
+	workshops := &workshopv1.WorkshopList{}
+	if err := r.List(ctx, workshops); err != nil {
+		return false, err
+	}
+	for i := range workshops.Items {
+		if workshops.Items[i].UID == uid && workshops.Items[i].GetDeletionTimestamp() == nil {
+			return true, nil
+		}
+	}
+	return false, nil
+}
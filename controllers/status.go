@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// Condition reasons
+const (
+	ReasonInstalled      = "Installed"
+	ReasonInstalling     = "Installing"
+	ReasonReconcileError = "ReconcileError"
+	ReasonDeleting       = "Deleting"
+	// ReasonAppsDomainUnavailable reports that neither the spec nor the cluster Ingress config give the apps domain
+	ReasonAppsDomainUnavailable = "AppsDomainUnavailable"
+)
+
+// componentPhase describes the status field of a component
+type componentPhase struct {
+	name      string
+	enabled   bool
+	installed bool
+	phase     *string
+}
+
+// statusRecorder tracks the phase of each component of a Workshop
+type statusRecorder struct {
+	workshop   *workshopv1.Workshop
+	components []componentPhase
+	// addOns holds the phases of the components without a status field of their own
+	addOns map[string]*string
+	// current is the component that stopped the reconciliation, if any
+	current string
+	// deleting is true while the components are uninstalled before the deletion of the Workshop
+	deleting bool
+	// message replaces the message of the conditions, if set
+	message string
+	// reason replaces the reason of the conditions on an error, if set
+	reason string
+	// events records the Events of the components on the Workshop
+	events record.EventRecorder
+}
+
+// installedComponents returns the names of the components which the status reports as installed
+func installedComponents(workshop *workshopv1.Workshop, components []Component) map[string]bool {
+	installed := map[string]bool{}
+	for _, c := range components {
+		phase := workshop.Status.AddOns[c.Name()]
+		if field := c.Status(&workshop.Status); field != nil {
+			phase = *field
+		}
+		installed[c.Name()] = util.IsInstalled(phase)
+	}
+	return installed
+}
+
+func newStatusRecorder(workshop *workshopv1.Workshop, components []Component, enabled map[string]bool,
+	events record.EventRecorder) *statusRecorder {
+	status := &workshop.Status
+	installed := installedComponents(workshop, components)
+
+	recorder := &statusRecorder{
+		workshop: workshop,
+		addOns:   map[string]*string{},
+		events:   events,
+	}
+	for _, c := range components {
+		phase := c.Status(status)
+		if phase == nil {
+			addOnPhase := status.AddOns[c.Name()]
+			phase = &addOnPhase
+			recorder.addOns[c.Name()] = phase
+		}
+		recorder.components = append(recorder.components, componentPhase{
+			name:      c.Name(),
+			enabled:   enabled[c.Name()],
+			installed: installed[c.Name()],
+			phase:     phase,
+		})
+	}
+
+	// Components not reached yet in this reconciliation keep their last phase,
+	// unless they have been enabled, or disabled without having been installed
+	for _, c := range recorder.components {
+		if !c.enabled && !c.installed {
+			*c.phase = util.OperatorStatus.NotScheduled
+		} else if c.enabled && (*c.phase == "" || *c.phase == util.OperatorStatus.NotScheduled) {
+			*c.phase = util.OperatorStatus.Scheduled
+		}
+	}
+
+	return recorder
+}
+
+// installed returns true if the named component was installed when the reconciliation started
+func (s *statusRecorder) installed(name string) bool {
+	for _, c := range s.components {
+		if c.name == name {
+			return c.installed
+		}
+	}
+	return false
+}
+
+// step records the phase of the named component and returns true if the reconciliation must stop
+func (s *statusRecorder) step(name string, result ctrl.Result, err error) bool {
+	for _, c := range s.components {
+		if c.name != name {
+			continue
+		}
+		before := *c.phase
+		switch {
+		case err != nil:
+			*c.phase = util.OperatorStatus.Failed
+		case util.IsRequeued(result, err) && c.enabled:
+			*c.phase = util.OperatorStatus.InProgress
+		case util.IsRequeued(result, err):
+			*c.phase = util.OperatorStatus.Uninstalling
+		case c.enabled:
+			*c.phase = util.OperatorStatus.Installed
+		default:
+			*c.phase = util.OperatorStatus.NotScheduled
+			forgetProvisioned(s.workshop, c.name)
+			util.RemoveCondition(&s.workshop.Status.Conditions, c.name+workshopv1.ConditionOperatorSuffix)
+		}
+		componentInstallTimer.record(s.workshop, c.name, before, *c.phase, time.Now())
+		s.recordEvent(c.name, before, *c.phase, err)
+	}
+
+	if util.IsRequeued(result, err) {
+		s.current = name
+		return true
+	}
+	return false
+}
+
+// recordEvent records an Event when a component fails, or when it gets installed or uninstalled
+func (s *statusRecorder) recordEvent(name string, before string, after string, err error) {
+	switch {
+	case err != nil:
+		recordEvent(s.events, s.workshop, corev1.EventTypeWarning, EventComponentFailed, name, "", "%s", err)
+	case after == before:
+	case after == util.OperatorStatus.Installed:
+		recordEvent(s.events, s.workshop, corev1.EventTypeNormal, EventComponentInstalled, name, "", "Installed")
+	case after == util.OperatorStatus.NotScheduled && before != "" && before != util.OperatorStatus.Scheduled:
+		recordEvent(s.events, s.workshop, corev1.EventTypeNormal, EventComponentUninstalled, name, "", "Uninstalled")
+	}
+}
+
+// setConditions sets Ready, Progressing and Degraded from the outcome of the reconciliation
+func (s *statusRecorder) setConditions(result ctrl.Result, err error) {
+	generation := s.workshop.Generation
+	conditions := &s.workshop.Status.Conditions
+
+	ready := workshopv1.Condition{Type: workshopv1.ConditionReady, ObservedGeneration: generation}
+	progressing := workshopv1.Condition{Type: workshopv1.ConditionProgressing, ObservedGeneration: generation}
+	degraded := workshopv1.Condition{Type: workshopv1.ConditionDegraded, ObservedGeneration: generation}
+
+	errorReason := ReasonReconcileError
+	if s.reason != "" {
+		errorReason = s.reason
+	}
+
+	switch {
+	case s.deleting:
+		message := s.message
+		switch {
+		case err != nil:
+			if message == "" {
+				message = fmt.Sprintf("Teardown failed: %s", err)
+			}
+			ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, errorReason, message
+			progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionFalse, errorReason, message
+			degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, errorReason, message
+		case util.IsRequeued(result, err):
+			if message == "" {
+				message = fmt.Sprintf("Waiting for the uninstallation of %s", s.current)
+			}
+			ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, ReasonDeleting, message
+			progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, ReasonDeleting, message
+			degraded.Status, degraded.Reason = metav1.ConditionFalse, ReasonDeleting
+		default:
+			message = "All components are uninstalled"
+			ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, ReasonDeleting, message
+			progressing.Status, progressing.Reason = metav1.ConditionFalse, ReasonDeleting
+			degraded.Status, degraded.Reason = metav1.ConditionFalse, ReasonDeleting
+		}
+	case err != nil:
+		message := s.message
+		if message == "" {
+			message = fmt.Sprintf("%s failed: %s", s.current, err)
+		}
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, errorReason, message
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionFalse, errorReason, message
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, errorReason, message
+	case util.IsRequeued(result, err):
+		message := fmt.Sprintf("Waiting for %s", s.current)
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionFalse, ReasonInstalling, message
+		progressing.Status, progressing.Reason, progressing.Message = metav1.ConditionTrue, ReasonInstalling, message
+		degraded.Status, degraded.Reason = metav1.ConditionFalse, ReasonInstalling
+	default:
+		message := "All enabled components are installed"
+		ready.Status, ready.Reason, ready.Message = metav1.ConditionTrue, ReasonInstalled, message
+		progressing.Status, progressing.Reason = metav1.ConditionFalse, ReasonInstalled
+		degraded.Status, degraded.Reason = metav1.ConditionFalse, ReasonInstalled
+	}
+
+	util.SetCondition(conditions, ready)
+	util.SetCondition(conditions, progressing)
+	util.SetCondition(conditions, degraded)
+
+	if len(s.addOns) > 0 {
+		s.workshop.Status.AddOns = map[string]string{}
+		for name, phase := range s.addOns {
+			s.workshop.Status.AddOns[name] = *phase
+		}
+	} else {
+		s.workshop.Status.AddOns = nil
+	}
+}
+
+// updateStatus writes the Workshop status through the status subresource
+func (r *WorkshopReconciler) updateStatus(ctx context.Context, status *statusRecorder, result ctrl.Result, err error) (ctrl.Result, error) {
+	log := util.Logger(ctx)
+	status.setConditions(result, err)
+	status.workshop.Status.ObservedGeneration = status.workshop.Generation
+
+	if status.deleting && !util.Contains(status.workshop.GetFinalizers(), workshopFinalizer) {
+		forgetWorkshopMetrics(status.workshop, status.components)
+	} else {
+		recordComponentPhases(status.workshop, status.components)
+	}
+
+	// The Workshop is gone once the teardown removed the finalizer
+	if updateErr := r.Status().Update(ctx, status.workshop); updateErr != nil && !errors.IsNotFound(updateErr) {
+		log.Error(updateErr, "Failed to update the status")
+		if err == nil {
+			return ctrl.Result{}, updateErr
+		}
+	}
+	return result, err
+}
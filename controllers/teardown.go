@@ -0,0 +1,99 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	securityv1 "github.com/openshift/api/security/v1"
+	userv1 "github.com/openshift/api/user/v1"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	admissionregistration "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// remainingResources returns the cluster resources of the Workshop which still exist, as Kind/name.
+// The finalizer of the Workshop is kept until none remains.
+func (r *WorkshopReconciler) remainingResources(ctx context.Context, workshop *workshopv1.Workshop, env *Environment) ([]string, error) {
+	remaining := []string{}
+	listOps := &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{util.WorkshopUIDLabel: string(workshop.UID)}),
+	}
+
+	owned := map[string]runtime.Object{
+		"User":                         &userv1.UserList{},
+		"Group":                        &userv1.GroupList{},
+		"Namespace":                    &corev1.NamespaceList{},
+		"ClusterRole":                  &rbac.ClusterRoleList{},
+		"ClusterRoleBinding":           &rbac.ClusterRoleBindingList{},
+		"MutatingWebhookConfiguration": &admissionregistration.MutatingWebhookConfigurationList{},
+	}
+
+	// The operators and their CRDs are shared by the Workshops and kept while another Workshop uses them
+	isShared, err := r.isUsedByOtherWorkshop(ctx, workshop, func(other *workshopv1.Workshop) bool { return true })
+	if err != nil {
+		return nil, err
+	}
+	if !isShared {
+		owned["Subscription"] = &olmv1alpha1.SubscriptionList{}
+		owned["ClusterServiceVersion"] = &olmv1alpha1.ClusterServiceVersionList{}
+		owned["CustomResourceDefinition"] = &apiextensionsv1beta1.CustomResourceDefinitionList{}
+	}
+
+	for kind, list := range owned {
+		if err := r.List(ctx, list, listOps); err != nil {
+			if meta.IsNoMatchError(err) {
+				continue
+			}
+			return nil, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if object, err := meta.Accessor(item); err == nil {
+				remaining = append(remaining, kind+"/"+object.GetName())
+			}
+		}
+	}
+
+	// OpenShift creates the identities on the first login, without the labels of the Workshop
+	for _, user := range env.Users {
+		identity := &userv1.Identity{}
+		err := r.Get(ctx, types.NamespacedName{Name: IDENTITY_NAME + ":" + user.Username}, identity)
+		if err == nil {
+			remaining = append(remaining, "Identity/"+identity.Name)
+		} else if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+	}
+
+	// The vault SCC is shared, only the service accounts of the Workshop must be gone
+	vaultSCC := &securityv1.SecurityContextConstraints{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "vault"}, vaultSCC); err == nil {
+		prefix := "system:serviceaccount:" + util.ScopedName(workshop, VAULT_NAMESPACE_NAME) + ":"
+		for _, user := range vaultSCC.Users {
+			if strings.HasPrefix(user, prefix) {
+				remaining = append(remaining, "SecurityContextConstraints/"+vaultSCC.Name)
+				break
+			}
+		}
+	} else if !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+		return nil, err
+	}
+
+	sort.Strings(remaining)
+	return remaining, nil
+}
@@ -2,10 +2,8 @@ package controllers
 
 import (
 	"context"
-	"encoding/base64"
 	"fmt"
 	userv1 "github.com/openshift/api/user/v1"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	openshiftuser "github.com/stakater/workshop-operator/common/user"
 	"github.com/stakater/workshop-operator/common/util"
@@ -14,10 +12,9 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
-	"os/exec"
+	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
-	"strings"
 )
 
 const (
@@ -26,47 +23,65 @@ const (
 	USER_ROLE_BINDING_NAMESPACE_NAME = "workshop-infra"
 	IDENTITY_NAME                    = "htpass-workshop-users"
 	USER_IDENTITY_MAPPING_NAME       = "htpass-workshop-users"
-	USER_LABEL_SELECTOR              = "createdBy=WorkshopOperator"
+	USER_CREATED_BY_LABEL            = "createdBy"
 )
 
 var userLabels = map[string]string{
-	"createdBy": "WorkshopOperator",
+	USER_CREATED_BY_LABEL: "WorkshopOperator",
 }
 
-func (r *WorkshopReconciler) reconcileUser(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	createUsers := make(map[string]bool)
-	totalUsers := workshop.Spec.UserDetails.NumberOfUsers
-	userPrefix := workshop.Spec.UserDetails.UserNamePrefix
-	for userSuffix := 1; userSuffix <= totalUsers; userSuffix++ {
-		userName := fmt.Sprint(userPrefix, userSuffix)
-		createUsers[userName] = true
+func (r *WorkshopReconciler) reconcileUser(ctx context.Context, workshop *workshopv1.Workshop, appsHostnameSuffix string) (reconcile.Result, error) {
+	users := util.Roster(workshop)
+	if !r.isOpenShift() {
+		if isDexUser(workshop) {
+			return r.reconcileDexUsers(ctx, workshop, users, appsHostnameSuffix)
+		}
+		return r.reconcileServiceAccountUsers(ctx, workshop, users)
+	}
+
+	createUsers := make(map[string]bool, len(users))
+	for _, user := range users {
+		createUsers[user.Username] = true
 	}
 
-	listUsers, err := r.createdUserList(workshop)
+	listUsers, err := r.createdUserList(ctx, workshop)
 	if err != nil {
-		log.Errorf("Failed to get Created User List {%s} ", err)
+		return reconcile.Result{}, err
 	}
 
+	ownedUsers := map[string]bool{}
 	for _, user := range listUsers.Items {
 		username := user.Name
+		ownedUsers[username] = true
 		_, ok := createUsers[username]
 		if ok {
 			createUsers[username] = false
 		} else {
-			if result, err := r.deleteOpenshiftUser(workshop, r.Scheme, username); util.IsRequeued(result, err) {
+			if result, err := r.deleteOpenshiftUser(ctx, workshop, r.Scheme, username); util.IsRequeued(result, err) {
 				return result, err
 			}
 		}
 	}
 
-	for username, value := range createUsers {
-		if value {
-			if result, err := r.addUser(workshop, r.Scheme, username); util.IsRequeued(result, err) {
-				return result, err
-			}
+	for provisioned, user := range users {
+		result, err := r.addUser(ctx, workshop, r.Scheme, user, createUsers[user.Username])
+		setProvisioned(workshop, "Users", user.Username, err)
+		attendeeStatus(workshop, user.Username).UserReady = err == nil
+		if util.IsRequeued(result, err) {
+			recordUsers(workshop, len(users), provisioned)
+			return result, err
 		}
 	}
-	if result, err := r.createUserHtpasswd(workshop, createUsers); util.IsRequeued(result, err) {
+	recordUsers(workshop, len(users), len(users))
+
+	if result, err := r.reconcileUserGroups(ctx, workshop, users); util.IsRequeued(result, err) {
+		return result, err
+	}
+	if result, err := r.createUserHtpasswd(ctx, workshop, createUsers, ownedUsers); util.IsRequeued(result, err) {
+		return result, err
+	}
+
+	if result, err := r.deleteUserCredentials(ctx, workshop, createUsers); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -75,126 +90,163 @@ func (r *WorkshopReconciler) reconcileUser(workshop *workshopv1.Workshop) (recon
 }
 
 // Add user in openshift cluster
-func (r *WorkshopReconciler) addUser(workshop *workshopv1.Workshop, scheme *runtime.Scheme, username string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addUser(ctx context.Context, workshop *workshopv1.Workshop, scheme *runtime.Scheme, attendee util.Attendee, isNew bool) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	username := attendee.Username
 
 	//Create User
-	user := openshiftuser.NewUser(workshop, r.Scheme, username, userLabels)
-	if err := r.Create(context.TODO(), user); err != nil && !errors.IsAlreadyExists(err) {
+	user := openshiftuser.NewUser(workshop, r.Scheme, username, attendee.DisplayName, userLabels)
+	if err := r.Create(ctx, user); err != nil && !errors.IsAlreadyExists(err) {
+		r.warningEvent(workshop, EventUserFailed, "Users", username, "Failed to create the User: %s", err)
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s User", user.Name)
+		log.Info("Created", util.LogKind, "User", util.LogName, user.Name)
+		r.normalEvent(workshop, EventUserCreated, "Users", username, "Created the User")
+	} else if errors.IsAlreadyExists(err) {
+		userFound := &userv1.User{}
+		if err := r.Get(ctx, types.NamespacedName{Name: username}, userFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		// Adopt the users created by the operator before the Workshops were labelling their users,
+		// any other user is an account the Workshop must not take over, and delete later
+		_, labelled := userFound.Labels[util.WorkshopUIDLabel]
+		createdByOperator := userFound.Labels[USER_CREATED_BY_LABEL] == userLabels[USER_CREATED_BY_LABEL]
+		if (labelled && !util.IsOwnedBy(workshop, userFound.Labels)) || (!labelled && !createdByOperator) {
+			r.warningEvent(workshop, EventUserFailed, "Users", username, "The User already exists and is not owned by the Workshop")
+			return reconcile.Result{}, fmt.Errorf("user %s already exists and is not owned by Workshop %s/%s, use another username",
+				username, workshop.Namespace, workshop.Name)
+		}
+		// Keep the display name in sync with the roster
+		if !labelled || userFound.FullName != attendee.DisplayName {
+			patch := client.MergeFrom(userFound.DeepCopy())
+			userFound.Labels = util.WithOwnerLabels(workshop, userFound.Labels)
+			userFound.FullName = attendee.DisplayName
+			if err := r.Patch(ctx, userFound, patch); err != nil {
+				return reconcile.Result{}, err
+			}
+			log.Info("Updated", util.LogKind, "User", util.LogName, userFound.Name)
+		}
+	}
+
+	if !isNew {
+		//Success
+		return reconcile.Result{}, nil
 	}
 
 	// Create User Role Binding
 	userRoleBinding := openshiftuser.NewUserRoleBinding(workshop, r.Scheme, username, USER_ROLE_BINDING_NAMESPACE_NAME,
 		USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
-	if err := r.Create(context.TODO(), userRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, userRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Role Binding", userRoleBinding.Name)
 	}
 
 	// Get User
 	userFound := &userv1.User{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: username}, userFound); err != nil {
-		log.Errorf("Failed to find %s User", userFound.Name)
+	if err := r.Get(ctx, types.NamespacedName{Name: username}, userFound); err != nil {
+		log.Error(err, "Failed to get", util.LogKind, "User", util.LogUser, username)
 
 	}
 
 	// Create Identity
 	identity := openshiftuser.NewIdentity(workshop, r.Scheme, username, IDENTITY_NAME, userFound)
-	if err := r.Create(context.TODO(), identity); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, identity); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s Identity ", identity.Name)
+		log.Info("Created", util.LogKind, "Identity", util.LogName, identity.Name)
 	}
 
 	// Create User Identity Mapping
 	userIdentity := openshiftuser.NewUserIdentityMapping(workshop, r.Scheme, USER_IDENTITY_MAPPING_NAME, username)
-	if err := r.Create(context.TODO(), userIdentity); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.Create(ctx, userIdentity); err != nil && !errors.IsAlreadyExists(err) {
 		return reconcile.Result{}, err
 	} else if err == nil {
-		log.Infof("Created %s User Identity Mapping ", userIdentity.Name)
+		log.Info("Created", util.LogKind, "UserIdentityMapping", util.LogName, userIdentity.Name)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) createUserHtpasswd(workshop *workshopv1.Workshop, users map[string]bool) (reconcile.Result, error) {
-
-	var htpasswds []byte
-	var countUsers int
-	createUsers := []string{}
+// createUserHtpasswd updates the Htpasswd secret with the users of the Workshop. The secret is shared
+// with the other Workshops, so only the entries of the users owned by this Workshop are changed.
+func (r *WorkshopReconciler) createUserHtpasswd(ctx context.Context, workshop *workshopv1.Workshop, users map[string]bool,
+	ownedUsers map[string]bool) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	password := workshop.Spec.UserDetails.DefaultPassword
-	totalUsers := workshop.Spec.UserDetails.NumberOfUsers
-	userPrefix := workshop.Spec.UserDetails.UserNamePrefix
-
-	for usersname := range users {
-		createUsers = append(createUsers, usersname)
+	secretFound := &corev1.Secret{}
+	err := r.Get(ctx, types.NamespacedName{Name: HTPASSWD_SECRET_NAME, Namespace: HTPASSWD_SECRET_NAMESPACE_NAME}, secretFound)
+	if err != nil && !errors.IsNotFound(err) {
+		return reconcile.Result{}, err
 	}
 
-	for _, username := range createUsers {
-		command := "echo \"password\" | htpasswd -b -B -i -n " + username
-		updateCommad := fmt.Sprint(strings.Replace(command, "password", password, -1))
-		out, err := exec.Command("/bin/bash", "-c", updateCommad).Output()
+	htpasswd := openshiftuser.ParseHtpasswd(secretFound.Data["htpasswd"])
+	changed := false
+	for username := range ownedUsers {
+		if _, ok := users[username]; !ok && htpasswd.Remove(username) {
+			changed = true
+		}
+	}
+	for username := range users {
+		password, err := r.userPassword(ctx, workshop, username)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		updated, err := htpasswd.SetPassword(r.passwords, workshop.UID, username, password)
 		if err != nil {
-			log.Errorf("Failed to Execute Bash Command %s", err)
+			log.Error(err, "Failed to hash the password", util.LogUser, username)
+			return reconcile.Result{}, err
 		}
-		userpwd := fmt.Sprint(strings.TrimSpace(string(out)), "\n")
-		htpasswds = append(htpasswds, []byte(userpwd)...)
+		changed = changed || updated
 	}
 
-	htpasswdSecret := openshiftuser.NewHTPasswdSecret(workshop, r.Scheme, HTPASSWD_SECRET_NAME, HTPASSWD_SECRET_NAMESPACE_NAME, htpasswds)
-	if err := r.Create(context.TODO(), htpasswdSecret); err != nil && !errors.IsAlreadyExists(err) {
-		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created  %s Secret", htpasswdSecret.Name)
-	} else if errors.IsAlreadyExists(err) {
-		// Get secret
-		secretFound := &corev1.Secret{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: HTPASSWD_SECRET_NAME, Namespace: HTPASSWD_SECRET_NAMESPACE_NAME}, secretFound); err == nil {
-			for _, secretData := range secretFound.Data {
-				encodedSecret := base64.StdEncoding.EncodeToString(secretData)
-				decodeSecret, err := base64.StdEncoding.DecodeString(encodedSecret)
-				if err != nil {
-					log.Errorf("Failed to Decode Secret %s", err)
-				}
-				countUsers = strings.Count(string(decodeSecret), userPrefix)
-			}
-			if totalUsers > countUsers || totalUsers < countUsers {
-				if err := r.Delete(context.TODO(), htpasswdSecret); err != nil {
-					return reconcile.Result{}, err
-				}
-				if err := r.Create(context.TODO(), htpasswdSecret); err != nil && !errors.IsAlreadyExists(err) {
-					return reconcile.Result{}, err
-				} else if err == nil {
-					log.Infof("Created  %s secret", htpasswdSecret.Name)
-				}
-			}
+	if errors.IsNotFound(err) {
+		htpasswdSecret := openshiftuser.NewHTPasswdSecret(workshop, r.Scheme, HTPASSWD_SECRET_NAME, HTPASSWD_SECRET_NAMESPACE_NAME, htpasswd.Bytes())
+		if err := r.Create(ctx, htpasswdSecret); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Info("Created", util.LogKind, "Secret", util.LogName, htpasswdSecret.Name)
+		}
+	} else if changed {
+		patch := client.MergeFrom(secretFound.DeepCopy())
+		if secretFound.Data == nil {
+			secretFound.Data = map[string][]byte{}
 		}
+		secretFound.Data["htpasswd"] = htpasswd.Bytes()
+		if err := r.Patch(ctx, secretFound, patch); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Updated", util.LogKind, "Secret", util.LogName, secretFound.Name)
 	}
 
 	return reconcile.Result{}, nil
 }
 
 // deleteUsers delete users in openshift cluster
-func (r *WorkshopReconciler) deleteUsers(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteUsers(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	if !r.isOpenShift() {
+		// The users may have switched from one identity to the other
+		if result, err := r.deleteDexUsers(ctx, workshop); util.IsRequeued(result, err) {
+			return result, err
+		}
+		return r.deleteServiceAccountUsers(ctx, workshop)
+	}
 
-	listUsers, err := r.createdUserList(workshop)
+	usernames, err := r.ownedUserNames(ctx, workshop)
 	if err != nil {
-		log.Errorf("Failed to get Created User List {%s} ", err)
+		return reconcile.Result{}, err
 	}
 
-	for _, user := range listUsers.Items {
-		username := user.Name
-		if result, err := r.deleteOpenshiftUser(workshop, r.Scheme, username); util.IsRequeued(result, err) {
+	for username := range usernames {
+		if result, err := r.deleteOpenshiftUser(ctx, workshop, r.Scheme, username); util.IsRequeued(result, err) {
 			return result, err
 		}
 	}
 
-	if result, err := r.deleteUserHtpasswd(workshop); err != nil {
+	if result, err := r.deleteUserHtpasswd(ctx, workshop, usernames); err != nil {
+		return result, err
+	}
+
+	if result, err := r.reconcileUserGroups(ctx, workshop, nil); util.IsRequeued(result, err) {
 		return result, err
 	}
 
@@ -203,72 +255,168 @@ func (r *WorkshopReconciler) deleteUsers(workshop *workshopv1.Workshop) (reconci
 }
 
 // deleteUser delete OpenShift user
-func (r *WorkshopReconciler) deleteOpenshiftUser(workshop *workshopv1.Workshop, scheme *runtime.Scheme, username string) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteOpenshiftUser(ctx context.Context, workshop *workshopv1.Workshop, scheme *runtime.Scheme, username string) (reconcile.Result, error) {
+	log := util.Logger(ctx).WithValues(util.LogUser, username)
 
 	// Get user
 	userFound := &userv1.User{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: username}, userFound); err != nil {
-		log.Errorf("Failed to find %s User", userFound.Name)
+	if err := r.Get(ctx, types.NamespacedName{Name: username}, userFound); err != nil {
+		log.Error(err, "Failed to get", util.LogKind, "User")
 	}
 	//
 	// Delete User Identity Mapping
 	userIdentity := openshiftuser.NewUserIdentityMapping(workshop, r.Scheme, USER_IDENTITY_MAPPING_NAME, username)
-	if err := r.Delete(context.TODO(), userIdentity); err != nil {
+	if err := r.Delete(ctx, userIdentity); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s User Identity Mapping ", userIdentity.Name)
+	log.Info("Deleted", util.LogKind, "UserIdentityMapping", util.LogName, userIdentity.Name)
 
 	// Delete Identity
 	identity := openshiftuser.NewIdentity(workshop, r.Scheme, username, IDENTITY_NAME, userFound)
-	if err := r.Delete(context.TODO(), identity); err != nil {
+	if err := r.Delete(ctx, identity); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Identity  ", identity.Name)
+	log.Info("Deleted", util.LogKind, "Identity", util.LogName, identity.Name)
 
 	// Delete User Role Binding
 	userRoleBinding := openshiftuser.NewUserRoleBinding(workshop, r.Scheme, username, USER_ROLE_BINDING_NAMESPACE_NAME,
 		USER_ROLE_BINDING_NAME, KIND_CLUSTER_ROLE)
-	if err := r.Delete(context.TODO(), userRoleBinding); err != nil {
+	if err := r.Delete(ctx, userRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Role Binding", userRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "RoleBinding", util.LogName, userRoleBinding.Name)
 
 	// Delete User
-	user := openshiftuser.NewUser(workshop, r.Scheme, username, userLabels)
-	if err := r.Delete(context.TODO(), user); err != nil {
+	user := openshiftuser.NewUser(workshop, r.Scheme, username, "", userLabels)
+	if err := r.Delete(ctx, user); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s user", user.Name)
+	log.Info("Deleted", util.LogKind, "User", util.LogName, user.Name)
+	r.passwords.Forget(workshop.UID, username)
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-//deleteUserHtpasswd delete Htpasswd secret for users
-func (r *WorkshopReconciler) deleteUserHtpasswd(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+// deleteUserHtpasswd delete the entries of the users of the Workshop from the Htpasswd secret,
+// and the secret itself once no other Workshop has users in it
+func (r *WorkshopReconciler) deleteUserHtpasswd(ctx context.Context, workshop *workshopv1.Workshop, usernames map[string]bool) (reconcile.Result, error) {
+	log := util.Logger(ctx)
 
-	htpasswdSecret := openshiftuser.NewHTPasswdSecret(workshop, r.Scheme, HTPASSWD_SECRET_NAME, HTPASSWD_SECRET_NAMESPACE_NAME, []byte(""))
-	if err := r.Delete(context.TODO(), htpasswdSecret); err != nil {
+	secretFound := &corev1.Secret{}
+	if err := r.Get(ctx, types.NamespacedName{Name: HTPASSWD_SECRET_NAME, Namespace: HTPASSWD_SECRET_NAMESPACE_NAME}, secretFound); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s HTPasswd Secret", htpasswdSecret.Name)
+
+	htpasswd := openshiftuser.ParseHtpasswd(secretFound.Data["htpasswd"])
+	for username := range usernames {
+		htpasswd.Remove(username)
+	}
+
+	if len(htpasswd) == 0 {
+		if err := r.Delete(ctx, secretFound); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Secret", util.LogName, secretFound.Name)
+	} else {
+		patch := client.MergeFrom(secretFound.DeepCopy())
+		secretFound.Data["htpasswd"] = htpasswd.Bytes()
+		if err := r.Patch(ctx, secretFound, patch); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Updated", util.LogKind, "Secret", util.LogName, secretFound.Name)
+	}
 	//Success
 	return reconcile.Result{}, nil
 }
 
-//createdUserList return list of users
-func (r *WorkshopReconciler) createdUserList(workshop *workshopv1.Workshop) (*userv1.UserList, error) {
-	labelSelector, err := labels.Parse(USER_LABEL_SELECTOR)
-	if err != nil {
-		log.Errorf("Failed to get list of users %s", err)
-	}
+// createdUserList return list of users created by the Workshop
+func (r *WorkshopReconciler) createdUserList(ctx context.Context, workshop *workshopv1.Workshop) (*userv1.UserList, error) {
+	log := util.Logger(ctx)
 	listUsers := &userv1.UserList{}
 	listOps := &client.ListOptions{
-		LabelSelector: labelSelector,
+		LabelSelector: labels.SelectorFromSet(labels.Set{util.WorkshopUIDLabel: string(workshop.UID)}),
 	}
 	// list User
-	if err := r.List(context.TODO(), listUsers, listOps); err != nil {
-		log.Errorf("Failed to get  list of  users, filtered by labelSelector {%s} ,{%s}", labelSelector, err)
+	if err := r.List(ctx, listUsers, listOps); err != nil {
+		log.Error(err, "Failed to list", util.LogKind, "User", "labelSelector", listOps.LabelSelector)
+		return listUsers, err
 	}
-	return listUsers, err
+	return listUsers, nil
+}
+
+// ownedUserNames return the names of the users created by the Workshop
+func (r *WorkshopReconciler) ownedUserNames(ctx context.Context, workshop *workshopv1.Workshop) (map[string]bool, error) {
+	listUsers, err := r.createdUserList(ctx, workshop)
+	if err != nil {
+		return nil, err
+	}
+	usernames := make(map[string]bool, len(listUsers.Items))
+	for _, user := range listUsers.Items {
+		usernames[user.Name] = true
+	}
+	return usernames, nil
+}
+
+// reconcileUserGroups create a <workshop>-<group> OpenShift group per group of the roster,
+// and delete the groups of the Workshop which are no longer used
+func (r *WorkshopReconciler) reconcileUserGroups(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	members := map[string][]string{}
+	for _, user := range users {
+		if user.Group != "" {
+			groupName := util.ScopedName(workshop, user.Group)
+			members[groupName] = append(members[groupName], user.Username)
+		}
+	}
+
+	for groupName, usernames := range members {
+		group := openshiftuser.NewGroup(workshop, r.Scheme, groupName, userLabels, usernames)
+		if err := r.Create(ctx, group); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Info("Created", util.LogKind, "Group", util.LogName, group.Name)
+		} else if errors.IsAlreadyExists(err) {
+			groupFound := &userv1.Group{}
+			if err := r.Get(ctx, types.NamespacedName{Name: groupName}, groupFound); err != nil {
+				return reconcile.Result{}, err
+			}
+			if !util.IsOwnedBy(workshop, groupFound.Labels) {
+				return reconcile.Result{}, fmt.Errorf("group %s already exists and is not owned by Workshop %s/%s",
+					groupName, workshop.Namespace, workshop.Name)
+			}
+			if !reflect.DeepEqual([]string(groupFound.Users), usernames) {
+				patch := client.MergeFrom(groupFound.DeepCopy())
+				groupFound.Users = usernames
+				if err := r.Patch(ctx, groupFound, patch); err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Info("Updated", util.LogKind, "Group", util.LogName, groupFound.Name)
+			}
+		}
+	}
+
+	listGroups := &userv1.GroupList{}
+	listOps := &client.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{util.WorkshopUIDLabel: string(workshop.UID)}),
+	}
+	if err := r.List(ctx, listGroups, listOps); err != nil {
+		return reconcile.Result{}, err
+	}
+	for i := range listGroups.Items {
+		group := &listGroups.Items[i]
+		if _, ok := members[group.Name]; ok {
+			continue
+		}
+		if err := r.Delete(ctx, group); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "Group", util.LogName, group.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
 }
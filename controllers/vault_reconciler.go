@@ -3,7 +3,6 @@ package controllers
 import (
 	"context"
 	securityv1 "github.com/openshift/api/security/v1"
-	"github.com/prometheus/common/log"
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
 	"github.com/stakater/workshop-operator/common/kubernetes"
 	"github.com/stakater/workshop-operator/common/util"
@@ -63,18 +62,13 @@ const (
 )
 
 // Reconciling Vault
-func (r *WorkshopReconciler) reconcileVault(workshop *workshopv1.Workshop, users int) (reconcile.Result, error) {
-
-	enabled := workshop.Spec.Infrastructure.Vault.Enabled
-
-	if enabled {
-		if result, err := r.addVaultServer(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+func (r *WorkshopReconciler) reconcileVault(ctx context.Context, workshop *workshopv1.Workshop, users []util.Attendee) (reconcile.Result, error) {
+	if result, err := r.addVaultServer(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
+	}
 
-		if result, err := r.addVaultAgentInjector(workshop); util.IsRequeued(result, err) {
-			return result, err
-		}
+	if result, err := r.addVaultAgentInjector(ctx, workshop); util.IsRequeued(result, err) {
+		return result, err
 	}
 
 	//Success
@@ -82,95 +76,59 @@ func (r *WorkshopReconciler) reconcileVault(workshop *workshopv1.Workshop, users
 }
 
 // Add Vault Server
-func (r *WorkshopReconciler) addVaultServer(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) addVaultServer(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	vaultNamespaceName := util.ScopedName(workshop, VAULT_NAMESPACE_NAME)
 
-	log.Infoln("Creating VaultServer ")
+	log.Info("Creating VaultServer")
 
 	// Create Namespace
-	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, VAULT_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), vaultNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, vaultNamespaceName)
+	if err := r.apply(ctx, vaultNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault Project", vaultNamespace.Name)
 	}
 
-	configMap := kubernetes.NewConfigMap(workshop, r.Scheme, VAULT_CONFIGMAP_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels, ExtraConfigFromValues)
-	if err := r.Create(context.TODO(), configMap); err != nil && !errors.IsAlreadyExists(err) {
+	configMap := kubernetes.NewConfigMap(workshop, r.Scheme, VAULT_CONFIGMAP_NAME, vaultNamespaceName, VaultServerLabels, ExtraConfigFromValues)
+	if err := r.apply(ctx, configMap); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault ConfigMap", configMap.Name)
 	}
 
 	// Create Service Account
-	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULT_SERVICEACCOUNT_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels)
-	if err := r.Create(context.TODO(), serviceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULT_SERVICEACCOUNT_NAME, vaultNamespaceName, VaultServerLabels)
+	if err := r.apply(ctx, serviceAccount); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault Service Account", serviceAccount.Name)
 	}
 
 	// Create ServiceAccountUser
 	serviceAccountUser := "system:serviceaccount:" + vaultNamespace.Name + ":" + serviceAccount.Name
 
-	vaultSCC := &securityv1.SecurityContextConstraints{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault"}, vaultSCC); err != nil {
-		privilegedSCCFound := &securityv1.SecurityContextConstraints{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: "privileged"}, privilegedSCCFound); err == nil {
-			newVaultSCC := privilegedSCCFound.DeepCopy()
-			newVaultSCC.ObjectMeta = metav1.ObjectMeta{}
-			newVaultSCC.Name = "vault"
-
-			if !util.StringInSlice(serviceAccountUser, newVaultSCC.Users) {
-				newVaultSCC.Users = append(newVaultSCC.Users, serviceAccountUser)
-			}
-			if err := r.Create(context.TODO(), newVaultSCC); err != nil && !errors.IsAlreadyExists(err) {
-				return reconcile.Result{}, err
-			} else {
-				log.Infof("Created %s SCC", newVaultSCC.Name)
-			}
-		} else {
-			if !util.StringInSlice(serviceAccountUser, vaultSCC.Users) {
-				vaultSCC.Users = append(vaultSCC.Users, serviceAccountUser)
-				if err := r.Update(context.TODO(), vaultSCC); err != nil {
-					return reconcile.Result{}, err
-				} else {
-					log.Infof("Updated %s SCC", vaultSCC.Name)
-				}
-			}
-		}
+	if result, err := r.addVaultSCCUser(ctx, serviceAccountUser); err != nil {
+		return result, err
 	}
 
 	// Create ClusterRole Binding
-	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, VAULT_ROLEBINDING_NAME, VAULT_NAMESPACE_NAME,
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, VAULT_ROLEBINDING_NAME), vaultNamespaceName,
 		VaultServerLabels, serviceAccount.Name, VAULT_ROLEBINDING_ROLE_NAME, KIND_CLUSTER_ROLE)
-	if err := r.Create(context.TODO(), clusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, clusterRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault Cluster Role Binding", clusterRoleBinding.Name)
 	}
 
 	// Create Service
-	internalService := kubernetes.NewService(workshop, r.Scheme, VAULT_INTERNAL_SERVICE_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
-	if err := r.Create(context.TODO(), internalService); err != nil && !errors.IsAlreadyExists(err) {
+	internalService := kubernetes.NewService(workshop, r.Scheme, VAULT_INTERNAL_SERVICE_NAME, vaultNamespaceName, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
+	if err := r.apply(ctx, internalService); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault Service", internalService.Name)
 	}
 
 	// Create Service
-	service := kubernetes.NewService(workshop, r.Scheme, VAULT_SERVICE_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
-	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
+	service := kubernetes.NewService(workshop, r.Scheme, VAULT_SERVICE_NAME, vaultNamespaceName, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
+	if err := r.apply(ctx, service); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault Service", service.Name)
 	}
 
 	// Create StatefulSet
-	stateful := vault.NewStatefulSet(workshop, r.Scheme, VAULT_STATEFULSET_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels)
-	if err := r.Create(context.TODO(), stateful); err != nil && !errors.IsAlreadyExists(err) {
+	stateful := vault.NewStatefulSet(workshop, r.Scheme, VAULT_STATEFULSET_NAME, vaultNamespaceName, VaultServerLabels)
+	if err := r.apply(ctx, stateful); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s Vault Stateful", stateful.Name)
 	}
 
 	//Success
@@ -178,259 +136,307 @@ func (r *WorkshopReconciler) addVaultServer(workshop *workshopv1.Workshop) (reco
 }
 
 // Add VaultAgentInjector
-func (r *WorkshopReconciler) addVaultAgentInjector(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	log.Infoln("Creating VaultAgent")
+func (r *WorkshopReconciler) addVaultAgentInjector(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	vaultNamespaceName := util.ScopedName(workshop, VAULT_NAMESPACE_NAME)
+	log.Info("Creating VaultAgent")
 
 	// Create Namespace
-	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, VAULT_NAMESPACE_NAME)
-	if err := r.Create(context.TODO(), vaultNamespace); err != nil && !errors.IsAlreadyExists(err) {
+	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, vaultNamespaceName)
+	if err := r.apply(ctx, vaultNamespace); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Project", vaultNamespace.Name)
 	}
 
 	// Create Service Account
-	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULTAGENT_SERVICEACCOUNT_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels)
-	if err := r.Create(context.TODO(), serviceAccount); err != nil && !errors.IsAlreadyExists(err) {
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULTAGENT_SERVICEACCOUNT_NAME, vaultNamespaceName, VaultAgentLabels)
+	if err := r.apply(ctx, serviceAccount); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Service Account", serviceAccount.Name)
 	}
 
 	// Create ServiceAccountUser
 	serviceAccountUser := "system:serviceaccount:" + vaultNamespace.Name + ":" + serviceAccount.Name
 
-	vaultAgentSCC := &securityv1.SecurityContextConstraints{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault"}, vaultAgentSCC); err != nil {
-		privilegedSCCFound := &securityv1.SecurityContextConstraints{}
-		if err := r.Get(context.TODO(), types.NamespacedName{Name: "privileged"}, privilegedSCCFound); err == nil {
-			newVaultAgentSCC := privilegedSCCFound.DeepCopy()
-			newVaultAgentSCC.ObjectMeta = metav1.ObjectMeta{}
-			newVaultAgentSCC.Name = "vault"
-
-			if !util.StringInSlice(serviceAccountUser, newVaultAgentSCC.Users) {
-				newVaultAgentSCC.Users = append(newVaultAgentSCC.Users, serviceAccountUser)
-			}
-			if err := r.Create(context.TODO(), newVaultAgentSCC); err != nil && !errors.IsAlreadyExists(err) {
-				return reconcile.Result{}, err
-			} else {
-				log.Infof("Created %s SCC", newVaultAgentSCC.Name)
-			}
-		} else {
-			if !util.StringInSlice(serviceAccountUser, vaultAgentSCC.Users) {
-				vaultAgentSCC.Users = append(vaultAgentSCC.Users, serviceAccountUser)
-				if err := r.Update(context.TODO(), vaultAgentSCC); err != nil {
-					return reconcile.Result{}, err
-				} else {
-					log.Infof("Updated %s SCC", vaultAgentSCC.Name)
-				}
-			}
-		}
+	if result, err := r.addVaultSCCUser(ctx, serviceAccountUser); err != nil {
+		return result, err
 	}
 
 	// Create Cluster Role
 	clusterRole := kubernetes.NewClusterRole(workshop, r.Scheme,
-		VAULTAGENT_CLUSTERROLE_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels, kubernetes.VaultAgentInjectorRules())
-	if err := r.Create(context.TODO(), clusterRole); err != nil && !errors.IsAlreadyExists(err) {
+		util.ScopedName(workshop, VAULTAGENT_CLUSTERROLE_NAME), vaultNamespaceName, VaultAgentLabels, kubernetes.VaultAgentInjectorRules())
+	if err := r.apply(ctx, clusterRole); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Cluster Role", clusterRole.Name)
 	}
 
 	// Create Cluster Role Binding
-	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, VAULTAGENT_ROLEBINDING_NAME, VAULT_NAMESPACE_NAME,
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, VAULTAGENT_ROLEBINDING_NAME), vaultNamespaceName,
 		VaultAgentLabels, VAULTAGENT_SERVICEACCOUNT_NAME, clusterRole.Name, KIND_CLUSTER_ROLE)
-	if err := r.Create(context.TODO(), clusterRoleBinding); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, clusterRoleBinding); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Cluster Role Binding", clusterRoleBinding.Name)
 	}
 
 	// Create Service
-	service := kubernetes.NewServiceWithTarget(workshop, r.Scheme, VAULTAGENT_SERVICE_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels,
+	service := kubernetes.NewServiceWithTarget(workshop, r.Scheme, VAULTAGENT_SERVICE_NAME, vaultNamespaceName, VaultAgentLabels,
 		[]string{"http"}, []int32{443}, []int32{8080})
-	if err := r.Create(context.TODO(), service); err != nil && !errors.IsAlreadyExists(err) {
+	if err := r.apply(ctx, service); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Service", service.Name)
 	}
 
 	// Create Deployment
-	ocpDeployment := vault.NewAgentInjectorDeployment(workshop, r.Scheme, VAULTAGENT_DEPLOYMENT_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels)
-	if err := r.Create(context.TODO(), ocpDeployment); err != nil && !errors.IsAlreadyExists(err) {
+	ocpDeployment := vault.NewAgentInjectorDeployment(workshop, r.Scheme, VAULTAGENT_DEPLOYMENT_NAME, vaultNamespaceName, VaultAgentLabels)
+	if err := r.apply(ctx, ocpDeployment); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Deployment", ocpDeployment.Name)
 	}
 
 	// Create AgentInjectorWebHook
-	webhooks := vault.NewAgentInjectorWebHook(vaultNamespace.Name)
+	webhooks := vault.NewAgentInjectorWebHook(vaultNamespace.Name, util.OwnerLabels(workshop))
 	mutatingWebhookConfiguration := kubernetes.NewMutatingWebhookConfiguration(workshop, r.Scheme,
-		VAULTAGENT_WEBHOOK_NAME, VaultAgentLabels, webhooks)
-	if err := r.Create(context.TODO(), mutatingWebhookConfiguration); err != nil && !errors.IsAlreadyExists(err) {
+		util.ScopedName(workshop, VAULTAGENT_WEBHOOK_NAME), VaultAgentLabels, webhooks)
+	if err := r.apply(ctx, mutatingWebhookConfiguration); err != nil {
 		return reconcile.Result{}, err
-	} else if err == nil {
-		log.Infof("Created %s VaultAgent Mutating Webhook Configuration", mutatingWebhookConfiguration.Name)
 	}
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
-func (r *WorkshopReconciler) deleteVault(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	log.Infoln("Deleting Vault ")
+func (r *WorkshopReconciler) deleteVault(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	log.Info("Deleting Vault")
 
-	if result, err := r.deleteVaultServer(workshop); util.IsRequeued(result, err) {
+	if result, err := r.deleteVaultServer(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
-	if result, err := r.deleteVaultAgentInjector(workshop); util.IsRequeued(result, err) {
+	if result, err := r.deleteVaultAgentInjector(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
 
-	if result, err := r.deleteVaultNamespace(workshop); util.IsRequeued(result, err) {
+	if result, err := r.deleteVaultNamespace(ctx, workshop); util.IsRequeued(result, err) {
 		return result, err
 	}
 	return reconcile.Result{}, nil
 }
 
 // delete Vault
-func (r *WorkshopReconciler) deleteVaultServer(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	log.Infoln("Deleting VaultServer")
+func (r *WorkshopReconciler) deleteVaultServer(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	vaultNamespaceName := util.ScopedName(workshop, VAULT_NAMESPACE_NAME)
+	log.Info("Deleting VaultServer")
 
 	// last method last line
-	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULT_SERVICEACCOUNT_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels)
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULT_SERVICEACCOUNT_NAME, vaultNamespaceName, VaultServerLabels)
 
-	stateful := vault.NewStatefulSet(workshop, r.Scheme, VAULT_STATEFULSET_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels)
+	stateful := vault.NewStatefulSet(workshop, r.Scheme, VAULT_STATEFULSET_NAME, vaultNamespaceName, VaultServerLabels)
 	// Delete stateful
-	if err := r.Delete(context.TODO(), stateful); err != nil {
+	if err := r.Delete(ctx, stateful); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultServer stateful", stateful.Name)
+	log.Info("Deleted", util.LogKind, "StatefulSet", util.LogName, stateful.Name)
 
-	service := kubernetes.NewService(workshop, r.Scheme, VAULT_SERVICE_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
+	service := kubernetes.NewService(workshop, r.Scheme, VAULT_SERVICE_NAME, vaultNamespaceName, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
 	// Delete Service
-	if err := r.Delete(context.TODO(), service); err != nil {
+	if err := r.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultServer Service", service.Name)
+	log.Info("Deleted", util.LogKind, "Service", util.LogName, service.Name)
 
-	internalService := kubernetes.NewService(workshop, r.Scheme, VAULT_INTERNAL_SERVICE_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
+	internalService := kubernetes.NewService(workshop, r.Scheme, VAULT_INTERNAL_SERVICE_NAME, vaultNamespaceName, VaultServerLabels, []string{"http", "internal"}, []int32{8200, 8201})
 	// Delete internal Service
-	if err := r.Delete(context.TODO(), internalService); err != nil {
+	if err := r.Delete(ctx, internalService); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultServer internal Service", internalService.Name)
+	log.Info("Deleted", util.LogKind, "Service", util.LogName, internalService.Name)
 
-	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, VAULT_ROLEBINDING_NAME, VAULT_NAMESPACE_NAME,
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, VAULT_ROLEBINDING_NAME), vaultNamespaceName,
 		VaultServerLabels, serviceAccount.Name, VAULT_ROLEBINDING_ROLE_NAME, KIND_CLUSTER_ROLE)
 	// Delete ClusterRole Binding
-	if err := r.Delete(context.TODO(), clusterRoleBinding); err != nil {
+	if err := r.Delete(ctx, clusterRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  VaultServer ClusterRole Binding", clusterRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRoleBinding", util.LogName, clusterRoleBinding.Name)
 
-	vaultSCC := &securityv1.SecurityContextConstraints{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault"}, vaultSCC); err == nil {
-		if err := r.Delete(context.TODO(), vaultSCC); err != nil {
-			return reconcile.Result{}, err
-		}
-		log.Infof("Deleted %s vaultSCC ", vaultSCC.Name)
+	if result, err := r.removeVaultSCCUser(ctx, "system:serviceaccount:"+vaultNamespaceName+":"+serviceAccount.Name); err != nil {
+		return result, err
 	}
 
 	// Delete Service Account
-	if err := r.Delete(context.TODO(), serviceAccount); err != nil {
+	if err := r.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultServer Service Account", serviceAccount.Name)
+	log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, serviceAccount.Name)
 
-	configMap := kubernetes.NewConfigMap(workshop, r.Scheme, VAULT_CONFIGMAP_NAME, VAULT_NAMESPACE_NAME, VaultServerLabels, ExtraConfigFromValues)
+	configMap := kubernetes.NewConfigMap(workshop, r.Scheme, VAULT_CONFIGMAP_NAME, vaultNamespaceName, VaultServerLabels, ExtraConfigFromValues)
 	// Delete configMap
-	if err := r.Delete(context.TODO(), configMap); err != nil {
+	if err := r.Delete(ctx, configMap); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultServer configMap", configMap.Name)
-	log.Infoln("Deleted VaultServer Successfully")
+	log.Info("Deleted", util.LogKind, "ConfigMap", util.LogName, configMap.Name)
+	log.Info("Deleted VaultServer Successfully")
 
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // delete VaultAgentInjector
-func (r *WorkshopReconciler) deleteVaultAgentInjector(workshop *workshopv1.Workshop) (reconcile.Result, error) {
-	log.Infoln("Deleting VaultAgent ")
+func (r *WorkshopReconciler) deleteVaultAgentInjector(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	vaultNamespaceName := util.ScopedName(workshop, VAULT_NAMESPACE_NAME)
+	log.Info("Deleting VaultAgent")
 
-	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, VAULT_NAMESPACE_NAME)
+	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, vaultNamespaceName)
 	clusterRole := kubernetes.NewClusterRole(workshop, r.Scheme,
-		VAULTAGENT_CLUSTERROLE_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels, kubernetes.VaultAgentInjectorRules())
+		util.ScopedName(workshop, VAULTAGENT_CLUSTERROLE_NAME), vaultNamespaceName, VaultAgentLabels, kubernetes.VaultAgentInjectorRules())
 
-	webhooks := vault.NewAgentInjectorWebHook(vaultNamespace.Name)
+	webhooks := vault.NewAgentInjectorWebHook(vaultNamespace.Name, util.OwnerLabels(workshop))
 	mutatingWebhookConfiguration := kubernetes.NewMutatingWebhookConfiguration(workshop, r.Scheme,
-		VAULTAGENT_WEBHOOK_NAME, VaultAgentLabels, webhooks)
+		util.ScopedName(workshop, VAULTAGENT_WEBHOOK_NAME), VaultAgentLabels, webhooks)
 	// Delete AgentInjectorWebHook
-	if err := r.Delete(context.TODO(), mutatingWebhookConfiguration); err != nil {
+	if err := r.Delete(ctx, mutatingWebhookConfiguration); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultAgent Mutating Webhook Configuration ", mutatingWebhookConfiguration.Name)
+	log.Info("Deleted", util.LogKind, "MutatingWebhookConfiguration", util.LogName, mutatingWebhookConfiguration.Name)
 
-	ocpDeployment := vault.NewAgentInjectorDeployment(workshop, r.Scheme, VAULTAGENT_DEPLOYMENT_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels)
+	ocpDeployment := vault.NewAgentInjectorDeployment(workshop, r.Scheme, VAULTAGENT_DEPLOYMENT_NAME, vaultNamespaceName, VaultAgentLabels)
 	// Delete Deployment
-	if err := r.Delete(context.TODO(), ocpDeployment); err != nil {
+	if err := r.Delete(ctx, ocpDeployment); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultAgent Deployment ", ocpDeployment.Name)
+	log.Info("Deleted", util.LogKind, "Deployment", util.LogName, ocpDeployment.Name)
 
-	service := kubernetes.NewServiceWithTarget(workshop, r.Scheme, VAULTAGENT_SERVICE_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels,
+	service := kubernetes.NewServiceWithTarget(workshop, r.Scheme, VAULTAGENT_SERVICE_NAME, vaultNamespaceName, VaultAgentLabels,
 		[]string{"http"}, []int32{443}, []int32{8080})
 	// Delete Service
-	if err := r.Delete(context.TODO(), service); err != nil {
+	if err := r.Delete(ctx, service); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultAgent Service ", service.Name)
+	log.Info("Deleted", util.LogKind, "Service", util.LogName, service.Name)
 
-	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, VAULTAGENT_ROLEBINDING_NAME, VAULT_NAMESPACE_NAME,
+	clusterRoleBinding := kubernetes.NewClusterRoleBindingSA(workshop, r.Scheme, util.ScopedName(workshop, VAULTAGENT_ROLEBINDING_NAME), vaultNamespaceName,
 		VaultAgentLabels, VAULTAGENT_SERVICEACCOUNT_NAME, clusterRole.Name, KIND_CLUSTER_ROLE)
 	// Delete Cluster Role Binding
-	if err := r.Delete(context.TODO(), clusterRoleBinding); err != nil {
+	if err := r.Delete(ctx, clusterRoleBinding); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultAgent Cluster Role Binding", clusterRoleBinding.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRoleBinding", util.LogName, clusterRoleBinding.Name)
 
 	// Delete Cluster Role
-	if err := r.Delete(context.TODO(), clusterRole); err != nil {
+	if err := r.Delete(ctx, clusterRole); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s VaultAgent Cluster Role", clusterRole.Name)
+	log.Info("Deleted", util.LogKind, "ClusterRole", util.LogName, clusterRole.Name)
 
-	vaultAgentSCC := &securityv1.SecurityContextConstraints{}
-	if err := r.Get(context.TODO(), types.NamespacedName{Name: "vault"}, vaultAgentSCC); err == nil {
-		if err := r.Delete(context.TODO(), vaultAgentSCC); err != nil {
-			return reconcile.Result{}, err
-		}
-		log.Infof("Deleted %s vaultAgentSCC ", vaultAgentSCC.Name)
+	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULTAGENT_SERVICEACCOUNT_NAME, vaultNamespaceName, VaultAgentLabels)
+	if result, err := r.removeVaultSCCUser(ctx, "system:serviceaccount:"+vaultNamespaceName+":"+serviceAccount.Name); err != nil {
+		return result, err
 	}
 
-	serviceAccount := kubernetes.NewServiceAccount(workshop, r.Scheme, VAULTAGENT_SERVICEACCOUNT_NAME, VAULT_NAMESPACE_NAME, VaultAgentLabels)
 	// Delete  Service Account
-	if err := r.Delete(context.TODO(), serviceAccount); err != nil {
+	if err := r.Delete(ctx, serviceAccount); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s  VaultAgent Service Account", serviceAccount.Name)
-	log.Infoln("Deleted VaultAgent Successfully")
+	log.Info("Deleted", util.LogKind, "ServiceAccount", util.LogName, serviceAccount.Name)
+	log.Info("Deleted VaultAgent Successfully")
 	//Success
 	return reconcile.Result{}, nil
 }
 
 // delete Vault Namespace
-func (r *WorkshopReconciler) deleteVaultNamespace(workshop *workshopv1.Workshop) (reconcile.Result, error) {
+func (r *WorkshopReconciler) deleteVaultNamespace(ctx context.Context, workshop *workshopv1.Workshop) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	vaultNamespaceName := util.ScopedName(workshop, VAULT_NAMESPACE_NAME)
 
-	log.Infoln("Deleting Namespace")
-	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, VAULT_NAMESPACE_NAME)
+	log.Info("Deleting Namespace")
+	vaultNamespace := kubernetes.NewNamespace(workshop, r.Scheme, vaultNamespaceName)
 	// Delete Namespace
-	if err := r.Delete(context.TODO(), vaultNamespace); err != nil {
+	if err := r.Delete(ctx, vaultNamespace); err != nil && !errors.IsNotFound(err) {
 		return reconcile.Result{}, err
 	}
-	log.Infof("Deleted %s Namespace", vaultNamespace.Name)
-	log.Infoln("Deleted Namespace Successfully")
+	log.Info("Deleted", util.LogKind, "Namespace", util.LogName, vaultNamespace.Name)
+	log.Info("Deleted Namespace Successfully")
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// addVaultSCCUser lets the service account of a Workshop run privileged through the vault SCC,
+// a copy of the privileged SCC shared by the Workshops. Kubernetes has no SCC.
+func (r *WorkshopReconciler) addVaultSCCUser(ctx context.Context, serviceAccountUser string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	if !r.isOpenShift() {
+		return reconcile.Result{}, nil
+	}
+
+	vaultSCC := &securityv1.SecurityContextConstraints{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "vault"}, vaultSCC); err != nil {
+		if !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		privilegedSCCFound := &securityv1.SecurityContextConstraints{}
+		if err := r.Get(ctx, types.NamespacedName{Name: "privileged"}, privilegedSCCFound); err != nil {
+			return reconcile.Result{}, err
+		}
+		newVaultSCC := privilegedSCCFound.DeepCopy()
+		newVaultSCC.ObjectMeta = metav1.ObjectMeta{}
+		newVaultSCC.Name = "vault"
+		newVaultSCC.Users = []string{serviceAccountUser}
+		if err := r.Create(ctx, newVaultSCC); err != nil && !errors.IsAlreadyExists(err) {
+			return reconcile.Result{}, err
+		} else if err == nil {
+			log.Info("Created", util.LogKind, "SecurityContextConstraints", util.LogName, newVaultSCC.Name)
+			return reconcile.Result{}, nil
+		}
+		// Created by another Workshop in the meantime
+		if err := r.Get(ctx, types.NamespacedName{Name: "vault"}, vaultSCC); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+
+	if !util.StringInSlice(serviceAccountUser, vaultSCC.Users) {
+		vaultSCC.Users = append(vaultSCC.Users, serviceAccountUser)
+		if err := r.Update(ctx, vaultSCC); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Updated", util.LogKind, "SecurityContextConstraints", util.LogName, vaultSCC.Name)
+	}
+
+	//Success
+	return reconcile.Result{}, nil
+}
+
+// removeVaultSCCUser removes the service account of a Workshop from the vault SCC,
+// shared by the Workshops, and deletes the SCC once no service account uses it
+func (r *WorkshopReconciler) removeVaultSCCUser(ctx context.Context, serviceAccountUser string) (reconcile.Result, error) {
+	log := util.Logger(ctx)
+	if !r.isOpenShift() {
+		return reconcile.Result{}, nil
+	}
+
+	vaultSCC := &securityv1.SecurityContextConstraints{}
+	if err := r.Get(ctx, types.NamespacedName{Name: "vault"}, vaultSCC); err != nil {
+		if errors.IsNotFound(err) {
+			return reconcile.Result{}, nil
+		}
+		return reconcile.Result{}, err
+	}
+
+	users := []string{}
+	for _, user := range vaultSCC.Users {
+		if user != serviceAccountUser {
+			users = append(users, user)
+		}
+	}
+
+	if len(users) == 0 {
+		if err := r.Delete(ctx, vaultSCC); err != nil && !errors.IsNotFound(err) {
+			return reconcile.Result{}, err
+		}
+		log.Info("Deleted", util.LogKind, "SecurityContextConstraints", util.LogName, vaultSCC.Name)
+	} else if len(users) != len(vaultSCC.Users) {
+		vaultSCC.Users = users
+		if err := r.Update(ctx, vaultSCC); err != nil {
+			return reconcile.Result{}, err
+		}
+		log.Info("Removed the Service Account user", util.LogKind, "SecurityContextConstraints", util.LogName, vaultSCC.Name, util.LogUser, serviceAccountUser)
+	}
+
 	//Success
 	return reconcile.Result{}, nil
 }
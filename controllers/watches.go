@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"fmt"
+	"reflect"
+
+	argocdoperatorv1 "github.com/argoproj-labs/argocd-operator/pkg/apis/argoproj/v1alpha1"
+	che "github.com/eclipse/che-operator/pkg/apis/org/v1"
+	"github.com/go-logr/logr"
+	maistrav1 "github.com/maistra/istio-operator/pkg/apis/maistra/v1"
+	routev1 "github.com/openshift/api/route/v1"
+	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbac "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/gitea"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	"github.com/stakater/workshop-operator/common/nexus"
+	"github.com/stakater/workshop-operator/common/util"
+)
+
+// ownedKinds returns the kinds of the resources created by the Workshops, whose changes are reconciled
+func ownedKinds() []runtime.Object {
+	return []runtime.Object{
+		&appsv1.Deployment{},
+		&appsv1.StatefulSet{},
+		&batchv1.Job{},
+		&corev1.Service{},
+		&corev1.Secret{},
+		&corev1.ConfigMap{},
+		&corev1.Namespace{},
+		&corev1.ServiceAccount{},
+		&corev1.ResourceQuota{},
+		&corev1.LimitRange{},
+		&rbac.Role{},
+		&rbac.RoleBinding{},
+		&rbac.ClusterRole{},
+		&rbac.ClusterRoleBinding{},
+		&routev1.Route{},
+		kubernetes.NewIngressObject(),
+		&olmv1alpha1.Subscription{},
+		&che.CheCluster{},
+		&argocdoperatorv1.ArgoCD{},
+		&maistrav1.ServiceMeshMemberRoll{},
+		&gitea.Gitea{},
+		&nexus.Nexus{},
+	}
+}
+
+// servedKinds returns the kinds the cluster serves: a watch on a missing API, like the Routes
+// on Kubernetes or a CRD not installed yet, would stop the controller
+func servedKinds(log logr.Logger, mapper meta.RESTMapper, scheme *runtime.Scheme, kinds []runtime.Object) []runtime.Object {
+	served := []runtime.Object{}
+	for _, kind := range kinds {
+		gvk, err := apiutil.GVKForObject(kind, scheme)
+		if err != nil {
+			log.Error(err, "Failed to get the kind", "type", fmt.Sprintf("%T", kind))
+			continue
+		}
+		if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+			log.Info("Not watching", util.LogKind, gvk.Kind, "reason", err.Error())
+			continue
+		}
+		served = append(served, kind)
+	}
+	return served
+}
+
+// mapOwnerLabels enqueues the Workshop named in the ownership labels of the object.
+// Owner references can't point from another namespace or from a cluster-scoped object.
+func mapOwnerLabels(object handler.MapObject) []reconcile.Request {
+	labels := object.Meta.GetLabels()
+	name, namespace := labels[util.WorkshopNameLabel], labels[util.WorkshopNamespaceLabel]
+	if name == "" || namespace == "" {
+		return nil
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: name, Namespace: namespace}},
+	}
+}
+
+// awaitedDeployments returns the Deployments created by operators, not by the Workshop,
+// whose readiness gates a reconcile step
+func (r *WorkshopReconciler) awaitedDeployments(workshop *workshopv1.Workshop) []types.NamespacedName {
+	deployments := []types.NamespacedName{}
+	_, enabled, err := r.Components.Plan(&workshop.Spec)
+	if err != nil {
+		return deployments
+	}
+
+	if enabled["Nexus"] {
+		deployments = append(deployments, types.NamespacedName{Name: NEXUSDEPLOYMENTNAME, Namespace: util.ScopedName(workshop, NEXUSNAMESPACENAME)})
+	}
+	if enabled["Gitea"] {
+		deployments = append(deployments, types.NamespacedName{Name: GITEADEPLOYMENTNAME, Namespace: util.ScopedName(workshop, GITEANAMESPACENAME)})
+	}
+	if enabled["GitOps"] {
+		deployments = append(deployments,
+			types.NamespacedName{Name: GITOPS_DEPLOYMENT_NAME,
+				Namespace: kubernetes.SubscriptionNamespace(workshop.Spec.Infrastructure.GitOps.OperatorHub, GITOPS_OPERATOR_NAMESPACE_NAME)},
+			types.NamespacedName{Name: ARGOCD_DEPLOYMENT_NAME, Namespace: util.ScopedName(workshop, ARGOCD_NAMESPACE_NAME)})
+	}
+	if enabled["CodeReadyWorkspace"] {
+		deployments = append(deployments,
+			types.NamespacedName{Name: CODEREADY_OPERATOR_DEPLOYMENT_NAME, Namespace: util.ScopedName(workshop, CODEREADY_NAMESPACE_NAME)},
+			types.NamespacedName{Name: CODEREADY_DEPLOYMENT_NAME, Namespace: util.ScopedName(workshop, CODEREADY_NAMESPACE_NAME)})
+	}
+	if enabled["ServiceMesh"] {
+		deployments = append(deployments, types.NamespacedName{Name: ISTIO_OPERATOR_NAME, Namespace: ISTIO_OPERATOR_NAMESPACE_NAME})
+	}
+	return deployments
+}
+
+// mapAwaitedDeployment enqueues the Workshops waiting for the readiness of the Deployment
+func (r *WorkshopReconciler) mapAwaitedDeployment(object handler.MapObject) []reconcile.Request {
+	workshops := &workshopv1.WorkshopList{}
+	if err := r.List(r.context(), workshops); err != nil {
+		r.Log.Error(err, "Failed to list the Workshops")
+		return nil
+	}
+
+	deployment := types.NamespacedName{Name: object.Meta.GetName(), Namespace: object.Meta.GetNamespace()}
+	requests := []reconcile.Request{}
+	for _, workshop := range workshops.Items {
+		for _, awaited := range r.awaitedDeployments(&workshop) {
+			if awaited == deployment {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: workshop.Name, Namespace: workshop.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// mapManifestConfigMap maps a ConfigMap to the Workshops of its namespace whose extra manifests it holds,
+// so that editing the templates applies them again
+func (r *WorkshopReconciler) mapManifestConfigMap(object handler.MapObject) []reconcile.Request {
+	workshops := &workshopv1.WorkshopList{}
+	if err := r.List(r.context(), workshops, client.InNamespace(object.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Failed to list the Workshops")
+		return nil
+	}
+
+	requests := []reconcile.Request{}
+	for _, workshop := range workshops.Items {
+		for _, manifest := range workshop.Spec.ExtraManifests {
+			if manifest.ConfigMapRef != nil && manifest.ConfigMapRef.Name == object.Meta.GetName() {
+				requests = append(requests, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: workshop.Name, Namespace: workshop.Namespace},
+				})
+				break
+			}
+		}
+	}
+	return requests
+}
+
+// readinessChanged only lets through the events which change the readiness of a workload
+var readinessChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		switch newObject := e.ObjectNew.(type) {
+		case *appsv1.Deployment:
+			oldObject, ok := e.ObjectOld.(*appsv1.Deployment)
+			return !ok || kubernetes.IsDeploymentReady(oldObject) != kubernetes.IsDeploymentReady(newObject)
+		case *appsv1.StatefulSet:
+			oldObject, ok := e.ObjectOld.(*appsv1.StatefulSet)
+			return !ok || kubernetes.IsStatefulSetReady(oldObject) != kubernetes.IsStatefulSetReady(newObject)
+		}
+		return true
+	},
+}
+
+// hasOwnerLabels only lets through the events of the resources created by a Workshop
+var hasOwnerLabels = predicate.Funcs{
+	CreateFunc: func(e event.CreateEvent) bool {
+		_, ok := e.Meta.GetLabels()[util.WorkshopUIDLabel]
+		return ok
+	},
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		_, ok := e.MetaNew.GetLabels()[util.WorkshopUIDLabel]
+		return ok
+	},
+	DeleteFunc: func(e event.DeleteEvent) bool {
+		_, ok := e.Meta.GetLabels()[util.WorkshopUIDLabel]
+		return ok
+	},
+	GenericFunc: func(e event.GenericEvent) bool {
+		_, ok := e.Meta.GetLabels()[util.WorkshopUIDLabel]
+		return ok
+	},
+}
+
+// driftChanged only lets through the updates which change the desired state of a resource, or the readiness
+// of a workload, not its status. The resources without a generation, like Secrets, always pass,
+// except the ResourceQuotas whose usage changes with every pod.
+var driftChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if newQuota, ok := e.ObjectNew.(*corev1.ResourceQuota); ok {
+			oldQuota, ok := e.ObjectOld.(*corev1.ResourceQuota)
+			return !ok || !reflect.DeepEqual(newQuota.Spec, oldQuota.Spec) || !reflect.DeepEqual(e.MetaNew.GetLabels(), e.MetaOld.GetLabels())
+		}
+		if e.MetaNew.GetGeneration() == 0 || e.MetaNew.GetGeneration() != e.MetaOld.GetGeneration() {
+			return true
+		}
+		if !reflect.DeepEqual(e.MetaNew.GetLabels(), e.MetaOld.GetLabels()) {
+			return true
+		}
+		switch e.ObjectNew.(type) {
+		case *appsv1.Deployment, *appsv1.StatefulSet:
+			return readinessChanged.Update(e)
+		}
+		return false
+	},
+}
@@ -14,21 +14,32 @@ package controllers
 
 import (
 	"context"
-	"regexp"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
-	routev1 "github.com/openshift/api/route/v1"
-	"github.com/prometheus/common/log"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
 
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	workshopv1 "github.com/stakater/workshop-operator/api/v1"
+	"github.com/stakater/workshop-operator/common/kubernetes"
+	openshiftuser "github.com/stakater/workshop-operator/common/user"
 	"github.com/stakater/workshop-operator/common/util"
 )
 
@@ -37,23 +48,48 @@ type WorkshopReconciler struct {
 	client.Client
 	Log    logr.Logger
 	Scheme *runtime.Scheme
+	// Components installed by the Workshops, the builtin components when nil
+	Components *Registry
+	// Platform of the cluster, detected when empty
+	Platform kubernetes.Platform
+	// Recorder records the Events of the Workshops, the one of the manager when nil
+	Recorder record.EventRecorder
+	// Config is the REST config the clients impersonating the Service Accounts of the Workshops derive from,
+	// the one of the manager when nil
+	Config *rest.Config
+	// PortalImage is the image of the operator, which serves the portal of the Workshops with generated passwords
+	PortalImage string
+	// mapper maps the kinds of the manifests of the Workshops to their resources
+	mapper meta.RESTMapper
+	// apiReader reads the resources the cache may not hold yet straight from the API server
+	apiReader client.Reader
+	// passwords remembers the password hashes of the users verified already
+	passwords *openshiftuser.PasswordCache
+	// baseContext is cancelled when the manager stops or loses the leadership,
+	// which aborts the reconciliations in flight
+	baseContext context.Context
 }
 
 // Finalizer
 const workshopFinalizer = "finalizer.workshop.stakater.com"
 
+// teardownRequeueDelay is the delay between two checks of the resources left by the teardown
+const teardownRequeueDelay = 10 * time.Second
+
 // +kubebuilder:rbac:groups=workshop.stakater.com,resources=workshops;workshops/finalizers,verbs=*
 // +kubebuilder:rbac:groups=workshop.stakater.com,resources=workshops/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=workshop.stakater.com,resources=workshops,verbs=get;list;watch;create;update;patch;delete
 
-// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=create;delete
+// +kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=apps,resources=deployments/finalizers,verbs=update
-// +kubebuilder:rbac:groups=core,resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets;namespaces;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=core,resources=pods;services;endpoints;persistentvolumeclaims;events;configmaps;secrets;namespaces;serviceaccounts;resourcequotas;limitranges,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=config.openshift.io,resources=ingresses;consoles,verbs=get;list;watch
 // +kubebuilder:rbac:groups=security.openshift.io,resources=securitycontextconstraints,verbs=create;list;watch;update;patch;get;delete
 // +kubebuilder:rbac:groups=project.openshift.io,resources=projectrequests,verbs=create
-// +kubebuilder:rbac:groups=user.openshift.io,resources=users;identities;useridentitymappings,verbs=create;list;watch;update;get;delete
+// +kubebuilder:rbac:groups=user.openshift.io,resources=users;groups;identities;useridentitymappings,verbs=create;list;watch;update;patch;get;delete
 
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings;clusterroles;clusterrolebindings,verbs=*
 // +kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch;create;update;patch;delete
@@ -66,13 +102,20 @@ const workshopFinalizer = "finalizer.workshop.stakater.com"
 // +kubebuilder:rbac:groups=kiali.io,resources=kialis,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=operator.cert-manager.io,resources=certmanagers,verbs=get;list;watch;create;update;patch;delete
 
-func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
-	ctx := context.Background()
-	reqLogger := r.Log.WithValues("workshop", req.NamespacedName)
+// The charts standing in for the operators on Kubernetes are installed by Jobs running Helm
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=dex.coreos.com,resources=passwords,verbs=get;list;watch;create;update;patch;delete
+
+// The manifests of the spec are applied as the Service Account of the Workshop
+// +kubebuilder:rbac:groups=core,resources=serviceaccounts,verbs=impersonate
+
+func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (result ctrl.Result, err error) {
+	log := r.Log.WithValues(util.LogWorkshop, req.NamespacedName)
+	ctx := logr.NewContext(r.context(), log)
 
 	// Fetch the Workshop workshop
 	workshop := &workshopv1.Workshop{}
-	err := r.Get(ctx, req.NamespacedName, workshop)
+	err = r.Get(ctx, req.NamespacedName, workshop)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Request object not found, could have been deleted after reconcile request.
@@ -86,31 +129,12 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 		return reconcile.Result{}, err
 	}
 
-	//////////////////////////
-	// Variables
-	//////////////////////////
-	var (
-		openshiftConsoleURL string
-		appsHostnameSuffix  string
-	)
-	// extract app route suffix from openshift-console
-	route := &routev1.Route{}
-	if err := r.Get(ctx, types.NamespacedName{Name: "console", Namespace: "openshift-console"}, route); err != nil {
-		log.Errorf("Failed to get OpenShift Console: %s", err)
+	components, enabled, err := r.Components.Plan(&workshop.Spec)
+	if err != nil {
+		log.Error(err, "Failed to plan the components")
 		return reconcile.Result{}, err
 	}
-	openshiftConsoleURL = "https://" + route.Spec.Host
-	log.Infof("OpenShift Console URL %s", openshiftConsoleURL)
 
-	re := regexp.MustCompile(`^console-openshift-console.(.*?)$`)
-	match := re.FindStringSubmatch(route.Spec.Host)
-	appsHostnameSuffix = match[1]
-	log.Infof("Apps Hostname Suffix %s", appsHostnameSuffix)
-
-	users := workshop.Spec.UserDetails.NumberOfUsers
-	if users < 0 {
-		users = 0
-	}
 	// Handle Cleanup on Deletion
 
 	// Check if the Workshop workshop is marked to be deleted, which is
@@ -118,21 +142,28 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	isWorkshopMarkedToBeDeleted := workshop.GetDeletionTimestamp() != nil
 	if isWorkshopMarkedToBeDeleted {
 		if util.Contains(workshop.GetFinalizers(), workshopFinalizer) {
-			// Run finalization logic for workshopFinalizer. If the
-			// finalization logic fails, don't remove the finalizer so
-			// that we can retry during the next reconciliation.
-			if err := r.finalizeWorkshop(reqLogger, workshop); err != nil {
-				return ctrl.Result{}, err
+			// Report the progress of the teardown through the status subresource
+			status := newStatusRecorder(workshop, components, nil, r.Recorder)
+			status.deleting = true
+			defer func() {
+				result, err = r.updateStatus(ctx, status, result, err)
+			}()
+
+			env := r.teardownEnvironment(ctx, workshop)
+
+			// Tear the components down. If the teardown fails, don't remove
+			// the finalizer so that we can retry during the next reconciliation.
+			if result, err := r.handleDelete(ctx, req, workshop, components, enabled, env, status); util.IsRequeued(result, err) {
+				return result, err
 			}
-			_, _ = r.handleDelete(ctx, req, workshop, users, appsHostnameSuffix, openshiftConsoleURL)
 			// Remove workshopFinalizer. Once all finalizers have been
 			// removed, the object will be deleted.
 			controllerutil.RemoveFinalizer(workshop, workshopFinalizer)
-			log.Info("Finalizer removed for workshop" + workshop.ObjectMeta.Name)
-			err := r.Update(ctx, workshop)
-			if err != nil {
+			log.Info("Finalizer removed")
+			if err := r.Update(ctx, workshop); err != nil {
 				return ctrl.Result{}, err
 			}
+			r.passwords.ForgetWorkshop(workshop.UID)
 
 		}
 		return ctrl.Result{}, nil
@@ -140,165 +171,164 @@ func (r *WorkshopReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 
 	// Add finalizer for this CR
 	if !util.Contains(workshop.GetFinalizers(), workshopFinalizer) {
-		if err := r.addFinalizer(reqLogger, workshop); err != nil {
+		if err := r.addFinalizer(ctx, workshop); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
-	//////////////////////////
-	// Users
-	//////////////////////////
-	if result, err := r.reconcileUser(workshop); util.IsRequeued(result, err) {
-		return ctrl.Result{}, err
-	}
-
-	//////////////////////////
-	// Portal
-	//////////////////////////
-	if result, err := r.reconcilePortal(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// Projects
-	//////////////////////////
-	if result, err := r.reconcileProject(workshop, users); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// Bookbag
-	//////////////////////////
-	if result, err := r.reconcileBookbag(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// Nexus
-	//////////////////////////
-	if result, err := r.reconcileNexus(workshop); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// Gitea
-	//////////////////////////
-	if result, err := r.reconcileGitea(workshop, users); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// Pipeline
-	//////////////////////////
-	if result, err := r.reconcilePipelines(workshop); util.IsRequeued(result, err) {
-		return result, err
-	}
 
-	//////////////////////////
-	// GitOps
-	//////////////////////////
-	if result, err := r.reconcileGitOps(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// CodeReadyWorkspace
-	//////////////////////////
-	if result, err := r.reconcileCodeReadyWorkspace(workshop, users, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
-	}
-
-	//////////////////////////
-	// Service Mesh
-	//////////////////////////
-	if result, err := r.reconcileServiceMesh(workshop, users); util.IsRequeued(result, err) {
-		return result, err
-	}
+	// Report the phase of each component through the status subresource
+	status := newStatusRecorder(workshop, components, enabled, r.Recorder)
+	defer func() {
+		result, err = r.updateStatus(ctx, status, result, err)
+		// Once installed, the Workshop is reconciled again to refresh the usage of the quotas
+		if err == nil && result.IsZero() && hasProjectQuotas(workshop) {
+			result.RequeueAfter = PROJECT_QUOTA_REFRESH
+		}
+	}()
 
-	//////////////////////////
-	// Serverless
-	//////////////////////////
-	if result, err := r.reconcileServerless(workshop); util.IsRequeued(result, err) {
-		return result, err
+	env, err := r.environment(ctx, workshop, status)
+	if err != nil {
+		return reconcile.Result{}, err
 	}
+	pruneAttendees(workshop, env.Users)
 
-	//////////////////////////
-	// Vault
-	//////////////////////////
-	if result, err := r.reconcileVault(workshop, users); util.IsRequeued(result, err) {
-		return result, err
+	// Uninstall the components disabled since they were installed, each one before its dependencies
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		if enabled[component.Name()] || !status.installed(component.Name()) {
+			continue
+		}
+		log.Info("Uninstalling the disabled component", util.LogComponent, component.Name())
+		componentCtx := util.WithLogValues(ctx, util.LogComponent, component.Name())
+		if result, err := component.Finalize(componentCtx, r, workshop, env); status.step(component.Name(), result, err) {
+			return result, err
+		}
 	}
 
-	//////////////////////////
-	// Cert Manager
-	//////////////////////////
-	if result, err := r.reconcileCertManager(workshop, users); util.IsRequeued(result, err) {
-		return result, err
+	// Install the enabled components, each one after its dependencies
+	for _, component := range components {
+		if !enabled[component.Name()] {
+			continue
+		}
+		componentCtx := util.WithLogValues(ctx, util.LogComponent, component.Name())
+		if result, err := component.Reconcile(componentCtx, r, workshop, env); status.step(component.Name(), result, err) {
+			return result, err
+		}
 	}
 
 	return ctrl.Result{}, nil
 }
 
 func (r *WorkshopReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&workshopv1.Workshop{}).
-		Complete(r)
-}
-
-func (r *WorkshopReconciler) handleDelete(ctx context.Context, req ctrl.Request, workshop *workshopv1.Workshop, userID int, appsHostnameSuffix string, openshiftConsoleURL string) (ctrl.Result, error) {
-	log := r.Log.WithValues("workshop", req.NamespacedName)
-	log.Info("Deleting workshop   " + workshop.ObjectMeta.Name)
-
-	if result, err := r.deleteUsers(workshop); util.IsRequeued(result, err) {
-		return result, err
+	if r.Components == nil {
+		r.Components = NewDefaultRegistry()
 	}
-
-	if result, err := r.deletePortal(workshop, userID, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
+	if r.Platform == "" {
+		platform, err := kubernetes.DetectPlatform(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+		r.Platform = platform
 	}
-
-	if result, err := r.deleteProject(workshop, userID); util.IsRequeued(result, err) {
-		return result, err
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("workshop-controller")
 	}
-
-	if result, err := r.deleteBookbag(workshop, userID, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
+	if r.Config == nil {
+		r.Config = mgr.GetConfig()
 	}
-
-	if result, err := r.deleteNexus(workshop); util.IsRequeued(result, err) {
-		return result, err
+	r.mapper = mgr.GetRESTMapper()
+	r.apiReader = mgr.GetAPIReader()
+	passwords, err := openshiftuser.NewPasswordCache()
+	if err != nil {
+		return err
 	}
-
-	if result, err := r.deleteGitea(workshop); util.IsRequeued(result, err) {
-		return result, err
+	r.passwords = passwords
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r.baseContext = ctx
+	if err := mgr.Add(manager.RunnableFunc(func(stop <-chan struct{}) error {
+		<-stop
+		cancel()
+		return nil
+	})); err != nil {
+		cancel()
+		return err
 	}
+	r.Log.Info("Installing the Workshops", "platform", r.Platform)
 
-	if result, err := r.deletePipelines(workshop); util.IsRequeued(result, err) {
-
-		return result, err
+	controller := ctrl.NewControllerManagedBy(mgr).
+		For(&workshopv1.Workshop{}).
+		Watches(&source.Kind{Type: &appsv1.Deployment{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapAwaitedDeployment)},
+			builder.WithPredicates(readinessChanged)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(r.mapManifestConfigMap)})
+
+	// The resources of the Workshops are mapped back to them through their ownership labels
+	for _, kind := range servedKinds(r.Log, mgr.GetRESTMapper(), mgr.GetScheme(), ownedKinds()) {
+		controller = controller.Watches(&source.Kind{Type: kind},
+			&handler.EnqueueRequestsFromMapFunc{ToRequests: handler.ToRequestsFunc(mapOwnerLabels)},
+			builder.WithPredicates(hasOwnerLabels, driftChanged))
 	}
 
-	if result, err := r.deleteGitOps(workshop, userID, appsHostnameSuffix, openshiftConsoleURL); util.IsRequeued(result, err) {
-		return result, err
-	}
+	return controller.Complete(r)
+}
 
-	if result, err := r.deleteCodeReadyWorkspace(workshop, userID, appsHostnameSuffix); util.IsRequeued(result, err) {
-		return result, err
+// context returns the context of the reconciliations, cancelled when the manager stops
+func (r *WorkshopReconciler) context() context.Context {
+	if r.baseContext == nil {
+		return context.Background()
 	}
+	return r.baseContext
+}
 
-	if result, err := r.deleteServiceMeshService(workshop, userID); util.IsRequeued(result, err) {
-		return result, err
+// handleDelete uninstalls all the components of the Workshop, carrying on after a failure,
+// and returns a requeue until the cluster resources of the Workshop are gone
+func (r *WorkshopReconciler) handleDelete(ctx context.Context, req ctrl.Request, workshop *workshopv1.Workshop,
+	components []Component, enabled map[string]bool, env *Environment, status *statusRecorder) (ctrl.Result, error) {
+	log := util.Logger(ctx)
+	log.Info("Deleting the Workshop")
+
+	result := ctrl.Result{}
+	errs := []error{}
+	waiting := []string{}
+
+	// Uninstall the enabled or installed components, each one before its dependencies
+	for i := len(components) - 1; i >= 0; i-- {
+		component := components[i]
+		if !enabled[component.Name()] && !status.installed(component.Name()) {
+			continue
+		}
+		componentCtx := util.WithLogValues(ctx, util.LogComponent, component.Name())
+		componentResult, err := component.Finalize(componentCtx, r, workshop, env)
+		status.step(component.Name(), componentResult, err)
+		if err != nil {
+			log.Error(err, "Failed to uninstall the component", util.LogComponent, component.Name())
+			errs = append(errs, fmt.Errorf("%s: %v", component.Name(), err))
+		} else if util.IsRequeued(componentResult, err) {
+			waiting = append(waiting, component.Name())
+			result = componentResult
+		}
 	}
 
-	if result, err := r.deleteServerless(workshop); util.IsRequeued(result, err) {
-		return result, err
+	if err := utilerrors.NewAggregate(errs); err != nil {
+		status.message = fmt.Sprintf("Teardown failed: %s", err)
+		return ctrl.Result{}, err
 	}
-
-	if result, err := r.deleteVault(workshop); util.IsRequeued(result, err) {
-		return result, err
+	if len(waiting) > 0 {
+		status.message = fmt.Sprintf("Waiting for the uninstallation of %s", strings.Join(waiting, ", "))
+		return result, nil
 	}
 
-	if result, err := r.deleteCertManager(workshop); util.IsRequeued(result, err) {
-		return result, err
+	remaining, err := r.remainingResources(ctx, workshop, env)
+	if err != nil {
+		status.message = fmt.Sprintf("Failed to check the remaining resources: %s", err)
+		return ctrl.Result{}, err
+	}
+	if len(remaining) > 0 {
+		log.Info("Waiting for the deletion of the cluster resources", "remaining", remaining)
+		status.message = fmt.Sprintf("Waiting for the deletion of %s", strings.Join(remaining, ", "))
+		return ctrl.Result{RequeueAfter: teardownRequeueDelay}, nil
 	}
 
 	return ctrl.Result{}, nil
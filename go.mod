@@ -6,6 +6,7 @@ require (
 	github.com/argoproj-labs/argocd-operator v0.0.14
 	github.com/argoproj/argo-cd v1.5.8
 	github.com/argoproj/pkg v0.7.0 // indirect
+	github.com/blang/semver v3.5.1+incompatible
 	github.com/eclipse/che-operator v0.0.0-20191211154745-df0be398efea
 	github.com/go-logr/logr v0.4.0
 	github.com/go-logr/zapr v0.4.0 // indirect
@@ -15,7 +16,8 @@ require (
 	github.com/maistra/istio-operator v0.0.0-20201103161300-64d0fff69dbe
 	github.com/openshift/api v3.9.1-0.20190924102528-32369d4db2ad+incompatible
 	github.com/operator-framework/api v0.3.17
-	github.com/prometheus/common v0.14.0
+	github.com/prometheus/client_golang v1.7.1
+	github.com/prometheus/common v0.14.0 // indirect
 	github.com/yudai/gojsondiff v1.0.0 // indirect
 	github.com/yudai/golcs v0.0.0-20170316035057-ecda9a501e82 // indirect
 	github.com/yudai/pp v2.0.1+incompatible // indirect
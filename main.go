@@ -17,8 +17,12 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"flag"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
 
 	argocdoperatorv1 "github.com/argoproj-labs/argocd-operator/pkg/apis/argoproj/v1alpha1"
 	argocdv1 "github.com/argoproj/argo-cd/pkg/apis/application/v1alpha1"
@@ -29,6 +33,7 @@ import (
 	"github.com/stakater/workshop-operator/common/certmanager"
 	"github.com/stakater/workshop-operator/common/gitea"
 	"github.com/stakater/workshop-operator/common/nexus"
+	"github.com/stakater/workshop-operator/common/usernamedistribution"
 	"github.com/stakater/workshop-operator/controllers"
 
 	kiali "github.com/maistra/istio-operator/pkg/apis/external/kiali/v1alpha1"
@@ -38,12 +43,15 @@ import (
 	userv1 "github.com/openshift/api/user/v1"
 	olmv1 "github.com/operator-framework/api/pkg/operators/v1"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 	// +kubebuilder:scaffold:imports
 )
@@ -82,13 +90,24 @@ func init() {
 func main() {
 	var metricsAddr string
 	var enableLeaderElection bool
+	var portal bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&portal, "portal", false,
+		"Serve the portal of a Workshop with generated passwords instead of running the controller manager.")
+	// The verbosity is set with --zap-log-level, debug shows the applied resources
+	opts := zap.Options{Development: true}
+	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
-	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if portal {
+		servePortal()
+		return
+	}
 
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
@@ -102,14 +121,26 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Add-ons are registered on top of the builtin components
+	components := controllers.NewDefaultRegistry()
+
 	if err = (&controllers.WorkshopReconciler{
-		Client: mgr.GetClient(),
-		Log:    ctrl.Log.WithName("controllers").WithName("Workshop"),
-		Scheme: mgr.GetScheme(),
+		Client:      mgr.GetClient(),
+		Log:         ctrl.Log.WithName("controllers").WithName("Workshop"),
+		Scheme:      mgr.GetScheme(),
+		Components:  components,
+		Recorder:    mgr.GetEventRecorderFor("workshop-controller"),
+		PortalImage: operatorImage(mgr.GetAPIReader()),
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Workshop")
 		os.Exit(1)
 	}
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&workshopv1.Workshop{}).SetupWebhookWithManager(mgr, components); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "Workshop")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")
@@ -118,3 +149,48 @@ func main() {
 		os.Exit(1)
 	}
 }
+
+// servePortal serves the portal of a Workshop with generated passwords, configured by the environment of its Deployment
+func servePortal() {
+	c, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create the client of the portal")
+		os.Exit(1)
+	}
+	server, err := usernamedistribution.NewServerFromEnv(c)
+	if err != nil {
+		setupLog.Error(err, "invalid configuration of the portal")
+		os.Exit(1)
+	}
+
+	setupLog.Info("starting portal")
+	if err := http.ListenAndServe(":8080", server); err != nil {
+		setupLog.Error(err, "problem running portal")
+		os.Exit(1)
+	}
+}
+
+// operatorImage returns the image of the operator: OPERATOR_IMAGE if set, the image of the Pod of the operator otherwise
+func operatorImage(reader client.Reader) string {
+	if image := os.Getenv("OPERATOR_IMAGE"); image != "" {
+		return image
+	}
+
+	namespace, err := ioutil.ReadFile("/var/run/secrets/kubernetes.io/serviceaccount/namespace")
+	if err != nil {
+		setupLog.Info("The image of the operator is unknown, set OPERATOR_IMAGE to deploy the portal of the Workshops with generated passwords")
+		return ""
+	}
+	pod := &corev1.Pod{}
+	name := types.NamespacedName{Name: os.Getenv("HOSTNAME"), Namespace: strings.TrimSpace(string(namespace))}
+	if err := reader.Get(context.Background(), name, pod); err != nil {
+		setupLog.Error(err, "unable to read the image of the operator, set OPERATOR_IMAGE to deploy the portal of the Workshops with generated passwords")
+		return ""
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == "manager" {
+			return container.Image
+		}
+	}
+	return ""
+}